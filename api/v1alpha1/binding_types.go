@@ -0,0 +1,127 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// BindingDestinationType is the kind of entity a binding routes to.
+// +kubebuilder:validation:Enum=queue;exchange
+type BindingDestinationType string
+
+const (
+	BindingDestinationQueue    BindingDestinationType = "queue"
+	BindingDestinationExchange BindingDestinationType = "exchange"
+)
+
+// BindingSpec defines the desired state of Binding. The routing key and arguments can be changed, the binding
+// is then replaced on the broker.
+type BindingSpec struct {
+	// The LavinMQ instance in the same namespace to create the binding on.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="lavinmqRef is immutable"
+	LavinMQRef corev1.LocalObjectReference `json:"lavinmqRef"`
+
+	// Vhost of the exchange and the destination.
+	// +kubebuilder:default="/"
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="vhost is immutable"
+	// +optional
+	Vhost string `json:"vhost,omitempty"`
+
+	// Name of the exchange to bind to, the default exchange can't be bound to.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="source is immutable"
+	Source string `json:"source"`
+
+	// Name of the queue or exchange to route to.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="destination is immutable"
+	Destination string `json:"destination"`
+
+	// Whether the destination is a queue or an exchange.
+	// +kubebuilder:default="queue"
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="destinationType is immutable"
+	// +optional
+	DestinationType BindingDestinationType `json:"destinationType,omitempty"`
+
+	// Routing key, or pattern for topic exchanges, messages are matched against.
+	// +optional
+	RoutingKey string `json:"routingKey,omitempty"`
+
+	// Binding arguments, e.g. the headers matched by a headers exchange.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Arguments *runtime.RawExtension `json:"arguments,omitempty"`
+}
+
+// BindingStatus defines the observed state of Binding
+type BindingStatus struct {
+	// Properties key identifying the binding on the broker, derived from the routing key and arguments.
+	// +optional
+	PropertiesKey string `json:"propertiesKey,omitempty"`
+
+	// Conditions store the status conditions of the binding, Ready tells whether it exists as specified.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// LavinMQName returns the name of the LavinMQ instance the binding is created on.
+func (b *Binding) LavinMQName() string {
+	return b.Spec.LavinMQRef.Name
+}
+
+// StatusConditions returns the conditions of the binding for updating in place.
+func (b *Binding) StatusConditions() *[]metav1.Condition {
+	return &b.Status.Conditions
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Source",type=string,JSONPath=`.spec.source`
+// +kubebuilder:printcolumn:name="Destination",type=string,JSONPath=`.spec.destination`
+// +kubebuilder:printcolumn:name="Routing Key",type=string,JSONPath=`.spec.routingKey`
+// +kubebuilder:printcolumn:name="Vhost",type=string,JSONPath=`.spec.vhost`,priority=1
+// +kubebuilder:printcolumn:name="LavinMQ",type=string,JSONPath=`.spec.lavinmqRef.name`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Binding is the Schema for the bindings API
+type Binding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BindingSpec   `json:"spec,omitempty"`
+	Status BindingStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BindingList contains a list of Binding
+type BindingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Binding `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Binding{}, &BindingList{})
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ExchangeSpec defines the desired state of Exchange. AMQP doesn't allow changing the properties of an
+// existing exchange, changing them is reported as a conflict until the exchange is deleted and declared again.
+// +kubebuilder:validation:XValidation:rule="has(self.name) == has(oldSelf.name) && (!has(self.name) || self.name == oldSelf.name)",message="name is immutable"
+type ExchangeSpec struct {
+	// The LavinMQ instance in the same namespace to declare the exchange on.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="lavinmqRef is immutable"
+	LavinMQRef corev1.LocalObjectReference `json:"lavinmqRef"`
+
+	// Name of the exchange, the name of the resource unless set. The default exchange and the amq.* exchanges
+	// are declared by the broker itself.
+	// +kubebuilder:validation:MaxLength=255
+	// +kubebuilder:validation:XValidation:rule="!self.startsWith('amq.')",message="the amq. prefix is reserved"
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Vhost to declare the exchange in.
+	// +kubebuilder:default="/"
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="vhost is immutable"
+	// +optional
+	Vhost string `json:"vhost,omitempty"`
+
+	// Exchange type, e.g. direct, fanout, topic, headers or x-consistent-hash.
+	// +kubebuilder:default="direct"
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Whether the exchange survives a broker restart.
+	// +kubebuilder:default=true
+	// +optional
+	Durable *bool `json:"durable,omitempty"`
+
+	// Whether the exchange is deleted once its last binding is removed.
+	// +optional
+	AutoDelete bool `json:"autoDelete,omitempty"`
+
+	// Whether clients are prevented from publishing to the exchange, only exchanges bound to it route to it.
+	// +optional
+	Internal bool `json:"internal,omitempty"`
+
+	// Exchange arguments, e.g. alternate-exchange.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Arguments *runtime.RawExtension `json:"arguments,omitempty"`
+}
+
+// ExchangeStatus defines the observed state of Exchange
+type ExchangeStatus struct {
+	// Conditions store the status conditions of the exchange, Ready tells whether it's declared as specified.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// LavinMQName returns the name of the LavinMQ instance the exchange is declared on.
+func (e *Exchange) LavinMQName() string {
+	return e.Spec.LavinMQRef.Name
+}
+
+// ExchangeName returns the name of the exchange on the broker, spec.name or the name of the resource.
+func (e *Exchange) ExchangeName() string {
+	if e.Spec.Name != "" {
+		return e.Spec.Name
+	}
+
+	return e.Name
+}
+
+// IsDurable reports whether the exchange is durable, true unless spec.durable is false.
+func (e *Exchange) IsDurable() bool {
+	return e.Spec.Durable == nil || *e.Spec.Durable
+}
+
+// StatusConditions returns the conditions of the exchange for updating in place.
+func (e *Exchange) StatusConditions() *[]metav1.Condition {
+	return &e.Status.Conditions
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Exchange",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+// +kubebuilder:printcolumn:name="Vhost",type=string,JSONPath=`.spec.vhost`
+// +kubebuilder:printcolumn:name="LavinMQ",type=string,JSONPath=`.spec.lavinmqRef.name`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Exchange is the Schema for the exchanges API
+type Exchange struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ExchangeSpec   `json:"spec,omitempty"`
+	Status ExchangeStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ExchangeList contains a list of Exchange
+type ExchangeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Exchange `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Exchange{}, &ExchangeList{})
+}
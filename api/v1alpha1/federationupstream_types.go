@@ -0,0 +1,143 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FederationUpstreamSpec defines the desired state of FederationUpstream
+// +kubebuilder:validation:XValidation:rule="has(self.name) == has(oldSelf.name) && (!has(self.name) || self.name == oldSelf.name)",message="name is immutable"
+type FederationUpstreamSpec struct {
+	// The LavinMQ instance in the same namespace to federate from the upstream.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="lavinmqRef is immutable"
+	LavinMQRef corev1.LocalObjectReference `json:"lavinmqRef"`
+
+	// Name of the upstream, the name of the resource unless set. Federation policies refer to it by name.
+	// +kubebuilder:validation:MaxLength=255
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Vhost to create the upstream in.
+	// +kubebuilder:default="/"
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="vhost is immutable"
+	// +optional
+	Vhost string `json:"vhost,omitempty"`
+
+	// URI of the upstream broker.
+	URI URISource `json:"uri"`
+
+	// Upstream exchange to federate from, the name of the federated exchange unless set.
+	// +optional
+	Exchange string `json:"exchange,omitempty"`
+
+	// Upstream queue to federate from, the name of the federated queue unless set.
+	// +optional
+	Queue string `json:"queue,omitempty"`
+
+	// Number of unacknowledged messages a federation link consumes at most.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	PrefetchCount int32 `json:"prefetchCount,omitempty"`
+
+	// Seconds to wait before reconnecting after a connection failure.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ReconnectDelay int32 `json:"reconnectDelay,omitempty"`
+
+	// When messages are acknowledged upstream.
+	// +kubebuilder:default=on-confirm
+	// +optional
+	AckMode AckMode `json:"ackMode,omitempty"`
+
+	// Number of federation links a message may pass through.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxHops int32 `json:"maxHops,omitempty"`
+
+	// Milliseconds the upstream queue of a federated exchange is kept after the link disconnects.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Expires int64 `json:"expires,omitempty"`
+
+	// Milliseconds messages are kept in the upstream queue of a federated exchange.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MessageTTL int64 `json:"messageTTL,omitempty"`
+}
+
+// FederationUpstreamStatus defines the observed state of FederationUpstream
+type FederationUpstreamStatus struct {
+	// Conditions store the status conditions of the upstream, Ready tells whether it's defined as specified.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// LavinMQName returns the name of the LavinMQ instance the upstream is created on.
+func (u *FederationUpstream) LavinMQName() string {
+	return u.Spec.LavinMQRef.Name
+}
+
+// UpstreamName returns the name of the upstream on the broker, spec.name or the name of the resource.
+func (u *FederationUpstream) UpstreamName() string {
+	if u.Spec.Name != "" {
+		return u.Spec.Name
+	}
+
+	return u.Name
+}
+
+// URISources returns the sources of the URIs the upstream connects to.
+func (u *FederationUpstream) URISources() []URISource {
+	return []URISource{u.Spec.URI}
+}
+
+// StatusConditions returns the conditions of the upstream for updating in place.
+func (u *FederationUpstream) StatusConditions() *[]metav1.Condition {
+	return &u.Status.Conditions
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Vhost",type=string,JSONPath=`.spec.vhost`
+// +kubebuilder:printcolumn:name="LavinMQ",type=string,JSONPath=`.spec.lavinmqRef.name`,priority=1
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// FederationUpstream is the Schema for the federationupstreams API
+type FederationUpstream struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FederationUpstreamSpec   `json:"spec,omitempty"`
+	Status FederationUpstreamStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// FederationUpstreamList contains a list of FederationUpstream
+type FederationUpstreamList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FederationUpstream `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FederationUpstream{}, &FederationUpstreamList{})
+}
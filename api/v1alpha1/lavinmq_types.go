@@ -17,8 +17,13 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -46,18 +51,419 @@ type LavinMQSpec struct {
 	// Affinity scheduling rules to be applied on created Pods.
 	Affinity *corev1.Affinity `json:"affinity,omitempty"`
 
+	// +optional
+	// Tolerations applied to created Pods, e.g. to run on dedicated tainted nodes.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// +optional
+	// NodeSelector restricting the nodes created Pods are scheduled on.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// +optional
+	// TopologySpreadConstraints applied to created Pods.
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// +optional
+	// PriorityClassName of created Pods.
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// +optional
+	// SchedulerName of created Pods, the default scheduler is used if unset.
+	SchedulerName string `json:"schedulerName,omitempty"`
+
+	// +optional
+	// ServiceAccountName created Pods run as, the namespace default if unset.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// +optional
+	// ImagePullSecrets used to pull the LavinMQ image.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// +optional
+	// PodSecurityContext of created Pods.
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// +optional
+	// SecurityContext of the LavinMQ container.
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+
+	// +optional
+	// PodTemplate is strategic-merged over the generated pod template, e.g. to add sidecars, init containers,
+	// volumes or environment variables. Containers are merged by name, the LavinMQ container is named "lavinmq".
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	PodTemplate *runtime.RawExtension `json:"podTemplate,omitempty"`
+
 	// Will override the accessmode and force it to ReadWriteOnce
 	// +required
 	DataVolumeClaimSpec corev1.PersistentVolumeClaimSpec `json:"dataVolumeClaim"`
 
+	// What happens to the volumes of the pods removed when scaling down. Retain keeps them for a later
+	// scale up, Delete removes them once the pod is gone.
+	// +kubebuilder:default=Retain
+	// +optional
+	PersistentVolumeClaimRetentionPolicy PersistentVolumeClaimRetentionPolicy `json:"persistentVolumeClaimRetentionPolicy,omitempty"`
+
+	// Allows changes to the data volumes that can't be applied in place, shrinking them or moving them to
+	// another storage class. Without it a smaller size is rejected and a storage class change is ignored.
+	// +optional
+	VolumeMigration *VolumeMigrationSpec `json:"volumeMigration,omitempty"`
+
 	// +optional
 	EtcdEndpoints []string `json:"etcdEndpoints,omitempty"`
 
+	// Etcd used for clustering, either provisioned by the operator or the connection settings of etcdEndpoints.
+	// +optional
+	Etcd *EtcdSpec `json:"etcd,omitempty"`
+
 	// +optional
 	TlsSecret *corev1.SecretReference `json:"tlsSecret,omitempty"`
 
+	// Issues the broker certificate through cert-manager, as an alternative to tlsSecret.
+	// +optional
+	Tls *TlsSpec `json:"tls,omitempty"`
+
 	// +optional
 	Config LavinMQConfig `json:"config,omitempty"`
+
+	// Secret holding the credentials of the default user, keys "username" and "password".
+	// The Secret is generated with a random password if it doesn't exist. The operator adds
+	// the password hash and connection URIs (amqp_uri, amqps_uri, mqtt_uri, mqtts_uri) to it.
+	// Can't be combined with config.main.default_user and config.main.default_password.
+	// +optional
+	DefaultUserSecretRef *corev1.LocalObjectReference `json:"defaultUserSecretRef,omitempty"`
+
+	// Exposes the leader outside of the cluster through a LoadBalancer or NodePort Service named <name>-external.
+	// +optional
+	Service *ServiceSpec `json:"service,omitempty"`
+
+	// Exposes the management interface through an Ingress or a Gateway API HTTPRoute named <name>-mgmt.
+	// +optional
+	Ingress *IngressSpec `json:"ingress,omitempty"`
+
+	// Overrides the PodDisruptionBudget created for clustered instances (replicas > 1).
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+}
+
+type PodDisruptionBudgetSpec struct {
+	// Number or percentage of pods that can be unavailable during voluntary disruptions such as node drains.
+	// +kubebuilder:default=1
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+type ServiceSpec struct {
+	// Type of the Service.
+	// +kubebuilder:validation:Enum=LoadBalancer;NodePort
+	// +kubebuilder:default=LoadBalancer
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// Annotations set on the Service, e.g. to configure the load balancer of the cloud provider.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Restricts traffic through the load balancer to the given client CIDRs.
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+
+	// Set to Local to preserve the client source IP.
+	// +kubebuilder:validation:Enum=Cluster;Local
+	// +optional
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+
+	// Node ports per protocol. Ports left unset are allocated by Kubernetes.
+	// +optional
+	NodePorts NodePorts `json:"nodePorts,omitempty"`
+}
+
+type NodePorts struct {
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Http int32 `json:"http,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Https int32 `json:"https,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Amqp int32 `json:"amqp,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Amqps int32 `json:"amqps,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Mqtt int32 `json:"mqtt,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Mqtts int32 `json:"mqtts,omitempty"`
+}
+
+// ForPort returns the node port configured for the named service port, 0 if unset.
+func (n NodePorts) ForPort(name string) int32 {
+	switch name {
+	case "http":
+		return n.Http
+	case "https":
+		return n.Https
+	case "amqp":
+		return n.Amqp
+	case "amqps":
+		return n.Amqps
+	case "mqtt":
+		return n.Mqtt
+	case "mqtts":
+		return n.Mqtts
+	}
+
+	return 0
+}
+
+type EtcdSpec struct {
+	// Provision an etcd cluster named <name>-etcd for this instance, with its own headless Service,
+	// volumes and peer TLS. Can't be changed once the instance is created.
+	// +optional
+	Managed bool `json:"managed,omitempty"`
+
+	// Number of members of the managed etcd cluster. Can't be changed once created.
+	// +kubebuilder:validation:Enum=1;3;5
+	// +kubebuilder:default=3
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Image of the managed etcd cluster.
+	// +kubebuilder:default="quay.io/coreos/etcd:v3.5.17"
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Resources of the managed etcd members.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Volume of each managed etcd member, 1Gi with the default StorageClass if unset.
+	// +optional
+	DataVolumeClaimSpec *corev1.PersistentVolumeClaimSpec `json:"dataVolumeClaim,omitempty"`
+
+	// Secret with the client certificate (tls.crt, tls.key) and CA (ca.crt) for mutual TLS with etcdEndpoints,
+	// the endpoints have to use https. Not supported with a managed etcd cluster.
+	// +optional
+	TlsSecretRef *corev1.LocalObjectReference `json:"tlsSecretRef,omitempty"`
+
+	// Secret with the username and password to authenticate to etcdEndpoints with.
+	// Not supported with a managed etcd cluster.
+	// +optional
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+
+	// Key prefix the nodes keep their clustering state under. Defaults to <namespace>-<name>-<uid> so
+	// instances sharing an etcd cluster never collide, must not overlap with the prefix of another instance.
+	// Can't be changed once in use.
+	// +kubebuilder:validation:Pattern=`^[^/]+(/[^/]+)*$`
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// What happens to the clustering state kept in etcd when the instance is deleted. Delete removes
+	// all keys under the etcd prefix of the instance before the deletion completes, Retain keeps them.
+	// +kubebuilder:default=Delete
+	// +optional
+	DeletionPolicy EtcdDeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// PersistentVolumeClaimRetentionPolicy tells what happens to the volumes of pods removed by a scale down.
+// +kubebuilder:validation:Enum=Retain;Delete
+type PersistentVolumeClaimRetentionPolicy string
+
+const (
+	// PersistentVolumeClaimRetentionPolicyRetain keeps the volumes, they're reused when scaling up again.
+	PersistentVolumeClaimRetentionPolicyRetain PersistentVolumeClaimRetentionPolicy = "Retain"
+	// PersistentVolumeClaimRetentionPolicyDelete deletes the volumes once their pod is gone.
+	PersistentVolumeClaimRetentionPolicyDelete PersistentVolumeClaimRetentionPolicy = "Delete"
+)
+
+// VolumeMigrationSpec configures how data volumes are replaced. One volume at a time, the node using it is
+// stopped, a Job copies the data to a new claim and the new volume takes the place of the old one.
+type VolumeMigrationSpec struct {
+	// Rolling migrates one replica at a time while the others keep serving and requires at least two replicas.
+	// MaintenanceWindow also migrates single node instances, which are unavailable while the data is copied.
+	// +kubebuilder:default=Rolling
+	// +optional
+	Strategy VolumeMigrationStrategy `json:"strategy,omitempty"`
+
+	// Image running the copy, it needs sh and cp. Defaults to the LavinMQ image.
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+// VolumeMigrationStrategy tells whether a volume migration has to keep the cluster available.
+// +kubebuilder:validation:Enum=Rolling;MaintenanceWindow
+type VolumeMigrationStrategy string
+
+const (
+	// VolumeMigrationStrategyRolling only stops a node when all the others are ready and in sync.
+	VolumeMigrationStrategyRolling VolumeMigrationStrategy = "Rolling"
+	// VolumeMigrationStrategyMaintenanceWindow stops the nodes without waiting for the rest of the cluster.
+	VolumeMigrationStrategyMaintenanceWindow VolumeMigrationStrategy = "MaintenanceWindow"
+)
+
+// VolumeMigrationStatus tracks the volume replaced by an ongoing volume migration.
+type VolumeMigrationStatus struct {
+	// Name of the claim being replaced.
+	Claim string `json:"claim"`
+
+	// Step the migration is in.
+	Phase VolumeMigrationPhase `json:"phase"`
+}
+
+// VolumeMigrationPhase is the step a volume migration is in.
+type VolumeMigrationPhase string
+
+const (
+	// VolumeMigrationPhaseCopying keeps the node stopped while its data is copied and the claim is swapped.
+	VolumeMigrationPhaseCopying VolumeMigrationPhase = "Copying"
+	// VolumeMigrationPhaseRejoining waits for the node to catch up with the cluster on the new volume, the old
+	// volume is kept until then.
+	VolumeMigrationPhaseRejoining VolumeMigrationPhase = "Rejoining"
+)
+
+// VolumeStatus is the observed state of a data volume.
+type VolumeStatus struct {
+	// Name of the claim.
+	Claim string `json:"claim"`
+
+	// Storage requested by the claim.
+	// +optional
+	Requested resource.Quantity `json:"requested,omitempty"`
+
+	// Storage provisioned for the claim.
+	// +optional
+	Capacity resource.Quantity `json:"capacity,omitempty"`
+
+	// Step of an ongoing resize, empty once the volume has the requested size.
+	// +optional
+	ResizePhase VolumeResizePhase `json:"resizePhase,omitempty"`
+
+	// Details reported by the storage provider about the resize.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// VolumeResizePhase is the step a volume resize is in.
+type VolumeResizePhase string
+
+const (
+	// VolumeResizePhaseResizing waits for the storage provider to expand the volume.
+	VolumeResizePhaseResizing VolumeResizePhase = "Resizing"
+	// VolumeResizePhaseFileSystemResizePending waits for the node to grow the file system, storage providers
+	// that can't do that while the volume is mounted need the pod to be restarted.
+	VolumeResizePhaseFileSystemResizePending VolumeResizePhase = "FileSystemResizePending"
+	// VolumeResizePhaseFailed is reported when the storage provider failed to expand the volume.
+	VolumeResizePhaseFailed VolumeResizePhase = "Failed"
+)
+
+// EtcdDeletionPolicy tells what happens to the etcd prefix of a deleted instance.
+// +kubebuilder:validation:Enum=Delete;Retain
+type EtcdDeletionPolicy string
+
+const (
+	// EtcdDeletionPolicyDelete deletes the keys under the etcd prefix of the instance.
+	EtcdDeletionPolicyDelete EtcdDeletionPolicy = "Delete"
+	// EtcdDeletionPolicyRetain leaves the keys in etcd.
+	EtcdDeletionPolicyRetain EtcdDeletionPolicy = "Retain"
+)
+
+// MemberCount returns the number of members of the managed etcd cluster.
+func (e *EtcdSpec) MemberCount() int32 {
+	if e.Replicas == 0 {
+		return 3
+	}
+
+	return e.Replicas
+}
+
+type TlsSpec struct {
+	// Issuer signing the certificate. The certificate covers the services and every pod of the
+	// instance, it's stored in the Secret <name>-tls.
+	IssuerRef IssuerReference `json:"issuerRef"`
+
+	// Additional DNS names for the certificate, e.g. external hostnames.
+	// +optional
+	DNSNames []string `json:"dnsNames,omitempty"`
+}
+
+type IssuerReference struct {
+	// Name of the issuer.
+	Name string `json:"name"`
+
+	// Kind of the issuer.
+	// +kubebuilder:default=Issuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Group of the issuer, for issuers not part of cert-manager.
+	// +kubebuilder:default=cert-manager.io
+	// +optional
+	Group string `json:"group,omitempty"`
+}
+
+// IngressKind selects the kind of object routing HTTP traffic to the management interface.
+// +kubebuilder:validation:Enum=Ingress;HTTPRoute
+type IngressKind string
+
+const (
+	IngressKindIngress   IngressKind = "Ingress"
+	IngressKindHTTPRoute IngressKind = "HTTPRoute"
+)
+
+type IngressSpec struct {
+	// Render a networking.k8s.io/v1 Ingress or a gateway.networking.k8s.io/v1 HTTPRoute.
+	// +kubebuilder:default=Ingress
+	// +optional
+	Kind IngressKind `json:"kind,omitempty"`
+
+	// Hostnames the management interface is served on.
+	// +kubebuilder:validation:MinItems=1
+	Hostnames []string `json:"hostnames"`
+
+	// IngressClassName of the Ingress. Only used with kind Ingress.
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+
+	// Name of the Secret holding the certificate for the hostnames. Only used with kind Ingress,
+	// for HTTPRoutes TLS is terminated by the listener of the Gateway.
+	// +optional
+	TlsSecretName string `json:"tlsSecretName,omitempty"`
+
+	// Gateways the HTTPRoute attaches to. Required with kind HTTPRoute.
+	// +optional
+	ParentRefs []GatewayParentReference `json:"parentRefs,omitempty"`
+
+	// Annotations set on the Ingress or HTTPRoute.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type GatewayParentReference struct {
+	// Name of the Gateway.
+	Name string `json:"name"`
+
+	// Namespace of the Gateway, defaults to the namespace of the LavinMQ instance.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the Gateway listener to attach to.
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
 }
 
 type MainConfig struct {
@@ -205,6 +611,10 @@ type ClusteringConfig struct {
 	// Maximum number of unsynced actions allowed in the cluster.
 	// +optional
 	MaxUnsyncedActions uint64 `json:"max_unsynced_actions,omitempty"`
+	// Encrypt the replication traffic between the nodes with the broker certificate
+	// from tlsSecret or tls.
+	// +optional
+	Tls bool `json:"tls,omitempty"`
 }
 
 type LavinMQConfig struct {
@@ -215,18 +625,156 @@ type LavinMQConfig struct {
 	Clustering ClusteringConfig `json:"clustering,omitempty"`
 }
 
+// LavinMQPhase is a high-level summary of where the LavinMQ cluster is in its lifecycle.
+// +kubebuilder:validation:Enum=Pending;Running;Updating;Degraded;Terminating
+type LavinMQPhase string
+
+const (
+	// LavinMQPhasePending means no LavinMQ pod is ready yet.
+	LavinMQPhasePending LavinMQPhase = "Pending"
+	// LavinMQPhaseRunning means all replicas are ready and running the desired revision.
+	LavinMQPhaseRunning LavinMQPhase = "Running"
+	// LavinMQPhaseUpdating means a rollout or scaling operation is in progress.
+	LavinMQPhaseUpdating LavinMQPhase = "Updating"
+	// LavinMQPhaseDegraded means the operator failed to reconcile one of the owned resources.
+	LavinMQPhaseDegraded LavinMQPhase = "Degraded"
+	// LavinMQPhaseTerminating means the instance is being deleted and its etcd state cleaned up.
+	LavinMQPhaseTerminating LavinMQPhase = "Terminating"
+)
+
 // LavinMQStatus defines the observed state of LavinMQ
 type LavinMQStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// The generation of the LavinMQ resource most recently observed by the operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Number of LavinMQ pods with a Ready condition.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// Number of LavinMQ pods running the latest revision.
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// High-level summary of the cluster state.
+	// +optional
+	Phase LavinMQPhase `json:"phase,omitempty"`
+
+	// The image all replicas are running once the latest rollout has completed.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Name of the pod currently acting as leader.
+	// +optional
+	Leader string `json:"leader,omitempty"`
+
+	// Key prefix in etcd the clustering state of the instance is kept under.
+	// +optional
+	EtcdPrefix string `json:"etcdPrefix,omitempty"`
+
+	// Volume being replaced by an ongoing volume migration.
+	// +optional
+	VolumeMigration *VolumeMigrationStatus `json:"volumeMigration,omitempty"`
+
+	// Size and resize state of the data volumes.
+	// +optional
+	Volumes []VolumeStatus `json:"volumes,omitempty"`
 
 	// Conditions store the status conditions of the LavinMQ instances
 	// +lavinmq-operator:csv:customresourcedefinitions:type=status
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
 }
 
+// TlsSecretName returns the name of the Secret holding the broker certificate, either the one referenced
+// by spec.tlsSecret or the one issued by cert-manager. Empty if TLS isn't configured.
+func (r *LavinMQ) TlsSecretName() string {
+	if r.Spec.TlsSecret != nil {
+		return r.Spec.TlsSecret.Name
+	}
+
+	if r.Spec.Tls != nil {
+		return r.Name + "-tls"
+	}
+
+	return ""
+}
+
+// ManagedEtcd reports whether the operator provisions the etcd cluster of this instance.
+func (r *LavinMQ) ManagedEtcd() bool {
+	return r.Spec.Etcd != nil && r.Spec.Etcd.Managed
+}
+
+// ManagedEtcdName returns the name of the StatefulSet and headless Service of the managed etcd cluster.
+func (r *LavinMQ) ManagedEtcdName() string {
+	return r.Name + "-etcd"
+}
+
+// ManagedEtcdClientSecretName returns the name of the Secret with the client certificate generated for the
+// managed etcd cluster.
+func (r *LavinMQ) ManagedEtcdClientSecretName() string {
+	return r.ManagedEtcdName() + "-client-tls"
+}
+
+// EtcdPrefix returns the key prefix in etcd the clustering state is kept under: spec.etcd.prefix, the prefix
+// recorded in the status once in use, or a default unique to the instance.
+func (r *LavinMQ) EtcdPrefix() string {
+	if r.Spec.Etcd != nil && r.Spec.Etcd.Prefix != "" {
+		return r.Spec.Etcd.Prefix
+	}
+
+	if r.Status.EtcdPrefix != "" {
+		return r.Status.EtcdPrefix
+	}
+
+	return fmt.Sprintf("%s-%s-%s", r.Namespace, r.Name, r.UID)
+}
+
+// EtcdDeletionPolicy returns what to do with the etcd prefix when the instance is deleted, Delete unless set.
+func (r *LavinMQ) EtcdDeletionPolicy() EtcdDeletionPolicy {
+	if r.Spec.Etcd == nil || r.Spec.Etcd.DeletionPolicy == "" {
+		return EtcdDeletionPolicyDelete
+	}
+
+	return r.Spec.Etcd.DeletionPolicy
+}
+
+// EtcdEndpoints returns the etcd endpoints used for clustering, the members of the managed etcd
+// cluster or spec.etcdEndpoints. Nil if clustering isn't enabled.
+func (r *LavinMQ) EtcdEndpoints() []string {
+	if !r.ManagedEtcd() {
+		return r.Spec.EtcdEndpoints
+	}
+
+	name := r.ManagedEtcdName()
+	endpoints := []string{}
+	for i := range r.Spec.Etcd.MemberCount() {
+		endpoints = append(endpoints, fmt.Sprintf("https://%s-%d.%s.%s.svc.cluster.local:2379", name, i, name, r.Namespace))
+	}
+
+	return endpoints
+}
+
+// EtcdTlsSecretName returns the name of the Secret with the client certificate for etcd, the one generated for
+// the managed etcd cluster or spec.etcd.tlsSecretRef. Empty if etcd isn't accessed with a client certificate.
+func (r *LavinMQ) EtcdTlsSecretName() string {
+	if r.ManagedEtcd() {
+		return r.ManagedEtcdClientSecretName()
+	}
+
+	if r.Spec.Etcd != nil && r.Spec.Etcd.TlsSecretRef != nil {
+		return r.Spec.Etcd.TlsSecretRef.Name
+	}
+
+	return ""
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.readyReplicas`
+// +kubebuilder:printcolumn:name="Leader",type=string,JSONPath=`.status.leader`
+// +kubebuilder:printcolumn:name="Image",type=string,JSONPath=`.status.image`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // LavinMQ is the Schema for the lavinmqs API
 type LavinMQ struct {
@@ -17,16 +17,26 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
+// defaultStorageClassAnnotation marks the StorageClass used by claims that don't name one.
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
 // log is for logging in this package.
 var lavinmqlog = logf.Log.WithName("lavinmq-resource")
 
@@ -35,10 +45,120 @@ func (r *LavinMQ) SetupWebhookWithManager(mgr ctrl.Manager) error {
 
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
-		WithValidator(r).
+		WithValidator(&lavinMQValidator{LavinMQ: r, client: mgr.GetClient()}).
 		Complete()
 }
 
+// lavinMQValidator adds the checks that need to look at other instances to the validation of a single LavinMQ.
+type lavinMQValidator struct {
+	*LavinMQ
+	client client.Reader
+}
+
+func (v *lavinMQValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	warnings, err := v.LavinMQ.ValidateCreate(ctx, obj)
+	if err != nil {
+		return warnings, err
+	}
+	return warnings, v.validateEtcdPrefix(ctx, obj.(*LavinMQ))
+}
+
+func (v *lavinMQValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	warnings, err := v.LavinMQ.ValidateUpdate(ctx, oldObj, newObj)
+	if err != nil {
+		return warnings, err
+	}
+	if err := v.validateVolumeExpansion(ctx, oldObj.(*LavinMQ), newObj.(*LavinMQ)); err != nil {
+		return warnings, err
+	}
+	return warnings, v.validateEtcdPrefix(ctx, newObj.(*LavinMQ))
+}
+
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+
+// validateVolumeExpansion rejects growing the data volumes when their StorageClass doesn't allow expansion,
+// the claims would be stuck with the old size. Shrinking is left to the volume migration.
+func (v *lavinMQValidator) validateVolumeExpansion(ctx context.Context, oldLavinMQ, newLavinMQ *LavinMQ) error {
+	oldSize := oldLavinMQ.Spec.DataVolumeClaimSpec.Resources.Requests.Storage()
+	newSize := newLavinMQ.Spec.DataVolumeClaimSpec.Resources.Requests.Storage()
+	if newSize.Cmp(*oldSize) <= 0 {
+		return nil
+	}
+
+	storageClass, err := v.storageClass(ctx, newLavinMQ.Spec.DataVolumeClaimSpec.StorageClassName)
+	if err != nil {
+		return err
+	}
+	if storageClass == nil {
+		return fmt.Errorf("dataVolumeClaimSpec.resources.requests.storage can't be increased without a storage class")
+	}
+	if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+		return fmt.Errorf("dataVolumeClaimSpec.resources.requests.storage can't be increased, storage class %s doesn't allow volume expansion", storageClass.Name)
+	}
+
+	return nil
+}
+
+// storageClass returns the named StorageClass, or the default one when no name is given. Nil if there is none.
+func (v *lavinMQValidator) storageClass(ctx context.Context, name *string) (*storagev1.StorageClass, error) {
+	if name != nil {
+		storageClass := &storagev1.StorageClass{}
+		if err := v.client.Get(ctx, client.ObjectKey{Name: *name}, storageClass); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("storage class %s not found", *name)
+			}
+			return nil, fmt.Errorf("failed to get storage class %s: %w", *name, err)
+		}
+		return storageClass, nil
+	}
+
+	storageClasses := &storagev1.StorageClassList{}
+	if err := v.client.List(ctx, storageClasses); err != nil {
+		return nil, fmt.Errorf("failed to list storage classes: %w", err)
+	}
+	for i := range storageClasses.Items {
+		if storageClasses.Items[i].Annotations[defaultStorageClassAnnotation] == "true" {
+			return &storageClasses.Items[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// validateEtcdPrefix rejects a prefix overlapping with the prefix of another instance, as instances sharing
+// an etcd cluster would join each other's cluster. A managed etcd cluster is never shared.
+func (v *lavinMQValidator) validateEtcdPrefix(ctx context.Context, lavin *LavinMQ) error {
+	if lavin.ManagedEtcd() || len(lavin.Spec.EtcdEndpoints) == 0 {
+		return nil
+	}
+
+	instances := &LavinMQList{}
+	if err := v.client.List(ctx, instances); err != nil {
+		return fmt.Errorf("failed to list LavinMQ instances: %w", err)
+	}
+
+	prefix := lavin.EtcdPrefix()
+	for _, other := range instances.Items {
+		if other.Namespace == lavin.Namespace && other.Name == lavin.Name {
+			continue
+		}
+		if other.ManagedEtcd() || len(other.Spec.EtcdEndpoints) == 0 {
+			continue
+		}
+		if etcdPrefixesOverlap(prefix, other.EtcdPrefix()) {
+			return fmt.Errorf("etcd prefix %q overlaps with the prefix of LavinMQ %s/%s", prefix, other.Namespace, other.Name)
+		}
+	}
+
+	return nil
+}
+
+// etcdPrefixesOverlap reports whether the keys under one prefix can end up under the other, keys are
+// stored as <prefix>/<key>.
+func etcdPrefixesOverlap(a, b string) bool {
+	return a == b || strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
+
 // TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
 // NOTE: The 'path' attribute must follow a specific pattern and should not be modified directly here.
 // Modifying the path for an invalid path can cause API server errors; failing to locate the webhook.
@@ -50,9 +170,12 @@ var _ webhook.CustomValidator = &LavinMQ{}
 func (r *LavinMQ) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
 	lavin := obj.(*LavinMQ)
 	lavinmqlog.Info("validating create", "name", lavin.Name)
-	if lavin.Spec.Replicas > 1 && len(lavin.Spec.EtcdEndpoints) == 0 {
+	if lavin.Spec.Replicas > 1 && len(lavin.EtcdEndpoints()) == 0 {
 		return nil, fmt.Errorf("a provided etcd cluster is required for replication")
 	}
+	if err := validateSpec(&lavin.Spec); err != nil {
+		return nil, err
+	}
 	return nil, nil
 }
 
@@ -61,14 +184,27 @@ func (r *LavinMQ) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Obj
 	newLavinMQ := newObj.(*LavinMQ)
 	oldLavinMQ := oldObj.(*LavinMQ)
 	lavinmqlog.Info("validating update", "name", newLavinMQ.Name)
-	if newLavinMQ.Spec.Replicas > 1 && len(newLavinMQ.Spec.EtcdEndpoints) == 0 {
+	if newLavinMQ.Spec.Replicas > 1 && len(newLavinMQ.EtcdEndpoints()) == 0 {
 		return nil, fmt.Errorf("a provided etcd cluster is required for replication")
 	}
-	if oldLavinMQ.Spec.Replicas == 1 && len(oldLavinMQ.Spec.EtcdEndpoints) == 0 {
+	if oldLavinMQ.Spec.Replicas == 1 && len(oldLavinMQ.EtcdEndpoints()) == 0 {
 		if newLavinMQ.Spec.Replicas > 1 {
 			return nil, fmt.Errorf("in order to safely transition without message loss from single to multi node, first update to run the single node with etcd cluster, then update to multi node")
 		}
 	}
+	if oldLavinMQ.Spec.Replicas > 1 && oldLavinMQ.ManagedEtcd() != newLavinMQ.ManagedEtcd() {
+		return nil, fmt.Errorf("etcd.managed can't be changed while running multi node, scale down to a single node first")
+	}
+	if newLavinMQ.Spec.Etcd != nil && newLavinMQ.Spec.Etcd.Prefix != "" && newLavinMQ.Spec.Etcd.Prefix != oldLavinMQ.EtcdPrefix() {
+		return nil, fmt.Errorf("etcd.prefix can't be changed, the instance uses the prefix %q", oldLavinMQ.EtcdPrefix())
+	}
+	if oldLavinMQ.ManagedEtcd() && newLavinMQ.ManagedEtcd() &&
+		oldLavinMQ.Spec.Etcd.MemberCount() != newLavinMQ.Spec.Etcd.MemberCount() {
+		return nil, fmt.Errorf("etcd.replicas can't be changed on a managed etcd cluster")
+	}
+	if err := validateSpec(&newLavinMQ.Spec); err != nil {
+		return nil, err
+	}
 	return nil, nil
 }
 
@@ -76,3 +212,51 @@ func (r *LavinMQ) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Obj
 func (r *LavinMQ) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
 	return nil, nil
 }
+
+// validateSpec checks the fields that can be validated without looking at the previous version.
+func validateSpec(spec *LavinMQSpec) error {
+	if spec.DefaultUserSecretRef != nil && (spec.Config.Main.DefaultUser != "" || spec.Config.Main.DefaultPassword != "") {
+		return fmt.Errorf("defaultUserSecretRef can't be combined with config.main.default_user or config.main.default_password")
+	}
+	if spec.Etcd != nil && spec.Etcd.Managed && len(spec.EtcdEndpoints) > 0 {
+		return fmt.Errorf("etcdEndpoints can't be combined with etcd.managed")
+	}
+	if spec.TlsSecret != nil && spec.Tls != nil {
+		return fmt.Errorf("tlsSecret and tls are mutually exclusive")
+	}
+	if spec.Etcd != nil && spec.Etcd.Managed && (spec.Etcd.TlsSecretRef != nil || spec.Etcd.CredentialsSecretRef != nil) {
+		return fmt.Errorf("etcd.tlsSecretRef and etcd.credentialsSecretRef are only supported with etcdEndpoints")
+	}
+	if spec.Config.Clustering.Tls && spec.TlsSecret == nil && spec.Tls == nil {
+		return fmt.Errorf("config.clustering.tls requires tlsSecret or tls")
+	}
+	if spec.VolumeMigration != nil && spec.VolumeMigration.Strategy != VolumeMigrationStrategyMaintenanceWindow && spec.Replicas < 2 {
+		return fmt.Errorf("volumeMigration.strategy Rolling requires at least two replicas, use MaintenanceWindow for single node instances")
+	}
+	if err := validateIngress(spec.Ingress); err != nil {
+		return err
+	}
+	return validatePodTemplate(spec.PodTemplate)
+}
+
+func validateIngress(ingress *IngressSpec) error {
+	if ingress == nil {
+		return nil
+	}
+	if ingress.Kind == IngressKindHTTPRoute && len(ingress.ParentRefs) == 0 {
+		return fmt.Errorf("ingress.parentRefs is required for kind HTTPRoute")
+	}
+	return nil
+}
+
+func validatePodTemplate(podTemplate *runtime.RawExtension) error {
+	if podTemplate == nil || len(podTemplate.Raw) == 0 {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(podTemplate.Raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&corev1.PodTemplateSpec{}); err != nil {
+		return fmt.Errorf("podTemplate is not a valid pod template: %w", err)
+	}
+	return nil
+}
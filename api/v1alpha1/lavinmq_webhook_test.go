@@ -21,6 +21,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestCreateDefault(t *testing.T) {
@@ -114,9 +120,235 @@ func TestUpdateStandaloneWithEtcdToCluster(t *testing.T) {
 	assert.NoErrorf(t, err, "Failed to validate update")
 }
 
+func TestCreateHTTPRouteWithoutParentRefs(t *testing.T) {
+	t.Parallel()
+	lavinMQ := &LavinMQ{Spec: LavinMQSpec{
+		Ingress: &IngressSpec{Kind: IngressKindHTTPRoute, Hostnames: []string{"lavinmq.example.com"}},
+	}}
+	_, err := lavinMQ.ValidateCreate(context.TODO(), lavinMQ)
+	assert.Errorf(t, err, "Expected error when creating HTTPRoute without parentRefs")
+
+	lavinMQ.Spec.Ingress.ParentRefs = []GatewayParentReference{{Name: "gateway"}}
+	_, err = lavinMQ.ValidateCreate(context.TODO(), lavinMQ)
+	assert.NoErrorf(t, err, "Failed to validate create")
+}
+
+func TestCreateInvalidPodTemplate(t *testing.T) {
+	t.Parallel()
+	lavinMQ := &LavinMQ{Spec: LavinMQSpec{
+		PodTemplate: &runtime.RawExtension{Raw: []byte(`{"spec":{"initContainer":[{"name":"init"}]}}`)},
+	}}
+	_, err := lavinMQ.ValidateCreate(context.TODO(), lavinMQ)
+	assert.Errorf(t, err, "Expected error for unknown pod template field")
+
+	lavinMQ.Spec.PodTemplate.Raw = []byte(`{"spec":{"initContainers":[{"name":"init"}]}}`)
+	_, err = lavinMQ.ValidateCreate(context.TODO(), lavinMQ)
+	assert.NoErrorf(t, err, "Failed to validate create")
+}
+
+func TestCreateDefaultUserSecretWithConfiguredUser(t *testing.T) {
+	t.Parallel()
+	lavinMQ := &LavinMQ{Spec: LavinMQSpec{
+		DefaultUserSecretRef: &corev1.LocalObjectReference{Name: "default-user"},
+		Config:               LavinMQConfig{Main: MainConfig{DefaultUser: "admin"}},
+	}}
+	_, err := lavinMQ.ValidateCreate(context.TODO(), lavinMQ)
+	assert.Errorf(t, err, "Expected error when combining defaultUserSecretRef with default_user")
+}
+
+func TestCreateTlsSecretAndIssuer(t *testing.T) {
+	t.Parallel()
+	lavinMQ := &LavinMQ{Spec: LavinMQSpec{
+		TlsSecret: &corev1.SecretReference{Name: "tls"},
+		Tls:       &TlsSpec{IssuerRef: IssuerReference{Name: "ca"}},
+	}}
+	_, err := lavinMQ.ValidateCreate(context.TODO(), lavinMQ)
+	assert.Errorf(t, err, "Expected error when setting both tlsSecret and tls")
+}
+
 func TestDeleteDefault(t *testing.T) {
 	t.Parallel()
 	lavinMQ := &LavinMQ{}
 	_, err := lavinMQ.ValidateDelete(context.TODO(), lavinMQ)
 	assert.NoErrorf(t, err, "Failed to validate update")
 }
+
+func TestCreateClusterWithManagedEtcd(t *testing.T) {
+	t.Parallel()
+	lavinMQ := &LavinMQ{Spec: LavinMQSpec{
+		Replicas: 3,
+		Etcd:     &EtcdSpec{Managed: true},
+	}}
+	_, err := lavinMQ.ValidateCreate(context.TODO(), lavinMQ)
+	assert.NoErrorf(t, err, "Failed to validate create")
+}
+
+func TestCreateManagedEtcdWithEndpoints(t *testing.T) {
+	t.Parallel()
+	lavinMQ := &LavinMQ{Spec: LavinMQSpec{
+		Replicas:      3,
+		EtcdEndpoints: []string{"http://etcd-cluster:2379"},
+		Etcd:          &EtcdSpec{Managed: true},
+	}}
+	_, err := lavinMQ.ValidateCreate(context.TODO(), lavinMQ)
+	assert.Errorf(t, err, "Expected error when combining managed etcd with endpoints")
+}
+
+func TestUpdateManagedEtcd(t *testing.T) {
+	t.Parallel()
+	oldLavinMQ := &LavinMQ{Spec: LavinMQSpec{
+		Replicas: 3,
+		Etcd:     &EtcdSpec{Managed: true, Replicas: 3},
+	}}
+	newLavinMQ := &LavinMQ{Spec: LavinMQSpec{
+		Replicas: 3,
+		Etcd:     &EtcdSpec{Managed: true, Replicas: 5},
+	}}
+	_, err := newLavinMQ.ValidateUpdate(context.TODO(), oldLavinMQ, newLavinMQ)
+	assert.Errorf(t, err, "Expected error when changing managed etcd replicas")
+
+	newLavinMQ = &LavinMQ{Spec: LavinMQSpec{
+		Replicas:      3,
+		EtcdEndpoints: []string{"http://etcd-cluster:2379"},
+	}}
+	_, err = newLavinMQ.ValidateUpdate(context.TODO(), oldLavinMQ, newLavinMQ)
+	assert.Errorf(t, err, "Expected error when switching away from managed etcd while clustered")
+}
+
+func TestCreateManagedEtcdWithTlsSecret(t *testing.T) {
+	t.Parallel()
+	lavinMQ := &LavinMQ{Spec: LavinMQSpec{
+		Replicas: 3,
+		Etcd: &EtcdSpec{
+			Managed:      true,
+			TlsSecretRef: &corev1.LocalObjectReference{Name: "etcd-client"},
+		},
+	}}
+	_, err := lavinMQ.ValidateCreate(context.TODO(), lavinMQ)
+	assert.Errorf(t, err, "Expected error when combining managed etcd with a client certificate")
+}
+
+func TestCreateClusteringTlsWithoutCertificate(t *testing.T) {
+	t.Parallel()
+	lavinMQ := &LavinMQ{Spec: LavinMQSpec{
+		Replicas:      3,
+		EtcdEndpoints: []string{"https://etcd-cluster:2379"},
+		Config:        LavinMQConfig{Clustering: ClusteringConfig{Tls: true}},
+	}}
+	_, err := lavinMQ.ValidateCreate(context.TODO(), lavinMQ)
+	assert.Errorf(t, err, "Expected error when enabling clustering TLS without a certificate")
+
+	lavinMQ.Spec.TlsSecret = &corev1.SecretReference{Name: "lavinmq-tls"}
+	_, err = lavinMQ.ValidateCreate(context.TODO(), lavinMQ)
+	assert.NoErrorf(t, err, "Failed to validate create")
+}
+
+func TestCreateRollingVolumeMigrationSingleNode(t *testing.T) {
+	t.Parallel()
+	lavinMQ := &LavinMQ{Spec: LavinMQSpec{
+		Replicas:        1,
+		VolumeMigration: &VolumeMigrationSpec{Strategy: VolumeMigrationStrategyRolling},
+	}}
+	_, err := lavinMQ.ValidateCreate(context.TODO(), lavinMQ)
+	assert.Errorf(t, err, "Expected error when migrating the volume of a single node without a maintenance window")
+
+	lavinMQ.Spec.VolumeMigration.Strategy = VolumeMigrationStrategyMaintenanceWindow
+	_, err = lavinMQ.ValidateCreate(context.TODO(), lavinMQ)
+	assert.NoErrorf(t, err, "Failed to validate create")
+}
+
+func TestEtcdPrefixCollision(t *testing.T) {
+	t.Parallel()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, AddToScheme(scheme))
+
+	existing := &LavinMQ{
+		ObjectMeta: metav1.ObjectMeta{Name: "broker", Namespace: "team-a"},
+		Spec:       LavinMQSpec{EtcdEndpoints: []string{"http://etcd:2379"}},
+		Status:     LavinMQStatus{EtcdPrefix: "brokers/a"},
+	}
+	validator := &lavinMQValidator{
+		LavinMQ: &LavinMQ{},
+		client:  fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build(),
+	}
+
+	lavinMQ := &LavinMQ{
+		ObjectMeta: metav1.ObjectMeta{Name: "broker", Namespace: "team-b", UID: "b"},
+		Spec:       LavinMQSpec{EtcdEndpoints: []string{"http://etcd:2379"}},
+	}
+	_, err := validator.ValidateCreate(context.TODO(), lavinMQ)
+	assert.NoErrorf(t, err, "The default prefix includes the namespace and UID")
+
+	for _, prefix := range []string{"brokers/a", "brokers", "brokers/a/b"} {
+		lavinMQ.Spec.Etcd = &EtcdSpec{Prefix: prefix}
+		_, err = validator.ValidateCreate(context.TODO(), lavinMQ)
+		assert.Errorf(t, err, "Expected prefix %q to collide", prefix)
+	}
+
+	lavinMQ.Spec.Etcd = &EtcdSpec{Prefix: "brokers/ab"}
+	_, err = validator.ValidateCreate(context.TODO(), lavinMQ)
+	assert.NoErrorf(t, err, "Failed to validate create")
+}
+
+func TestUpdateEtcdPrefix(t *testing.T) {
+	t.Parallel()
+	oldLavinMQ := &LavinMQ{
+		Spec:   LavinMQSpec{Replicas: 3, EtcdEndpoints: []string{"http://etcd:2379"}},
+		Status: LavinMQStatus{EtcdPrefix: "broker"},
+	}
+	newLavinMQ := oldLavinMQ.DeepCopy()
+	newLavinMQ.Spec.Etcd = &EtcdSpec{Prefix: "broker"}
+	_, err := newLavinMQ.ValidateUpdate(context.TODO(), oldLavinMQ, newLavinMQ)
+	assert.NoErrorf(t, err, "Pinning the prefix in use is allowed")
+
+	newLavinMQ.Spec.Etcd.Prefix = "other"
+	_, err = newLavinMQ.ValidateUpdate(context.TODO(), oldLavinMQ, newLavinMQ)
+	assert.Errorf(t, err, "Expected error when changing the prefix")
+}
+
+func TestUpdateVolumeExpansion(t *testing.T) {
+	t.Parallel()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, AddToScheme(scheme))
+	assert.NoError(t, storagev1.AddToScheme(scheme))
+
+	expandable := &storagev1.StorageClass{
+		ObjectMeta:           metav1.ObjectMeta{Name: "expandable"},
+		AllowVolumeExpansion: &[]bool{true}[0],
+	}
+	fixed := &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "fixed",
+			Annotations: map[string]string{defaultStorageClassAnnotation: "true"},
+		},
+	}
+	validator := &lavinMQValidator{
+		LavinMQ: &LavinMQ{},
+		client:  fake.NewClientBuilder().WithScheme(scheme).WithObjects(expandable, fixed).Build(),
+	}
+
+	oldLavinMQ := &LavinMQ{
+		Spec: LavinMQSpec{
+			Replicas: 1,
+			DataVolumeClaimSpec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: &expandable.Name,
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				},
+			},
+		},
+	}
+	newLavinMQ := oldLavinMQ.DeepCopy()
+	newLavinMQ.Spec.DataVolumeClaimSpec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("20Gi")
+	_, err := validator.ValidateUpdate(context.TODO(), oldLavinMQ, newLavinMQ)
+	assert.NoErrorf(t, err, "Failed to validate update")
+
+	newLavinMQ.Spec.DataVolumeClaimSpec.StorageClassName = nil
+	oldLavinMQ.Spec.DataVolumeClaimSpec.StorageClassName = nil
+	_, err = validator.ValidateUpdate(context.TODO(), oldLavinMQ, newLavinMQ)
+	assert.ErrorContainsf(t, err, "storage class fixed doesn't allow volume expansion", "Expected the default storage class to be checked")
+
+	newLavinMQ.Spec.DataVolumeClaimSpec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("10Gi")
+	_, err = validator.ValidateUpdate(context.TODO(), oldLavinMQ, newLavinMQ)
+	assert.NoErrorf(t, err, "Only increasing the size is checked")
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// OperatorPolicySpec defines the desired state of OperatorPolicy. Operator policies apply to queues next to
+// the regular policies, limiting what policies and clients can set, e.g. an upper bound of max-length.
+// +kubebuilder:validation:XValidation:rule="has(self.name) == has(oldSelf.name) && (!has(self.name) || self.name == oldSelf.name)",message="name is immutable"
+type OperatorPolicySpec struct {
+	// The LavinMQ instance in the same namespace to create the operator policy on.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="lavinmqRef is immutable"
+	LavinMQRef corev1.LocalObjectReference `json:"lavinmqRef"`
+
+	// Name of the operator policy, the name of the resource unless set.
+	// +kubebuilder:validation:MaxLength=255
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Vhost to create the operator policy in.
+	// +kubebuilder:default="/"
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="vhost is immutable"
+	// +optional
+	Vhost string `json:"vhost,omitempty"`
+
+	// Regular expression matching the names of the queues the operator policy applies to.
+	Pattern string `json:"pattern"`
+
+	// Of the operator policies matching a queue the one with the highest priority is in effect.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// Settings applied to the matching queues, e.g. message-ttl, max-length or expires.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Definition *runtime.RawExtension `json:"definition"`
+}
+
+// OperatorPolicyStatus defines the observed state of OperatorPolicy
+type OperatorPolicyStatus struct {
+	// Number of queues the operator policy is in effect for, an operator policy with a higher priority
+	// matching the same queues takes precedence.
+	// +optional
+	AppliedTo int32 `json:"appliedTo,omitempty"`
+
+	// Conditions store the status conditions of the operator policy, Ready tells whether the broker applied it.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// LavinMQName returns the name of the LavinMQ instance the operator policy is created on.
+func (p *OperatorPolicy) LavinMQName() string {
+	return p.Spec.LavinMQRef.Name
+}
+
+// PolicyName returns the name of the operator policy on the broker, spec.name or the name of the resource.
+func (p *OperatorPolicy) PolicyName() string {
+	if p.Spec.Name != "" {
+		return p.Spec.Name
+	}
+
+	return p.Name
+}
+
+// StatusConditions returns the conditions of the operator policy for updating in place.
+func (p *OperatorPolicy) StatusConditions() *[]metav1.Condition {
+	return &p.Status.Conditions
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Pattern",type=string,JSONPath=`.spec.pattern`
+// +kubebuilder:printcolumn:name="Vhost",type=string,JSONPath=`.spec.vhost`
+// +kubebuilder:printcolumn:name="Applied To",type=integer,JSONPath=`.status.appliedTo`
+// +kubebuilder:printcolumn:name="LavinMQ",type=string,JSONPath=`.spec.lavinmqRef.name`,priority=1
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// OperatorPolicy is the Schema for the operatorpolicies API
+type OperatorPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OperatorPolicySpec   `json:"spec,omitempty"`
+	Status OperatorPolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OperatorPolicyList contains a list of OperatorPolicy
+type OperatorPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OperatorPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OperatorPolicy{}, &OperatorPolicyList{})
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PermissionSpec defines the desired state of Permission, the access of a user to a vhost. The regular
+// expressions match the names of the queues and exchanges the user may access, empty grants no access.
+type PermissionSpec struct {
+	// The LavinMQ instance in the same namespace to grant the permission on.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="lavinmqRef is immutable"
+	LavinMQRef corev1.LocalObjectReference `json:"lavinmqRef"`
+
+	// Name of the user on the broker, e.g. the user of a User resource.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="user is immutable"
+	User string `json:"user"`
+
+	// Vhost the user is granted access to.
+	// +kubebuilder:default="/"
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="vhost is immutable"
+	// +optional
+	Vhost string `json:"vhost,omitempty"`
+
+	// Queues and exchanges the user may declare and delete, e.g. ".*" or "^app\.".
+	// +optional
+	Configure string `json:"configure,omitempty"`
+
+	// Queues and exchanges the user may publish to or bind to.
+	// +optional
+	Write string `json:"write,omitempty"`
+
+	// Queues and exchanges the user may consume from or bind from.
+	// +optional
+	Read string `json:"read,omitempty"`
+}
+
+// PermissionStatus defines the observed state of Permission
+type PermissionStatus struct {
+	// Conditions store the status conditions of the permission, Ready tells whether it's granted as specified.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// LavinMQName returns the name of the LavinMQ instance the permission is granted on.
+func (p *Permission) LavinMQName() string {
+	return p.Spec.LavinMQRef.Name
+}
+
+// StatusConditions returns the conditions of the permission for updating in place.
+func (p *Permission) StatusConditions() *[]metav1.Condition {
+	return &p.Status.Conditions
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="User",type=string,JSONPath=`.spec.user`
+// +kubebuilder:printcolumn:name="Vhost",type=string,JSONPath=`.spec.vhost`
+// +kubebuilder:printcolumn:name="Configure",type=string,JSONPath=`.spec.configure`,priority=1
+// +kubebuilder:printcolumn:name="Write",type=string,JSONPath=`.spec.write`,priority=1
+// +kubebuilder:printcolumn:name="Read",type=string,JSONPath=`.spec.read`,priority=1
+// +kubebuilder:printcolumn:name="LavinMQ",type=string,JSONPath=`.spec.lavinmqRef.name`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Permission is the Schema for the permissions API
+type Permission struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PermissionSpec   `json:"spec,omitempty"`
+	Status PermissionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PermissionList contains a list of Permission
+type PermissionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Permission `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Permission{}, &PermissionList{})
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// QueueSpec defines the desired state of Queue. AMQP doesn't allow changing the properties of an existing
+// queue, changing them is reported as a conflict until the queue is deleted and declared again.
+// +kubebuilder:validation:XValidation:rule="has(self.name) == has(oldSelf.name) && (!has(self.name) || self.name == oldSelf.name)",message="name is immutable"
+type QueueSpec struct {
+	// The LavinMQ instance in the same namespace to declare the queue on.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="lavinmqRef is immutable"
+	LavinMQRef corev1.LocalObjectReference `json:"lavinmqRef"`
+
+	// Name of the queue, the name of the resource unless set.
+	// +kubebuilder:validation:MaxLength=255
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Vhost to declare the queue in.
+	// +kubebuilder:default="/"
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="vhost is immutable"
+	// +optional
+	Vhost string `json:"vhost,omitempty"`
+
+	// Whether the queue survives a broker restart.
+	// +kubebuilder:default=true
+	// +optional
+	Durable *bool `json:"durable,omitempty"`
+
+	// Whether the queue is deleted once its last consumer unsubscribes.
+	// +optional
+	AutoDelete bool `json:"autoDelete,omitempty"`
+
+	// Queue arguments, e.g. x-max-length or x-queue-type.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Arguments *runtime.RawExtension `json:"arguments,omitempty"`
+}
+
+// QueueStatus defines the observed state of Queue
+type QueueStatus struct {
+	// Conditions store the status conditions of the queue, Ready tells whether it's declared as specified.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// LavinMQName returns the name of the LavinMQ instance the queue is declared on.
+func (q *Queue) LavinMQName() string {
+	return q.Spec.LavinMQRef.Name
+}
+
+// QueueName returns the name of the queue on the broker, spec.name or the name of the resource.
+func (q *Queue) QueueName() string {
+	if q.Spec.Name != "" {
+		return q.Spec.Name
+	}
+
+	return q.Name
+}
+
+// IsDurable reports whether the queue is durable, true unless spec.durable is false.
+func (q *Queue) IsDurable() bool {
+	return q.Spec.Durable == nil || *q.Spec.Durable
+}
+
+// StatusConditions returns the conditions of the queue for updating in place.
+func (q *Queue) StatusConditions() *[]metav1.Condition {
+	return &q.Status.Conditions
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Queue",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="Vhost",type=string,JSONPath=`.spec.vhost`
+// +kubebuilder:printcolumn:name="LavinMQ",type=string,JSONPath=`.spec.lavinmqRef.name`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Queue is the Schema for the queues API
+type Queue struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   QueueSpec   `json:"spec,omitempty"`
+	Status QueueStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// QueueList contains a list of Queue
+type QueueList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Queue `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Queue{}, &QueueList{})
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Shovel states as reported by LavinMQ
+const (
+	ShovelStateStarting   = "Starting"
+	ShovelStateRunning    = "Running"
+	ShovelStateTerminated = "Terminated"
+)
+
+// ShovelSource is where a shovel consumes messages from, a queue or an exchange.
+// +kubebuilder:validation:XValidation:rule="has(self.queue) != has(self.exchange)",message="exactly one of queue and exchange is required"
+type ShovelSource struct {
+	// URI of the source broker.
+	URI URISource `json:"uri"`
+
+	// Queue to consume from.
+	// +optional
+	Queue string `json:"queue,omitempty"`
+
+	// Exchange to consume from through a temporary queue bound with exchangeKey.
+	// +optional
+	Exchange string `json:"exchange,omitempty"`
+
+	// Routing key the temporary queue is bound to the exchange with.
+	// +optional
+	ExchangeKey string `json:"exchangeKey,omitempty"`
+
+	// Number of unacknowledged messages the shovel consumes at most.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	PrefetchCount int32 `json:"prefetchCount,omitempty"`
+}
+
+// ShovelDestination is where a shovel publishes messages to, a queue or an exchange.
+// +kubebuilder:validation:XValidation:rule="!(has(self.queue) && has(self.exchange))",message="queue and exchange are mutually exclusive"
+type ShovelDestination struct {
+	// URI of the destination broker.
+	URI URISource `json:"uri"`
+
+	// Queue to publish to through the default exchange.
+	// +optional
+	Queue string `json:"queue,omitempty"`
+
+	// Exchange to publish to.
+	// +optional
+	Exchange string `json:"exchange,omitempty"`
+
+	// Routing key to publish with, the one of the consumed message unless set.
+	// +optional
+	ExchangeKey string `json:"exchangeKey,omitempty"`
+}
+
+// ShovelSpec defines the desired state of Shovel
+// +kubebuilder:validation:XValidation:rule="has(self.name) == has(oldSelf.name) && (!has(self.name) || self.name == oldSelf.name)",message="name is immutable"
+type ShovelSpec struct {
+	// The LavinMQ instance in the same namespace to run the shovel on.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="lavinmqRef is immutable"
+	LavinMQRef corev1.LocalObjectReference `json:"lavinmqRef"`
+
+	// Name of the shovel, the name of the resource unless set.
+	// +kubebuilder:validation:MaxLength=255
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Vhost to create the shovel in.
+	// +kubebuilder:default="/"
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="vhost is immutable"
+	// +optional
+	Vhost string `json:"vhost,omitempty"`
+
+	Source      ShovelSource      `json:"source"`
+	Destination ShovelDestination `json:"destination"`
+
+	// When messages are acknowledged at the source.
+	// +kubebuilder:default=on-confirm
+	// +optional
+	AckMode AckMode `json:"ackMode,omitempty"`
+
+	// Seconds to wait before reconnecting after a connection failure.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ReconnectDelay int32 `json:"reconnectDelay,omitempty"`
+}
+
+// ShovelStatus defines the observed state of Shovel
+type ShovelStatus struct {
+	// State of the shovel as reported by LavinMQ, e.g. Running or Terminated.
+	// +optional
+	State string `json:"state,omitempty"`
+
+	// Error the shovel last failed with.
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// Conditions store the status conditions of the shovel, Ready tells whether it's defined as specified.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// LavinMQName returns the name of the LavinMQ instance the shovel runs on.
+func (s *Shovel) LavinMQName() string {
+	return s.Spec.LavinMQRef.Name
+}
+
+// ShovelName returns the name of the shovel on the broker, spec.name or the name of the resource.
+func (s *Shovel) ShovelName() string {
+	if s.Spec.Name != "" {
+		return s.Spec.Name
+	}
+
+	return s.Name
+}
+
+// URISources returns the sources of the URIs the shovel connects to.
+func (s *Shovel) URISources() []URISource {
+	return []URISource{s.Spec.Source.URI, s.Spec.Destination.URI}
+}
+
+// StatusConditions returns the conditions of the shovel for updating in place.
+func (s *Shovel) StatusConditions() *[]metav1.Condition {
+	return &s.Status.Conditions
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Vhost",type=string,JSONPath=`.spec.vhost`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="LavinMQ",type=string,JSONPath=`.spec.lavinmqRef.name`,priority=1
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Shovel is the Schema for the shovels API
+type Shovel struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ShovelSpec   `json:"spec,omitempty"`
+	Status ShovelStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ShovelList contains a list of Shovel
+type ShovelList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Shovel `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Shovel{}, &ShovelList{})
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UserTag grants a user access to the management interface and API.
+// +kubebuilder:validation:Enum=administrator;monitoring;management;policymaker;impersonator
+type UserTag string
+
+const (
+	UserTagAdministrator UserTag = "administrator"
+	UserTagMonitoring    UserTag = "monitoring"
+	UserTagManagement    UserTag = "management"
+	UserTagPolicymaker   UserTag = "policymaker"
+	UserTagImpersonator  UserTag = "impersonator"
+)
+
+// UserSpec defines the desired state of User
+// +kubebuilder:validation:XValidation:rule="has(self.name) == has(oldSelf.name) && (!has(self.name) || self.name == oldSelf.name)",message="name is immutable"
+type UserSpec struct {
+	// The LavinMQ instance in the same namespace to create the user on.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="lavinmqRef is immutable"
+	LavinMQRef corev1.LocalObjectReference `json:"lavinmqRef"`
+
+	// Name of the user, the name of the resource unless set. The operator user lavinmq-operator is reserved.
+	// +kubebuilder:validation:MaxLength=255
+	// +kubebuilder:validation:XValidation:rule="self != 'lavinmq-operator'",message="lavinmq-operator is reserved for the operator"
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Secret holding the password of the user, key "password". The Secret is generated with a random password
+	// and the username if it doesn't exist, <name>-credentials unless set. Changing the password in the Secret
+	// changes the password of the user.
+	// +optional
+	PasswordSecretRef *corev1.LocalObjectReference `json:"passwordSecretRef,omitempty"`
+
+	// Tags of the user, giving access to the management interface and API.
+	// +listType=set
+	// +optional
+	Tags []UserTag `json:"tags,omitempty"`
+}
+
+// UserStatus defines the observed state of User
+type UserStatus struct {
+	// Resource version of the password Secret the password of the user was last set from.
+	// +optional
+	PasswordSecretVersion string `json:"passwordSecretVersion,omitempty"`
+
+	// Conditions store the status conditions of the user, Ready tells whether it exists as specified.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// LavinMQName returns the name of the LavinMQ instance the user is created on.
+func (u *User) LavinMQName() string {
+	return u.Spec.LavinMQRef.Name
+}
+
+// Username returns the name of the user on the broker, spec.name or the name of the resource.
+func (u *User) Username() string {
+	if u.Spec.Name != "" {
+		return u.Spec.Name
+	}
+
+	return u.Name
+}
+
+// PasswordSecretName returns the name of the Secret holding the password, spec.passwordSecretRef or the
+// Secret generated for the user.
+func (u *User) PasswordSecretName() string {
+	if u.Spec.PasswordSecretRef != nil {
+		return u.Spec.PasswordSecretRef.Name
+	}
+
+	return u.Name + "-credentials"
+}
+
+// StatusConditions returns the conditions of the user for updating in place.
+func (u *User) StatusConditions() *[]metav1.Condition {
+	return &u.Status.Conditions
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="User",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="Tags",type=string,JSONPath=`.spec.tags`
+// +kubebuilder:printcolumn:name="LavinMQ",type=string,JSONPath=`.spec.lavinmqRef.name`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// User is the Schema for the users API
+type User struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   UserSpec   `json:"spec,omitempty"`
+	Status UserStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// UserList contains a list of User
+type UserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []User `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&User{}, &UserList{})
+}
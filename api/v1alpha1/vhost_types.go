@@ -0,0 +1,117 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VhostDeletionPolicy tells what happens to the vhost on the broker when its Vhost resource is deleted.
+// +kubebuilder:validation:Enum=Delete;Retain
+type VhostDeletionPolicy string
+
+const (
+	// VhostDeletionPolicyDelete deletes the vhost with all queues, exchanges and messages in it.
+	VhostDeletionPolicyDelete VhostDeletionPolicy = "Delete"
+	// VhostDeletionPolicyRetain leaves the vhost on the broker.
+	VhostDeletionPolicyRetain VhostDeletionPolicy = "Retain"
+)
+
+// VhostSpec defines the desired state of Vhost
+// +kubebuilder:validation:XValidation:rule="has(self.name) == has(oldSelf.name) && (!has(self.name) || self.name == oldSelf.name)",message="name is immutable"
+type VhostSpec struct {
+	// The LavinMQ instance in the same namespace to create the vhost on.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="lavinmqRef is immutable"
+	LavinMQRef corev1.LocalObjectReference `json:"lavinmqRef"`
+
+	// Name of the vhost, the name of the resource unless set.
+	// +kubebuilder:validation:MaxLength=255
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// What happens to the vhost when the resource is deleted. Deleting a vhost deletes everything in it,
+	// including messages, so it's only deleted when set to Delete explicitly.
+	// +kubebuilder:default=Retain
+	// +optional
+	DeletionPolicy VhostDeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// VhostStatus defines the observed state of Vhost
+type VhostStatus struct {
+	// Conditions store the status conditions of the vhost, Ready tells whether it exists.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// LavinMQName returns the name of the LavinMQ instance the vhost is created on.
+func (v *Vhost) LavinMQName() string {
+	return v.Spec.LavinMQRef.Name
+}
+
+// VhostName returns the name of the vhost on the broker, spec.name or the name of the resource.
+func (v *Vhost) VhostName() string {
+	if v.Spec.Name != "" {
+		return v.Spec.Name
+	}
+
+	return v.Name
+}
+
+// DeletionPolicy returns what to do with the vhost when the resource is deleted, Retain unless set.
+func (v *Vhost) DeletionPolicy() VhostDeletionPolicy {
+	if v.Spec.DeletionPolicy == "" {
+		return VhostDeletionPolicyRetain
+	}
+
+	return v.Spec.DeletionPolicy
+}
+
+// StatusConditions returns the conditions of the vhost for updating in place.
+func (v *Vhost) StatusConditions() *[]metav1.Condition {
+	return &v.Status.Conditions
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Vhost",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="Deletion Policy",type=string,JSONPath=`.spec.deletionPolicy`
+// +kubebuilder:printcolumn:name="LavinMQ",type=string,JSONPath=`.spec.lavinmqRef.name`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Vhost is the Schema for the vhosts API
+type Vhost struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VhostSpec   `json:"spec,omitempty"`
+	Status VhostStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VhostList contains a list of Vhost
+type VhostList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Vhost `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Vhost{}, &VhostList{})
+}
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BindingReconciler reconciles a Binding object
+type BindingReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// ManagementEndpoint replaces the address of the management API, see reconciler.ResourceReconciler.
+	ManagementEndpoint string
+}
+
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=bindings,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=bindings/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=bindings/finalizers,verbs=update
+
+// Reconcile creates the binding on the referenced LavinMQ instance, see topology.reconcile.
+func (r *BindingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	binding := &cloudamqpcomv1alpha1.Binding{}
+	if err := r.Get(ctx, req.NamespacedName, binding); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	t := topology{Client: r.Client, scheme: r.Scheme, recorder: r.Recorder, managementEndpoint: r.ManagementEndpoint}
+	return t.reconcile(ctx, binding, &bindingEntity{binding: binding})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BindingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return setupTopologyController(mgr, &cloudamqpcomv1alpha1.Binding{}, &cloudamqpcomv1alpha1.BindingList{}, r)
+}
+
+type bindingEntity struct {
+	binding *cloudamqpcomv1alpha1.Binding
+}
+
+// declare creates the binding unless one with the same routing key and arguments exists. A binding created
+// for a previous routing key or arguments, tracked by its properties key in the status, is removed.
+func (e *bindingEntity) declare(ctx context.Context, mc *management.Client) (bool, error) {
+	spec := e.binding.Spec
+	arguments, err := decodeArguments(spec.Arguments)
+	if err != nil {
+		return false, err
+	}
+
+	current, err := e.find(ctx, mc, arguments)
+	if err != nil {
+		return false, err
+	}
+
+	missing := current == nil
+	if missing {
+		err := mc.Bind(ctx, spec.Vhost, spec.Source, e.destinationType(), spec.Destination, management.BindingSettings{
+			RoutingKey: spec.RoutingKey,
+			Arguments:  arguments,
+		})
+		if err != nil {
+			return false, err
+		}
+		// The properties key is derived by the broker, look the binding up again to learn it.
+		if current, err = e.find(ctx, mc, arguments); err != nil {
+			return true, err
+		}
+		if current == nil {
+			return true, fmt.Errorf("%s not found after creating it", e.describe())
+		}
+	}
+
+	if previous := e.binding.Status.PropertiesKey; previous != "" && previous != current.PropertiesKey {
+		err := mc.Unbind(ctx, spec.Vhost, spec.Source, e.destinationType(), spec.Destination, previous)
+		if err != nil && !management.IsNotFound(err) {
+			return missing, err
+		}
+	}
+	e.binding.Status.PropertiesKey = current.PropertiesKey
+
+	return missing, nil
+}
+
+// find returns the binding with the routing key of the spec and the given arguments, nil if there is none.
+func (e *bindingEntity) find(ctx context.Context, mc *management.Client, arguments map[string]any) (*management.Binding, error) {
+	spec := e.binding.Spec
+	bindings, err := mc.Bindings(ctx, spec.Vhost, spec.Source, e.destinationType(), spec.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range bindings {
+		if bindings[i].RoutingKey == spec.RoutingKey && argumentsEqual(bindings[i].Arguments, arguments) {
+			return &bindings[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (e *bindingEntity) delete(ctx context.Context, mc *management.Client) error {
+	spec := e.binding.Spec
+	propertiesKey := e.binding.Status.PropertiesKey
+	if propertiesKey == "" {
+		arguments, err := decodeArguments(spec.Arguments)
+		if err != nil {
+			return err
+		}
+		current, err := e.find(ctx, mc, arguments)
+		if err != nil || current == nil {
+			return err
+		}
+		propertiesKey = current.PropertiesKey
+	}
+
+	return mc.Unbind(ctx, spec.Vhost, spec.Source, e.destinationType(), spec.Destination, propertiesKey)
+}
+
+func (e *bindingEntity) describe() string {
+	spec := e.binding.Spec
+	return fmt.Sprintf("binding from exchange %q to %s %q with routing key %q in vhost %q",
+		spec.Source, e.destinationType(), spec.Destination, spec.RoutingKey, spec.Vhost)
+}
+
+func (e *bindingEntity) destinationType() management.DestinationType {
+	if e.binding.Spec.DestinationType == cloudamqpcomv1alpha1.BindingDestinationExchange {
+		return management.DestinationTypeExchange
+	}
+
+	return management.DestinationTypeQueue
+}
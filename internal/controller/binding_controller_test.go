@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
+)
+
+func TestBindingLifecycle(t *testing.T) {
+	t.Parallel()
+	instance, server := setupTopology(t)
+	defer server.Close()
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	r := &BindingReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), ManagementEndpoint: server.URL}
+	mc := management.NewClient(server.URL, "lavinmq-operator", "secret")
+
+	binding := &cloudamqpcomv1alpha1.Binding{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: instance.Namespace},
+		Spec: cloudamqpcomv1alpha1.BindingSpec{
+			LavinMQRef:  corev1.LocalObjectReference{Name: instance.Name},
+			Source:      "events",
+			Destination: "orders",
+			RoutingKey:  "order.created",
+		},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), binding))
+
+	t.Log("The binding waits for its exchange and queue")
+	_, err := r.Reconcile(t.Context(), reconcileRequest(binding))
+	assert.NoError(t, err)
+	assert.Equal(t, reasonDependencyNotFound, readyCondition(t, binding).Reason)
+
+	assert.NoError(t, mc.DeclareExchange(t.Context(), "/", "events", management.ExchangeSettings{Type: "topic", Durable: true}))
+	assert.NoError(t, mc.DeclareQueue(t.Context(), "/", "orders", management.QueueSettings{Durable: true}))
+	_, err = r.Reconcile(t.Context(), reconcileRequest(binding))
+	assert.NoError(t, err)
+	assert.Equal(t, metav1.ConditionTrue, readyCondition(t, binding).Status)
+	assert.Equal(t, "order.created", binding.Status.PropertiesKey)
+
+	t.Log("Changing the routing key replaces the binding")
+	binding.Spec.RoutingKey = "order.*"
+	assert.NoError(t, k8sClient.Update(t.Context(), binding))
+	_, err = r.Reconcile(t.Context(), reconcileRequest(binding))
+	assert.NoError(t, err)
+	bindings, err := mc.Bindings(t.Context(), "/", "events", management.DestinationTypeQueue, "orders")
+	assert.NoError(t, err)
+	assert.Len(t, bindings, 1)
+	assert.Equal(t, "order.*", bindings[0].RoutingKey)
+
+	t.Log("Deleting the resource removes the binding")
+	assert.NoError(t, k8sClient.Get(t.Context(), reconcileRequest(binding).NamespacedName, binding))
+	assert.NoError(t, k8sClient.Delete(t.Context(), binding))
+	_, err = r.Reconcile(t.Context(), reconcileRequest(binding))
+	assert.NoError(t, err)
+	bindings, err = mc.Bindings(t.Context(), "/", "events", management.DestinationTypeQueue, "orders")
+	assert.NoError(t, err)
+	assert.Empty(t, bindings)
+}
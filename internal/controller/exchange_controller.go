@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ExchangeReconciler reconciles an Exchange object
+type ExchangeReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// ManagementEndpoint replaces the address of the management API, see reconciler.ResourceReconciler.
+	ManagementEndpoint string
+}
+
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=exchanges,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=exchanges/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=exchanges/finalizers,verbs=update
+
+// Reconcile declares the exchange on the referenced LavinMQ instance, see topology.reconcile.
+func (r *ExchangeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	exchange := &cloudamqpcomv1alpha1.Exchange{}
+	if err := r.Get(ctx, req.NamespacedName, exchange); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	t := topology{Client: r.Client, scheme: r.Scheme, recorder: r.Recorder, managementEndpoint: r.ManagementEndpoint}
+	return t.reconcile(ctx, exchange, &exchangeEntity{exchange: exchange})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ExchangeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return setupTopologyController(mgr, &cloudamqpcomv1alpha1.Exchange{}, &cloudamqpcomv1alpha1.ExchangeList{}, r)
+}
+
+type exchangeEntity struct {
+	exchange *cloudamqpcomv1alpha1.Exchange
+}
+
+// declare declares the exchange, the broker rejects declaring an existing exchange with other properties.
+func (e *exchangeEntity) declare(ctx context.Context, mc *management.Client) (bool, error) {
+	spec := e.exchange.Spec
+	arguments, err := decodeArguments(spec.Arguments)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = mc.Exchange(ctx, spec.Vhost, e.exchange.ExchangeName())
+	if err != nil && !management.IsNotFound(err) {
+		return false, err
+	}
+	missing := err != nil
+
+	err = mc.DeclareExchange(ctx, spec.Vhost, e.exchange.ExchangeName(), management.ExchangeSettings{
+		Type:       spec.Type,
+		Durable:    e.exchange.IsDurable(),
+		AutoDelete: spec.AutoDelete,
+		Internal:   spec.Internal,
+		Arguments:  arguments,
+	})
+
+	return missing, err
+}
+
+func (e *exchangeEntity) delete(ctx context.Context, mc *management.Client) error {
+	return mc.DeleteExchange(ctx, e.exchange.Spec.Vhost, e.exchange.ExchangeName())
+}
+
+func (e *exchangeEntity) describe() string {
+	return fmt.Sprintf("exchange %q in vhost %q", e.exchange.ExchangeName(), e.exchange.Spec.Vhost)
+}
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FederationUpstreamReconciler reconciles a FederationUpstream object
+type FederationUpstreamReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// ManagementEndpoint replaces the address of the management API, see reconciler.ResourceReconciler.
+	ManagementEndpoint string
+}
+
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=federationupstreams,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=federationupstreams/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=federationupstreams/finalizers,verbs=update
+
+// Reconcile defines the federation upstream on the referenced LavinMQ instance, see topology.reconcile.
+func (r *FederationUpstreamReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	upstream := &cloudamqpcomv1alpha1.FederationUpstream{}
+	if err := r.Get(ctx, req.NamespacedName, upstream); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	t := topology{Client: r.Client, scheme: r.Scheme, recorder: r.Recorder, managementEndpoint: r.ManagementEndpoint}
+	return t.reconcile(ctx, upstream, &federationUpstreamEntity{Client: r.Client, upstream: upstream})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *FederationUpstreamReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return setupParameterController(mgr, &cloudamqpcomv1alpha1.FederationUpstream{}, &cloudamqpcomv1alpha1.FederationUpstreamList{}, r)
+}
+
+type federationUpstreamEntity struct {
+	client.Client
+	upstream *cloudamqpcomv1alpha1.FederationUpstream
+}
+
+// declare defines the upstream with the URI resolved from its source. Exchanges and queues are federated
+// from it by policies setting federation-upstream to its name.
+func (e *federationUpstreamEntity) declare(ctx context.Context, mc *management.Client) (bool, error) {
+	spec := e.upstream.Spec
+	uri, err := resolveURI(ctx, e.Client, e.upstream.Namespace, spec.URI)
+	if err != nil {
+		return false, err
+	}
+
+	settings := management.FederationUpstreamSettings{
+		URI:            uri,
+		Exchange:       spec.Exchange,
+		Queue:          spec.Queue,
+		PrefetchCount:  int(spec.PrefetchCount),
+		ReconnectDelay: int(spec.ReconnectDelay),
+		AckMode:        string(spec.AckMode),
+		MaxHops:        int(spec.MaxHops),
+		Expires:        spec.Expires,
+		MessageTTL:     spec.MessageTTL,
+	}
+
+	return declareParameter(ctx, mc, management.ComponentFederationUpstream, spec.Vhost, e.upstream.UpstreamName(), settings)
+}
+
+func (e *federationUpstreamEntity) delete(ctx context.Context, mc *management.Client) error {
+	return mc.DeleteParameter(ctx, management.ComponentFederationUpstream, e.upstream.Spec.Vhost, e.upstream.UpstreamName())
+}
+
+func (e *federationUpstreamEntity) describe() string {
+	return fmt.Sprintf("federation upstream %q in vhost %q", e.upstream.UpstreamName(), e.upstream.Spec.Vhost)
+}
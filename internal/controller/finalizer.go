@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// lavinmqFinalizer keeps a deleted instance around until its clustering state in etcd has been cleaned up,
+// the owned Kubernetes resources are garbage collected through their owner references.
+const lavinmqFinalizer = "lavinmq.cloudamqp.com/finalizer"
+
+// finalize runs once the instance is marked for deletion. The etcd prefix is deleted according to
+// spec.etcd.deletionPolicy and the outcome is reported in the status, the finalizer is only removed
+// once that succeeded. Failures are retried, switching the policy to Retain lets the deletion through.
+func (r *LavinMQReconciler) finalize(ctx context.Context, resourceReconciler *reconciler.ResourceReconciler) (ctrl.Result, error) {
+	logger := resourceReconciler.Logger
+	instance := resourceReconciler.Instance
+
+	if !controllerutil.ContainsFinalizer(instance, lavinmqFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	prefix := resourceReconciler.EtcdPrefix()
+	deleting := metav1.Condition{
+		Type:               typeDeletingLavinMQ,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: instance.Generation,
+	}
+
+	var cleanupErr error
+	switch {
+	case instance.EtcdEndpoints() == nil:
+		deleting.Reason = "NoEtcd"
+		deleting.Message = "No clustering state to clean up"
+	case instance.EtcdDeletionPolicy() == cloudamqpcomv1alpha1.EtcdDeletionPolicyRetain:
+		deleting.Reason = "EtcdPrefixRetained"
+		deleting.Message = fmt.Sprintf("Keys under etcd prefix %q are retained", prefix)
+	default:
+		deleted, err := resourceReconciler.DeleteEtcdPrefix(ctx)
+		if err != nil {
+			deleting.Reason = "EtcdCleanupFailed"
+			deleting.Message = err.Error()
+			cleanupErr = err
+		} else {
+			logger.Info("Deleted etcd prefix", "prefix", prefix, "keys", deleted)
+			deleting.Reason = "EtcdPrefixDeleted"
+			deleting.Message = fmt.Sprintf("Deleted %d keys under etcd prefix %q", deleted, prefix)
+		}
+	}
+
+	original := instance.DeepCopy()
+	instance.Status.Phase = cloudamqpcomv1alpha1.LavinMQPhaseTerminating
+	meta.SetStatusCondition(&instance.Status.Conditions, deleting)
+	if err := r.Status().Patch(ctx, instance, client.MergeFrom(original)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if cleanupErr != nil {
+		return ctrl.Result{}, cleanupErr
+	}
+
+	controllerutil.RemoveFinalizer(instance, lavinmqFinalizer)
+	if err := r.Update(ctx, instance); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
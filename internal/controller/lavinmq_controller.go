@@ -21,26 +21,41 @@ import (
 	"fmt"
 
 	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/controller/utils"
 	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // Definitions to manage status conditions
 const (
-	// typeAvailableLavinMQ represents the status of the StatefulSet reconciliation
+	// typeAvailableLavinMQ represents whether at least one LavinMQ replica is ready to serve clients
 	typeAvailableLavinMQ = "Available"
-	// typeDegradedLavinMQ represents the status used when the custom resource is deleted and the finalizer operations are yet to occur.
+	// typeProgressingLavinMQ represents whether a rollout, scaling or other multi-step operation is ongoing
+	typeProgressingLavinMQ = "Progressing"
+	// typeDegradedLavinMQ represents whether one of the resource reconcilers failed
 	typeDegradedLavinMQ = "Degraded"
+	// typeDeletingLavinMQ represents the progress of cleaning up a deleted instance
+	typeDeletingLavinMQ = "Deleting"
 )
 
+// secretRefsIndex indexes LavinMQ instances by the names of the secrets they reference
+const secretRefsIndex = ".spec.secretRefs"
+
 // LavinMQReconciler reconciles a LavinMQ object
 type LavinMQReconciler struct {
 	client.Client
@@ -53,8 +68,16 @@ type LavinMQReconciler struct {
 // +kubebuilder:rbac:groups=cloudamqp.com,resources=lavinmqs/finalizers,verbs=update
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -90,28 +113,154 @@ func (r *LavinMQReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		Client:   r.Client,
 	}
 
+	if !instance.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &resourceReconciler)
+	}
+
+	if !controllerutil.ContainsFinalizer(instance, lavinmqFinalizer) {
+		controllerutil.AddFinalizer(instance, lavinmqFinalizer)
+		if err := r.Update(ctx, instance); err != nil {
+			logger.Error(err, "Failed to add finalizer")
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.recordEtcdPrefix(ctx, &resourceReconciler); err != nil {
+		if apierrors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		logger.Error(err, "Failed to record etcd prefix")
+		return ctrl.Result{}, err
+	}
+
 	reconcilers := resourceReconciler.Reconcilers()
 
+	result := ctrl.Result{}
+	outcome := reconcileOutcome{}
 	for _, reconciler := range reconcilers {
-		_, err := reconciler.Reconcile(ctx)
+		res, err := reconciler.Reconcile(ctx)
 		if err != nil {
 			logger.Error(err, "Failed to reconcile resource", "name", reconciler.Name())
+			outcome.failed = reconciler
+			outcome.err = err
+			break
+		}
+
+		if res.Requeue {
+			outcome.inProgress = append(outcome.inProgress, reconciler)
+		}
+		result = mergeResults(result, res)
+	}
+
+	if err := r.updateStatus(ctx, &resourceReconciler, outcome); err != nil {
+		if apierrors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		logger.Error(err, "Failed to update LavinMQ status")
+		if outcome.err == nil {
 			return ctrl.Result{}, err
 		}
 	}
 
+	if outcome.err != nil {
+		return ctrl.Result{}, outcome.err
+	}
+
 	logger.Info("Updated resources for LavinMQ")
 
-	return ctrl.Result{}, nil
+	return result, nil
+}
+
+// mergeResults combines the results of several reconcilers, requeueing as soon as any of them asked for it.
+func mergeResults(a, b ctrl.Result) ctrl.Result {
+	merged := ctrl.Result{Requeue: a.Requeue || b.Requeue}
+
+	switch {
+	case a.RequeueAfter == 0:
+		merged.RequeueAfter = b.RequeueAfter
+	case b.RequeueAfter == 0 || a.RequeueAfter < b.RequeueAfter:
+		merged.RequeueAfter = a.RequeueAfter
+	default:
+		merged.RequeueAfter = b.RequeueAfter
+	}
+
+	return merged
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *LavinMQReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &cloudamqpcomv1alpha1.LavinMQ{}, secretRefsIndex, secretRefs)
+	if err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&cloudamqpcomv1alpha1.LavinMQ{}).
 		Owns(&appsv1.StatefulSet{}).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.PersistentVolumeClaim{}).
+		Owns(&batchv1.Job{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&networkingv1.Ingress{}).
+		// Pods are owned by the StatefulSet, watch them to keep readiness and pod roles current.
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(podToLavinMQ)).
+		// Referenced secrets aren't necessarily owned by the instance, e.g. certificates managed elsewhere.
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.secretToLavinMQ)).
 		Complete(r)
 }
+
+// secretRefs returns the names of the secrets referenced by a LavinMQ instance, used as field index.
+func secretRefs(obj client.Object) []string {
+	instance := obj.(*cloudamqpcomv1alpha1.LavinMQ)
+	// The operator user Secret is generated again when deleted.
+	refs := []string{reconciler.OperatorUserSecretName(instance)}
+	if name := instance.TlsSecretName(); name != "" {
+		refs = append(refs, name)
+	}
+	if instance.Spec.DefaultUserSecretRef != nil {
+		refs = append(refs, instance.Spec.DefaultUserSecretRef.Name)
+	}
+	if etcd := instance.Spec.Etcd; etcd != nil {
+		if etcd.TlsSecretRef != nil {
+			refs = append(refs, etcd.TlsSecretRef.Name)
+		}
+		if etcd.CredentialsSecretRef != nil {
+			refs = append(refs, etcd.CredentialsSecretRef.Name)
+		}
+	}
+
+	return refs
+}
+
+// secretToLavinMQ maps a secret to the LavinMQ instances referencing it.
+func (r *LavinMQReconciler) secretToLavinMQ(ctx context.Context, obj client.Object) []reconcile.Request {
+	instances := &cloudamqpcomv1alpha1.LavinMQList{}
+	err := r.List(ctx, instances, client.InNamespace(obj.GetNamespace()), client.MatchingFields{secretRefsIndex: obj.GetName()})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list LavinMQ instances referencing secret", "name", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(instances.Items))
+	for _, instance := range instances.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace},
+		})
+	}
+
+	return requests
+}
+
+// podToLavinMQ maps a pod to the LavinMQ instance it belongs to, using the instance selector labels.
+func podToLavinMQ(_ context.Context, obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	name, ok := labels[utils.InstanceLabel]
+	if !ok || labels[utils.ManagedByLabel] != utils.ManagedByValue {
+		return nil
+	}
+
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: name, Namespace: obj.GetNamespace()}},
+	}
+}
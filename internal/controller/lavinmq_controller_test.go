@@ -20,8 +20,12 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -240,6 +244,73 @@ func TestUpdatingLavinMQ(t *testing.T) {
 
 }
 
+func TestLavinMQStatus(t *testing.T) {
+	t.Parallel()
+	reconciler, lavinmq := setupResources(t)
+
+	defer cleanupResources(t, lavinmq)
+
+	err := k8sClient.Create(t.Context(), lavinmq)
+	assert.NoErrorf(t, err, "Failed to create LavinMQ resource")
+
+	_, err = reconciler.Reconcile(t.Context(), reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      lavinmq.Name,
+			Namespace: lavinmq.Namespace,
+		},
+	})
+	assert.NoErrorf(t, err, "Failed to reconcile")
+
+	resource := &cloudamqpcomv1alpha1.LavinMQ{}
+	err = k8sClient.Get(t.Context(), types.NamespacedName{
+		Name:      lavinmq.Name,
+		Namespace: lavinmq.Namespace,
+	}, resource)
+	assert.NoErrorf(t, err, "Failed to get LavinMQ resource")
+
+	// There is no StatefulSet controller in the test environment, so no pods ever become ready.
+	assert.Equal(t, resource.Generation, resource.Status.ObservedGeneration)
+	assert.Equal(t, cloudamqpcomv1alpha1.LavinMQPhasePending, resource.Status.Phase)
+	assert.Equal(t, int32(0), resource.Status.ReadyReplicas)
+	assert.Empty(t, resource.Status.Leader)
+
+	assert.True(t, meta.IsStatusConditionFalse(resource.Status.Conditions, typeAvailableLavinMQ))
+	assert.True(t, meta.IsStatusConditionTrue(resource.Status.Conditions, typeProgressingLavinMQ))
+	assert.True(t, meta.IsStatusConditionFalse(resource.Status.Conditions, typeDegradedLavinMQ))
+}
+
+func TestMergeResults(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, reconcile.Result{}, mergeResults(reconcile.Result{}, reconcile.Result{}))
+	assert.Equal(t, reconcile.Result{Requeue: true}, mergeResults(reconcile.Result{Requeue: true}, reconcile.Result{}))
+	assert.Equal(t, reconcile.Result{RequeueAfter: time.Second},
+		mergeResults(reconcile.Result{RequeueAfter: time.Minute}, reconcile.Result{RequeueAfter: time.Second}))
+	assert.Equal(t, reconcile.Result{RequeueAfter: time.Minute},
+		mergeResults(reconcile.Result{}, reconcile.Result{RequeueAfter: time.Minute}))
+}
+
+func TestConditionReason(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "HeadlessServiceReconcileFailed", conditionReason("headless-service", "ReconcileFailed"))
+	assert.Equal(t, "PvcInProgress", conditionReason("pvc", "InProgress"))
+}
+
+func TestSecretRefs(t *testing.T) {
+	t.Parallel()
+	instance := &cloudamqpcomv1alpha1.LavinMQ{}
+	assert.Empty(t, secretRefs(instance))
+
+	instance.Spec.TlsSecret = &corev1.SecretReference{Name: "tls"}
+	instance.Spec.DefaultUserSecretRef = &corev1.LocalObjectReference{Name: "default-user"}
+	assert.Equal(t, []string{"tls", "default-user"}, secretRefs(instance))
+
+	instance.Spec.Etcd = &cloudamqpcomv1alpha1.EtcdSpec{
+		TlsSecretRef:         &corev1.LocalObjectReference{Name: "etcd-client"},
+		CredentialsSecretRef: &corev1.LocalObjectReference{Name: "etcd-credentials"},
+	}
+	assert.Equal(t, []string{"tls", "default-user", "etcd-client", "etcd-credentials"}, secretRefs(instance))
+}
+
 func setupResources(t *testing.T) (*LavinMQReconciler, *cloudamqpcomv1alpha1.LavinMQ) {
 	reconciler := &LavinMQReconciler{
 		Client: k8sClient,
@@ -292,3 +363,129 @@ func cleanupResources(t *testing.T, lavinmq *cloudamqpcomv1alpha1.LavinMQ) {
 	err = testutils.DeleteNamespace(t.Context(), k8sClient, namespace)
 	assert.NoErrorf(t, err, "Failed to delete namespace")
 }
+
+func TestFinalizerDeletesEtcdPrefix(t *testing.T) {
+	t.Parallel()
+	reconciler, lavinmq := setupResources(t)
+
+	defer cleanupResources(t, lavinmq)
+
+	server := testutils.StartFakeEtcd(map[string]string{
+		lavinmq.Name + "/leader":  "tcp://a",
+		lavinmq.Name + "/isr":     "b",
+		lavinmq.Name + "-other/x": "c",
+	})
+	defer server.Close()
+
+	lavinmq.Spec.EtcdEndpoints = []string{server.URL}
+	lavinmq.Spec.Etcd = &cloudamqpcomv1alpha1.EtcdSpec{Prefix: lavinmq.Name}
+	err := k8sClient.Create(t.Context(), lavinmq)
+	assert.NoErrorf(t, err, "Failed to create LavinMQ resource")
+
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: lavinmq.Name, Namespace: lavinmq.Namespace}}
+	_, err = reconciler.Reconcile(t.Context(), request)
+	assert.NoErrorf(t, err, "Failed to reconcile")
+
+	resource := &cloudamqpcomv1alpha1.LavinMQ{}
+	err = k8sClient.Get(t.Context(), request.NamespacedName, resource)
+	assert.NoErrorf(t, err, "Failed to get LavinMQ resource")
+	assert.Contains(t, resource.Finalizers, lavinmqFinalizer)
+	assert.Equal(t, lavinmq.Name, resource.Status.EtcdPrefix)
+
+	err = k8sClient.Delete(t.Context(), resource)
+	assert.NoErrorf(t, err, "Failed to delete LavinMQ resource")
+
+	_, err = reconciler.Reconcile(t.Context(), request)
+	assert.NoErrorf(t, err, "Failed to reconcile deletion")
+
+	assert.Equal(t, []string{lavinmq.Name + "-other/x"}, server.Keys())
+	err = k8sClient.Get(t.Context(), request.NamespacedName, resource)
+	assert.True(t, apierrors.IsNotFound(err), "Expected the instance to be gone once finalized")
+}
+
+func TestFinalizerWithUnreachableEtcd(t *testing.T) {
+	t.Parallel()
+	reconciler, lavinmq := setupResources(t)
+
+	defer cleanupResources(t, lavinmq)
+
+	lavinmq.Spec.EtcdEndpoints = []string{"http://127.0.0.1:1"}
+	lavinmq.Spec.Etcd = &cloudamqpcomv1alpha1.EtcdSpec{DeletionPolicy: cloudamqpcomv1alpha1.EtcdDeletionPolicyDelete}
+	err := k8sClient.Create(t.Context(), lavinmq)
+	assert.NoErrorf(t, err, "Failed to create LavinMQ resource")
+
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: lavinmq.Name, Namespace: lavinmq.Namespace}}
+	_, err = reconciler.Reconcile(t.Context(), request)
+	assert.NoErrorf(t, err, "Failed to reconcile")
+
+	resource := &cloudamqpcomv1alpha1.LavinMQ{}
+	assert.NoError(t, k8sClient.Get(t.Context(), request.NamespacedName, resource))
+	assert.NoError(t, k8sClient.Delete(t.Context(), resource))
+
+	t.Log("An unreachable etcd blocks the deletion and is reported in the status")
+	_, err = reconciler.Reconcile(t.Context(), request)
+	assert.Error(t, err)
+
+	assert.NoError(t, k8sClient.Get(t.Context(), request.NamespacedName, resource))
+	assert.Equal(t, cloudamqpcomv1alpha1.LavinMQPhaseTerminating, resource.Status.Phase)
+	deleting := meta.FindStatusCondition(resource.Status.Conditions, typeDeletingLavinMQ)
+	assert.NotNil(t, deleting)
+	assert.Equal(t, "EtcdCleanupFailed", deleting.Reason)
+
+	t.Log("Switching to Retain lets the deletion through")
+	resource.Spec.Etcd.DeletionPolicy = cloudamqpcomv1alpha1.EtcdDeletionPolicyRetain
+	assert.NoError(t, k8sClient.Update(t.Context(), resource))
+
+	_, err = reconciler.Reconcile(t.Context(), request)
+	assert.NoErrorf(t, err, "Failed to reconcile deletion")
+
+	err = k8sClient.Get(t.Context(), request.NamespacedName, resource)
+	assert.True(t, apierrors.IsNotFound(err), "Expected the instance to be gone once finalized")
+}
+
+func TestEtcdPrefixRecorded(t *testing.T) {
+	t.Parallel()
+	reconciler, lavinmq := setupResources(t)
+
+	defer cleanupResources(t, lavinmq)
+
+	lavinmq.Spec.EtcdEndpoints = []string{"http://127.0.0.1:1"}
+	err := k8sClient.Create(t.Context(), lavinmq)
+	assert.NoErrorf(t, err, "Failed to create LavinMQ resource")
+
+	t.Log("Instances created before the prefix was configurable keep the prefix from their config")
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: lavinmq.Name, Namespace: lavinmq.Namespace},
+		Data:       map[string]string{"lavinmq.ini": "[clustering]\netcd_prefix = " + lavinmq.Name + "\n"},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), configMap))
+
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: lavinmq.Name, Namespace: lavinmq.Namespace}}
+	_, err = reconciler.Reconcile(t.Context(), request)
+	assert.NoErrorf(t, err, "Failed to reconcile")
+
+	resource := &cloudamqpcomv1alpha1.LavinMQ{}
+	assert.NoError(t, k8sClient.Get(t.Context(), request.NamespacedName, resource))
+	assert.Equal(t, lavinmq.Name, resource.Status.EtcdPrefix)
+	assert.Equal(t, lavinmq.Name, resource.EtcdPrefix())
+
+	assert.NoError(t, k8sClient.Get(t.Context(), request.NamespacedName, configMap))
+	assert.Contains(t, configMap.Data["lavinmq.ini"], "etcd_prefix = "+lavinmq.Name+"\n")
+}
+
+func TestStatefulSetRolloutCompleteOnDelete(t *testing.T) {
+	t.Parallel()
+	replicas := int32(3)
+	sts := &appsv1.StatefulSet{
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:       &replicas,
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType},
+		},
+		Status: appsv1.StatefulSetStatus{UpdatedReplicas: 2, CurrentRevision: "old", UpdateRevision: "new"},
+	}
+	assert.False(t, statefulSetRolloutComplete(sts))
+
+	// The current revision isn't advanced with OnDelete
+	sts.Status.UpdatedReplicas = 3
+	assert.True(t, statefulSetRolloutComplete(sts))
+}
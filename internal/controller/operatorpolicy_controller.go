@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OperatorPolicyReconciler reconciles an OperatorPolicy object
+type OperatorPolicyReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// ManagementEndpoint replaces the address of the management API, see reconciler.ResourceReconciler.
+	ManagementEndpoint string
+}
+
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=operatorpolicies,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=operatorpolicies/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=operatorpolicies/finalizers,verbs=update
+
+// Reconcile creates the operator policy on the referenced LavinMQ instance, see topology.reconcile.
+func (r *OperatorPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	policy := &cloudamqpcomv1alpha1.OperatorPolicy{}
+	if err := r.Get(ctx, req.NamespacedName, policy); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	t := topology{Client: r.Client, scheme: r.Scheme, recorder: r.Recorder, managementEndpoint: r.ManagementEndpoint}
+	return t.reconcile(ctx, policy, &operatorPolicyEntity{policy: policy})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OperatorPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return setupTopologyController(mgr, &cloudamqpcomv1alpha1.OperatorPolicy{}, &cloudamqpcomv1alpha1.OperatorPolicyList{}, r)
+}
+
+type operatorPolicyEntity struct {
+	policy *cloudamqpcomv1alpha1.OperatorPolicy
+}
+
+// declare creates or replaces the operator policy unless it's set as specified already, and counts the
+// queues it's in effect for.
+func (e *operatorPolicyEntity) declare(ctx context.Context, mc *management.Client) (bool, error) {
+	spec := e.policy.Spec
+	definition, err := decodeArguments(spec.Definition)
+	if err != nil {
+		return false, err
+	}
+	settings := management.PolicySettings{
+		Pattern:    spec.Pattern,
+		ApplyTo:    string(cloudamqpcomv1alpha1.PolicyApplyToQueues),
+		Priority:   int(spec.Priority),
+		Definition: definition,
+	}
+
+	current, err := mc.OperatorPolicy(ctx, spec.Vhost, e.policy.PolicyName())
+	if err != nil && !management.IsNotFound(err) {
+		return false, err
+	}
+	missing := err != nil
+	if missing || !samePolicy(current, settings) {
+		if err := mc.PutOperatorPolicy(ctx, spec.Vhost, e.policy.PolicyName(), settings); err != nil {
+			return missing, err
+		}
+	}
+
+	appliedTo, err := policyAppliedTo(ctx, mc, spec.Vhost, e.policy.PolicyName(), cloudamqpcomv1alpha1.PolicyApplyToQueues, true)
+	if err != nil {
+		return missing, err
+	}
+	e.policy.Status.AppliedTo = appliedTo
+
+	return missing, nil
+}
+
+func (e *operatorPolicyEntity) delete(ctx context.Context, mc *management.Client) error {
+	return mc.DeleteOperatorPolicy(ctx, e.policy.Spec.Vhost, e.policy.PolicyName())
+}
+
+func (e *operatorPolicyEntity) describe() string {
+	return fmt.Sprintf("operator policy %q in vhost %q", e.policy.PolicyName(), e.policy.Spec.Vhost)
+}
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// uriSecretIndex indexes shovels and federation upstreams by the names of the Secrets their URIs are
+// resolved from, the Secret holding the URI or the credentials for connecting to a LavinMQ instance.
+const uriSecretIndex = ".spec.uriSecrets"
+
+// uriObject is a resource connecting to brokers by URI, e.g. a shovel.
+type uriObject interface {
+	topologyObject
+	URISources() []cloudamqpcomv1alpha1.URISource
+}
+
+// setupParameterController sets up the controller of a resource defining a runtime parameter from URIs,
+// reconciling it again when a Secret a URI is resolved from changes.
+func setupParameterController(mgr ctrl.Manager, obj uriObject, list client.ObjectList, r reconcile.Reconciler) error {
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), obj, uriSecretIndex, uriSecrets)
+	if err != nil {
+		return err
+	}
+
+	b, err := topologyController(mgr, obj, list)
+	if err != nil {
+		return err
+	}
+
+	return b.
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(referencing(mgr.GetClient(), list, uriSecretIndex))).
+		Complete(r)
+}
+
+// uriSecrets returns the names of the Secrets the URIs of a resource are resolved from, used as field index.
+func uriSecrets(obj client.Object) []string {
+	names := []string{}
+	for _, source := range obj.(uriObject).URISources() {
+		if name := uriSecretName(source); name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// uriSecretName returns the name of the Secret a URI is resolved from.
+func uriSecretName(source cloudamqpcomv1alpha1.URISource) string {
+	switch {
+	case source.SecretKeyRef != nil:
+		return source.SecretKeyRef.Name
+	case source.LavinMQ != nil:
+		return source.LavinMQ.CredentialsSecretRef.Name
+	}
+
+	return ""
+}
+
+// resolveURI returns the URI a source specifies, read from a Secret or built for the leader of a LavinMQ
+// instance with the credentials of a basic auth Secret. A missing Secret or instance is returned as the not
+// found error of the Kubernetes API.
+func resolveURI(ctx context.Context, c client.Client, namespace string, source cloudamqpcomv1alpha1.URISource) (string, error) {
+	secret := &corev1.Secret{}
+	name := uriSecretName(source)
+	if name == "" {
+		return "", fmt.Errorf("%w: a Secret holding the URI or the credentials is required", errInvalidSpec)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+		return "", err
+	}
+
+	if ref := source.SecretKeyRef; ref != nil {
+		uri := string(secret.Data[ref.Key])
+		if uri == "" {
+			return "", fmt.Errorf("%w: secret %s has no %s", errInvalidSpec, secret.Name, ref.Key)
+		}
+		return uri, nil
+	}
+
+	instance := &cloudamqpcomv1alpha1.LavinMQ{}
+	if err := c.Get(ctx, types.NamespacedName{Name: source.LavinMQ.Name, Namespace: namespace}, instance); err != nil {
+		return "", err
+	}
+	username := string(secret.Data[corev1.BasicAuthUsernameKey])
+	password := string(secret.Data[corev1.BasicAuthPasswordKey])
+	if username == "" || password == "" {
+		return "", fmt.Errorf("%w: secret %s has no %s or %s", errInvalidSpec, secret.Name, corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey)
+	}
+	// The administrator of the operator would end up in a definition anyone reading parameters can see.
+	if username == reconciler.OperatorUsername {
+		return "", fmt.Errorf("%w: user %s is reserved for the operator", errInvalidSpec, reconciler.OperatorUsername)
+	}
+
+	uri, err := reconciler.LeaderAMQPURI(instance, url.UserPassword(username, password), source.LavinMQ.Vhost)
+	if err != nil {
+		return "", fmt.Errorf("%w: LavinMQ %s: %v", errInvalidSpec, instance.Name, err)
+	}
+
+	return uri, nil
+}
+
+// declareParameter creates or replaces a runtime parameter unless its value is as specified already,
+// reporting whether it was missing. Comparing the whole value picks up rotated credentials in the URIs.
+func declareParameter(ctx context.Context, mc *management.Client, component, vhost, name string, value any) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	desired := map[string]any{}
+	if err := json.Unmarshal(data, &desired); err != nil {
+		return false, err
+	}
+
+	current, err := mc.Parameter(ctx, component, vhost, name)
+	if err != nil && !management.IsNotFound(err) {
+		return false, err
+	}
+	missing := err != nil
+	if !missing && argumentsEqual(current.Value, desired) {
+		return false, nil
+	}
+
+	return missing, mc.PutParameter(ctx, component, vhost, name, value)
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PermissionReconciler reconciles a Permission object
+type PermissionReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// ManagementEndpoint replaces the address of the management API, see reconciler.ResourceReconciler.
+	ManagementEndpoint string
+}
+
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=permissions,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=permissions/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=permissions/finalizers,verbs=update
+
+// Reconcile grants the permission on the referenced LavinMQ instance, see topology.reconcile.
+func (r *PermissionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	permission := &cloudamqpcomv1alpha1.Permission{}
+	if err := r.Get(ctx, req.NamespacedName, permission); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	t := topology{Client: r.Client, scheme: r.Scheme, recorder: r.Recorder, managementEndpoint: r.ManagementEndpoint}
+	return t.reconcile(ctx, permission, &permissionEntity{permission: permission})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PermissionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return setupTopologyController(mgr, &cloudamqpcomv1alpha1.Permission{}, &cloudamqpcomv1alpha1.PermissionList{}, r)
+}
+
+type permissionEntity struct {
+	permission *cloudamqpcomv1alpha1.Permission
+}
+
+// declare sets the permissions of the user in the vhost unless they're set as specified already.
+func (e *permissionEntity) declare(ctx context.Context, mc *management.Client) (bool, error) {
+	spec := e.permission.Spec
+	current, err := mc.Permission(ctx, spec.Vhost, spec.User)
+	if err != nil && !management.IsNotFound(err) {
+		return false, err
+	}
+	missing := err != nil
+	if !missing && current.Configure == spec.Configure && current.Write == spec.Write && current.Read == spec.Read {
+		return false, nil
+	}
+
+	return missing, mc.SetPermissions(ctx, spec.Vhost, spec.User, management.PermissionSettings{
+		Configure: spec.Configure,
+		Write:     spec.Write,
+		Read:      spec.Read,
+	})
+}
+
+func (e *permissionEntity) delete(ctx context.Context, mc *management.Client) error {
+	return mc.DeletePermissions(ctx, e.permission.Spec.Vhost, e.permission.Spec.User)
+}
+
+func (e *permissionEntity) describe() string {
+	return fmt.Sprintf("permissions of user %q in vhost %q", e.permission.Spec.User, e.permission.Spec.Vhost)
+}
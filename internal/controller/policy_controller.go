@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PolicyReconciler reconciles a Policy object
+type PolicyReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// ManagementEndpoint replaces the address of the management API, see reconciler.ResourceReconciler.
+	ManagementEndpoint string
+}
+
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=policies,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=policies/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=policies/finalizers,verbs=update
+
+// Reconcile creates the policy on the referenced LavinMQ instance, see topology.reconcile.
+func (r *PolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	policy := &cloudamqpcomv1alpha1.Policy{}
+	if err := r.Get(ctx, req.NamespacedName, policy); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	t := topology{Client: r.Client, scheme: r.Scheme, recorder: r.Recorder, managementEndpoint: r.ManagementEndpoint}
+	return t.reconcile(ctx, policy, &policyEntity{policy: policy})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return setupTopologyController(mgr, &cloudamqpcomv1alpha1.Policy{}, &cloudamqpcomv1alpha1.PolicyList{}, r)
+}
+
+type policyEntity struct {
+	policy *cloudamqpcomv1alpha1.Policy
+}
+
+// declare creates or replaces the policy unless it's set as specified already, and counts the queues and
+// exchanges it's in effect for.
+func (e *policyEntity) declare(ctx context.Context, mc *management.Client) (bool, error) {
+	spec := e.policy.Spec
+	definition, err := decodeArguments(spec.Definition)
+	if err != nil {
+		return false, err
+	}
+	settings := management.PolicySettings{
+		Pattern:    spec.Pattern,
+		ApplyTo:    string(spec.ApplyTo),
+		Priority:   int(spec.Priority),
+		Definition: definition,
+	}
+
+	current, err := mc.Policy(ctx, spec.Vhost, e.policy.PolicyName())
+	if err != nil && !management.IsNotFound(err) {
+		return false, err
+	}
+	missing := err != nil
+	if missing || !samePolicy(current, settings) {
+		if err := mc.PutPolicy(ctx, spec.Vhost, e.policy.PolicyName(), settings); err != nil {
+			return missing, err
+		}
+	}
+
+	appliedTo, err := policyAppliedTo(ctx, mc, spec.Vhost, e.policy.PolicyName(), spec.ApplyTo, false)
+	if err != nil {
+		return missing, err
+	}
+	e.policy.Status.AppliedTo = appliedTo
+
+	return missing, nil
+}
+
+func (e *policyEntity) delete(ctx context.Context, mc *management.Client) error {
+	return mc.DeletePolicy(ctx, e.policy.Spec.Vhost, e.policy.PolicyName())
+}
+
+func (e *policyEntity) describe() string {
+	return fmt.Sprintf("policy %q in vhost %q", e.policy.PolicyName(), e.policy.Spec.Vhost)
+}
+
+// samePolicy compares a policy on the broker with the settings it should have.
+func samePolicy(policy *management.Policy, settings management.PolicySettings) bool {
+	applyTo := settings.ApplyTo
+	if applyTo == "" {
+		applyTo = string(cloudamqpcomv1alpha1.PolicyApplyToAll)
+	}
+
+	return policy.Pattern == settings.Pattern && policy.ApplyTo == applyTo && policy.Priority == settings.Priority &&
+		argumentsEqual(policy.Definition, settings.Definition)
+}
+
+// policyAppliedTo counts the queues and exchanges in a vhost a policy, or operator policy, is in effect for.
+func policyAppliedTo(ctx context.Context, mc *management.Client, vhost, name string, applyTo cloudamqpcomv1alpha1.PolicyApplyTo, operatorPolicy bool) (int32, error) {
+	count := int32(0)
+	if applyTo != cloudamqpcomv1alpha1.PolicyApplyToExchanges {
+		queues, err := mc.Queues(ctx, vhost)
+		if err != nil {
+			return 0, err
+		}
+		for _, queue := range queues {
+			if (operatorPolicy && queue.OperatorPolicy == name) || (!operatorPolicy && queue.Policy == name) {
+				count++
+			}
+		}
+	}
+
+	if applyTo != cloudamqpcomv1alpha1.PolicyApplyToQueues {
+		exchanges, err := mc.Exchanges(ctx, vhost)
+		if err != nil {
+			return 0, err
+		}
+		for _, exchange := range exchanges {
+			if exchange.Policy == name {
+				count++
+			}
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
+)
+
+func TestPolicy(t *testing.T) {
+	t.Parallel()
+	instance, server := setupTopology(t)
+	defer server.Close()
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	r := &PolicyReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), ManagementEndpoint: server.URL}
+	mc := management.NewClient(server.URL, "lavinmq-operator", "secret")
+
+	for _, name := range []string{"orders.created", "orders.shipped", "invoices"} {
+		assert.NoError(t, mc.DeclareQueue(t.Context(), "/", name, management.QueueSettings{Durable: true}))
+	}
+
+	policy := &cloudamqpcomv1alpha1.Policy{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders-ttl", Namespace: instance.Namespace},
+		Spec: cloudamqpcomv1alpha1.PolicySpec{
+			LavinMQRef: corev1.LocalObjectReference{Name: instance.Name},
+			Pattern:    "^orders\\.",
+			ApplyTo:    cloudamqpcomv1alpha1.PolicyApplyToQueues,
+			Definition: &runtime.RawExtension{Raw: []byte(`{"message-ttl":60000}`)},
+		},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), policy))
+
+	t.Log("The status counts the queues the policy is in effect for")
+	_, err := r.Reconcile(t.Context(), reconcileRequest(policy))
+	assert.NoError(t, err)
+	assert.Equal(t, metav1.ConditionTrue, readyCondition(t, policy).Status)
+	assert.Equal(t, int32(2), policy.Status.AppliedTo)
+
+	t.Log("The definition is updated in place")
+	policy.Spec.Definition = &runtime.RawExtension{Raw: []byte(`{"message-ttl":120000}`)}
+	assert.NoError(t, k8sClient.Update(t.Context(), policy))
+	_, err = r.Reconcile(t.Context(), reconcileRequest(policy))
+	assert.NoError(t, err)
+	set, err := mc.Policy(t.Context(), "/", "orders-ttl")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 120000, set.Definition["message-ttl"])
+
+	t.Log("Deleting the resource deletes the policy")
+	assert.NoError(t, k8sClient.Delete(t.Context(), policy))
+	_, err = r.Reconcile(t.Context(), reconcileRequest(policy))
+	assert.NoError(t, err)
+	_, err = mc.Policy(t.Context(), "/", "orders-ttl")
+	assert.True(t, management.IsNotFound(err), "Expected the policy to be deleted, got %v", err)
+}
+
+func TestOperatorPolicy(t *testing.T) {
+	t.Parallel()
+	instance, server := setupTopology(t)
+	defer server.Close()
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	r := &OperatorPolicyReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), ManagementEndpoint: server.URL}
+	mc := management.NewClient(server.URL, "lavinmq-operator", "secret")
+	assert.NoError(t, mc.DeclareQueue(t.Context(), "/", "orders", management.QueueSettings{Durable: true}))
+
+	policy := &cloudamqpcomv1alpha1.OperatorPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "max-length", Namespace: instance.Namespace},
+		Spec: cloudamqpcomv1alpha1.OperatorPolicySpec{
+			LavinMQRef: corev1.LocalObjectReference{Name: instance.Name},
+			Pattern:    ".*",
+			Definition: &runtime.RawExtension{Raw: []byte(`{"max-length":1000}`)},
+		},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), policy))
+
+	_, err := r.Reconcile(t.Context(), reconcileRequest(policy))
+	assert.NoError(t, err)
+	assert.Equal(t, metav1.ConditionTrue, readyCondition(t, policy).Status)
+	assert.Equal(t, int32(1), policy.Status.AppliedTo)
+	set, err := mc.OperatorPolicy(t.Context(), "/", "max-length")
+	assert.NoError(t, err)
+	assert.Equal(t, "queues", set.ApplyTo)
+}
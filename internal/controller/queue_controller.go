@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// QueueReconciler reconciles a Queue object
+type QueueReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// ManagementEndpoint replaces the address of the management API, see reconciler.ResourceReconciler.
+	ManagementEndpoint string
+}
+
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=queues,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=queues/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=queues/finalizers,verbs=update
+
+// Reconcile declares the queue on the referenced LavinMQ instance, see topology.reconcile.
+func (r *QueueReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	queue := &cloudamqpcomv1alpha1.Queue{}
+	if err := r.Get(ctx, req.NamespacedName, queue); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	t := topology{Client: r.Client, scheme: r.Scheme, recorder: r.Recorder, managementEndpoint: r.ManagementEndpoint}
+	return t.reconcile(ctx, queue, &queueEntity{queue: queue})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *QueueReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return setupTopologyController(mgr, &cloudamqpcomv1alpha1.Queue{}, &cloudamqpcomv1alpha1.QueueList{}, r)
+}
+
+type queueEntity struct {
+	queue *cloudamqpcomv1alpha1.Queue
+}
+
+// declare declares the queue, the broker rejects declaring an existing queue with other properties.
+func (e *queueEntity) declare(ctx context.Context, mc *management.Client) (bool, error) {
+	spec := e.queue.Spec
+	arguments, err := decodeArguments(spec.Arguments)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = mc.Queue(ctx, spec.Vhost, e.queue.QueueName())
+	if err != nil && !management.IsNotFound(err) {
+		return false, err
+	}
+	missing := err != nil
+
+	err = mc.DeclareQueue(ctx, spec.Vhost, e.queue.QueueName(), management.QueueSettings{
+		Durable:    e.queue.IsDurable(),
+		AutoDelete: spec.AutoDelete,
+		Arguments:  arguments,
+	})
+
+	return missing, err
+}
+
+func (e *queueEntity) delete(ctx context.Context, mc *management.Client) error {
+	return mc.DeleteQueue(ctx, e.queue.Spec.Vhost, e.queue.QueueName())
+}
+
+func (e *queueEntity) describe() string {
+	return fmt.Sprintf("queue %q in vhost %q", e.queue.QueueName(), e.queue.Spec.Vhost)
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ShovelReconciler reconciles a Shovel object
+type ShovelReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// ManagementEndpoint replaces the address of the management API, see reconciler.ResourceReconciler.
+	ManagementEndpoint string
+}
+
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=shovels,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=shovels/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=shovels/finalizers,verbs=update
+
+// Reconcile defines the shovel on the referenced LavinMQ instance, see topology.reconcile, and mirrors its
+// state. A shovel that isn't running is checked again sooner than declared entities are checked for drift.
+func (r *ShovelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	shovel := &cloudamqpcomv1alpha1.Shovel{}
+	if err := r.Get(ctx, req.NamespacedName, shovel); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	t := topology{Client: r.Client, scheme: r.Scheme, recorder: r.Recorder, managementEndpoint: r.ManagementEndpoint}
+	result, err := t.reconcile(ctx, shovel, &shovelEntity{Client: r.Client, shovel: shovel})
+	if err == nil && shovel.Status.State != cloudamqpcomv1alpha1.ShovelStateRunning && result.RequeueAfter > topologyRetryInterval {
+		result.RequeueAfter = topologyRetryInterval
+	}
+
+	return result, err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ShovelReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return setupParameterController(mgr, &cloudamqpcomv1alpha1.Shovel{}, &cloudamqpcomv1alpha1.ShovelList{}, r)
+}
+
+type shovelEntity struct {
+	client.Client
+	shovel *cloudamqpcomv1alpha1.Shovel
+}
+
+// declare defines the shovel with the URIs resolved from their sources, and reads back its state.
+func (e *shovelEntity) declare(ctx context.Context, mc *management.Client) (bool, error) {
+	spec := e.shovel.Spec
+	srcURI, err := resolveURI(ctx, e.Client, e.shovel.Namespace, spec.Source.URI)
+	if err != nil {
+		return false, fmt.Errorf("source: %w", err)
+	}
+	destURI, err := resolveURI(ctx, e.Client, e.shovel.Namespace, spec.Destination.URI)
+	if err != nil {
+		return false, fmt.Errorf("destination: %w", err)
+	}
+
+	settings := management.ShovelSettings{
+		SrcURI:           srcURI,
+		SrcQueue:         spec.Source.Queue,
+		SrcExchange:      spec.Source.Exchange,
+		SrcExchangeKey:   spec.Source.ExchangeKey,
+		SrcPrefetchCount: int(spec.Source.PrefetchCount),
+		DestURI:          destURI,
+		DestQueue:        spec.Destination.Queue,
+		DestExchange:     spec.Destination.Exchange,
+		DestExchangeKey:  spec.Destination.ExchangeKey,
+		AckMode:          string(spec.AckMode),
+		ReconnectDelay:   int(spec.ReconnectDelay),
+	}
+	created, err := declareParameter(ctx, mc, management.ComponentShovel, spec.Vhost, e.shovel.ShovelName(), settings)
+	if err != nil {
+		return created, err
+	}
+
+	shovels, err := mc.Shovels(ctx, spec.Vhost)
+	if err != nil {
+		return created, err
+	}
+	// Not listed until the broker started it.
+	e.shovel.Status.State, e.shovel.Status.Error = cloudamqpcomv1alpha1.ShovelStateStarting, ""
+	for _, shovel := range shovels {
+		if shovel.Name == e.shovel.ShovelName() {
+			e.shovel.Status.State, e.shovel.Status.Error = shovel.State, shovel.Error
+		}
+	}
+
+	return created, nil
+}
+
+func (e *shovelEntity) delete(ctx context.Context, mc *management.Client) error {
+	return mc.DeleteParameter(ctx, management.ComponentShovel, e.shovel.Spec.Vhost, e.shovel.ShovelName())
+}
+
+func (e *shovelEntity) describe() string {
+	return fmt.Sprintf("shovel %q in vhost %q", e.shovel.ShovelName(), e.shovel.Spec.Vhost)
+}
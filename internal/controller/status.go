@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileOutcome collects what happened while running the resource reconcilers,
+// it's used to derive the conditions of the instance.
+type reconcileOutcome struct {
+	// Reconciler that returned an error, if any.
+	failed reconciler.Reconciler
+	err    error
+	// Reconcilers that asked to be requeued, i.e. still have work in progress.
+	inProgress []reconciler.Reconciler
+}
+
+// updateStatus refreshes the observed state of the instance and patches the status subresource if anything changed.
+func (r *LavinMQReconciler) updateStatus(ctx context.Context, resourceReconciler *reconciler.ResourceReconciler, outcome reconcileOutcome) error {
+	logger := resourceReconciler.Logger
+	instance := resourceReconciler.Instance
+	original := instance.DeepCopy()
+	status := &instance.Status
+
+	status.ObservedGeneration = instance.Generation
+
+	sts := &appsv1.StatefulSet{}
+	err := r.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		sts = nil
+	}
+
+	rolloutComplete := false
+	if sts != nil {
+		status.ReadyReplicas = sts.Status.ReadyReplicas
+		status.UpdatedReplicas = sts.Status.UpdatedReplicas
+		rolloutComplete = statefulSetRolloutComplete(sts)
+		if rolloutComplete && len(sts.Spec.Template.Spec.Containers) > 0 {
+			status.Image = sts.Spec.Template.Spec.Containers[0].Image
+		}
+	} else {
+		status.ReadyReplicas = 0
+		status.UpdatedReplicas = 0
+	}
+
+	if status.ReadyReplicas > 0 {
+		leader, err := resourceReconciler.CurrentLeader(ctx)
+		if err != nil {
+			// Not being able to tell the leader shouldn't block the rest of the status.
+			logger.Error(err, "Failed to determine leader")
+		} else {
+			status.Leader = leader
+		}
+	} else {
+		status.Leader = ""
+	}
+
+	setConditions(instance, sts, rolloutComplete, outcome)
+
+	switch {
+	case outcome.err != nil:
+		status.Phase = cloudamqpcomv1alpha1.LavinMQPhaseDegraded
+	case status.ReadyReplicas == 0:
+		status.Phase = cloudamqpcomv1alpha1.LavinMQPhasePending
+	case meta.IsStatusConditionTrue(status.Conditions, typeProgressingLavinMQ):
+		status.Phase = cloudamqpcomv1alpha1.LavinMQPhaseUpdating
+	default:
+		status.Phase = cloudamqpcomv1alpha1.LavinMQPhaseRunning
+	}
+
+	if equality.Semantic.DeepEqual(original.Status, instance.Status) {
+		return nil
+	}
+
+	return r.Status().Patch(ctx, instance, client.MergeFrom(original))
+}
+
+// recordEtcdPrefix records the etcd prefix in the status before anything is rendered with it, so the instance
+// keeps its prefix and other instances can be validated against it. Instances created before the prefix was
+// configurable adopt the prefix from their ConfigMap.
+func (r *LavinMQReconciler) recordEtcdPrefix(ctx context.Context, resourceReconciler *reconciler.ResourceReconciler) error {
+	instance := resourceReconciler.Instance
+	original := instance.DeepCopy()
+
+	if instance.Status.EtcdPrefix == "" {
+		configured, err := resourceReconciler.ConfiguredEtcdPrefix(ctx)
+		if err != nil {
+			return err
+		}
+		instance.Status.EtcdPrefix = configured
+	}
+
+	instance.Status.EtcdPrefix = instance.EtcdPrefix()
+	if original.Status.EtcdPrefix == instance.Status.EtcdPrefix {
+		return nil
+	}
+
+	resourceReconciler.Logger.Info("Recording etcd prefix", "prefix", instance.Status.EtcdPrefix)
+	return r.Status().Patch(ctx, instance, client.MergeFrom(original))
+}
+
+func setConditions(instance *cloudamqpcomv1alpha1.LavinMQ, sts *appsv1.StatefulSet, rolloutComplete bool, outcome reconcileOutcome) {
+	desired := instance.Spec.Replicas
+	ready := instance.Status.ReadyReplicas
+	generation := instance.Generation
+
+	available := metav1.Condition{
+		Type:               typeAvailableLavinMQ,
+		Status:             metav1.ConditionFalse,
+		Reason:             "NoReplicasReady",
+		Message:            fmt.Sprintf("%d/%d replicas ready", ready, desired),
+		ObservedGeneration: generation,
+	}
+	if ready > 0 {
+		available.Status = metav1.ConditionTrue
+		available.Reason = "ReplicasReady"
+	}
+	meta.SetStatusCondition(&instance.Status.Conditions, available)
+
+	progressing := metav1.Condition{
+		Type:               typeProgressingLavinMQ,
+		Status:             metav1.ConditionFalse,
+		Reason:             "RolloutComplete",
+		Message:            "All replicas are running the desired revision",
+		ObservedGeneration: generation,
+	}
+	switch {
+	case len(outcome.inProgress) > 0:
+		names := make([]string, 0, len(outcome.inProgress))
+		for _, rc := range outcome.inProgress {
+			name := rc.Name()
+			if reporter, ok := rc.(progressReporter); ok && reporter.Progress() != "" {
+				name = fmt.Sprintf("%s (%s)", name, reporter.Progress())
+			}
+			names = append(names, name)
+		}
+		progressing.Status = metav1.ConditionTrue
+		progressing.Reason = conditionReason(outcome.inProgress[0].Name(), "InProgress")
+		progressing.Message = fmt.Sprintf("Waiting on %s", strings.Join(names, ", "))
+	case sts == nil:
+		progressing.Status = metav1.ConditionTrue
+		progressing.Reason = "Creating"
+		progressing.Message = "StatefulSet has not been created yet"
+	case !rolloutComplete:
+		progressing.Status = metav1.ConditionTrue
+		progressing.Reason = "RollingUpdate"
+		progressing.Message = fmt.Sprintf("%d/%d replicas updated", sts.Status.UpdatedReplicas, desired)
+	case ready < desired:
+		progressing.Status = metav1.ConditionTrue
+		progressing.Reason = "WaitingForReplicas"
+		progressing.Message = available.Message
+	}
+	meta.SetStatusCondition(&instance.Status.Conditions, progressing)
+
+	degraded := metav1.Condition{
+		Type:               typeDegradedLavinMQ,
+		Status:             metav1.ConditionFalse,
+		Reason:             "ReconcileSucceeded",
+		Message:            "All resources reconciled",
+		ObservedGeneration: generation,
+	}
+	if outcome.err != nil {
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = conditionReason(outcome.failed.Name(), "ReconcileFailed")
+		degraded.Message = outcome.err.Error()
+	}
+	meta.SetStatusCondition(&instance.Status.Conditions, degraded)
+}
+
+// progressReporter is implemented by reconcilers that can describe the step they're waiting on.
+type progressReporter interface {
+	Progress() string
+}
+
+// statefulSetRolloutComplete reports whether the StatefulSet controller has observed the latest spec
+// and every replica runs the latest revision.
+func statefulSetRolloutComplete(sts *appsv1.StatefulSet) bool {
+	if sts.Status.ObservedGeneration < sts.Generation {
+		return false
+	}
+
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+
+	// The current revision is only advanced by RollingUpdate, with OnDelete the updated replicas tell.
+	if sts.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return sts.Status.UpdatedReplicas == replicas
+	}
+
+	return sts.Status.UpdatedReplicas == replicas && sts.Status.CurrentRevision == sts.Status.UpdateRevision
+}
+
+// conditionReason turns a reconciler name such as "headless-service" into a
+// condition reason such as "HeadlessServiceReconcileFailed".
+func conditionReason(name string, suffix string) string {
+	reason := strings.Builder{}
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' }) {
+		reason.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	reason.WriteString(suffix)
+
+	return reason.String()
+}
@@ -0,0 +1,336 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Reasons of the Ready condition of the resources declaring entities on a LavinMQ instance
+const (
+	reasonDeclared           = "Declared"
+	reasonRedeclared         = "Redeclared"
+	reasonLavinMQNotFound    = "LavinMQNotFound"
+	reasonLavinMQUnavailable = "LavinMQUnavailable"
+	reasonDependencyNotFound = "DependencyNotFound"
+	reasonConflict           = "Conflict"
+	reasonInvalidSpec        = "InvalidSpec"
+	reasonDeclareFailed      = "DeclareFailed"
+	reasonDeleteFailed       = "DeleteFailed"
+)
+
+// topologyFinalizer keeps a deleted resource around until its entity has been deleted on the broker.
+const topologyFinalizer = "lavinmq.cloudamqp.com/topology"
+
+// lavinmqRefIndex indexes the resources declaring entities by the name of the LavinMQ instance they reference
+const lavinmqRefIndex = ".spec.lavinmqRef.name"
+
+const (
+	// topologyResyncInterval is how often declared entities are checked for drift, e.g. a queue deleted by hand.
+	topologyResyncInterval = 5 * time.Minute
+	// topologyRetryInterval is how soon declaring is retried while the instance or a dependency isn't available.
+	topologyRetryInterval = 30 * time.Second
+)
+
+// errInvalidSpec is wrapped by entities that can't be declared as specified, retrying won't help until the
+// resource or something it references changes.
+var errInvalidSpec = errors.New("invalid spec")
+
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// topologyObject is a resource declaring an entity, e.g. a queue, on the LavinMQ instance it references.
+type topologyObject interface {
+	client.Object
+	LavinMQName() string
+	StatusConditions() *[]metav1.Condition
+}
+
+// topologyEntity declares and deletes the entity of a resource through the management API.
+type topologyEntity interface {
+	// declare creates the entity or brings it in line with the spec, reporting whether it was missing.
+	declare(ctx context.Context, mc *management.Client) (created bool, err error)
+	// delete removes the entity from the broker.
+	delete(ctx context.Context, mc *management.Client) error
+	// describe names the entity in conditions and events, e.g. queue "orders" in vhost "/".
+	describe() string
+}
+
+// topology holds what the controllers of the resources declaring entities share.
+type topology struct {
+	client.Client
+	scheme             *runtime.Scheme
+	recorder           record.EventRecorder
+	managementEndpoint string
+}
+
+// reconcile declares the entity on the leader of the referenced instance and reports the outcome in the Ready
+// condition. Declared entities are checked again every topologyResyncInterval, an entity that went missing
+// is declared again and reported as drift. Deleting the resource deletes the entity unless the instance is
+// gone or being deleted itself.
+func (t topology) reconcile(ctx context.Context, obj topologyObject, entity topologyEntity) (ctrl.Result, error) {
+	instance := &cloudamqpcomv1alpha1.LavinMQ{}
+	err := t.Get(ctx, types.NamespacedName{Name: obj.LavinMQName(), Namespace: obj.GetNamespace()}, instance)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		instance = nil
+	}
+
+	if !obj.GetDeletionTimestamp().IsZero() {
+		return t.finalize(ctx, obj, entity, instance)
+	}
+
+	if !controllerutil.ContainsFinalizer(obj, topologyFinalizer) {
+		controllerutil.AddFinalizer(obj, topologyFinalizer)
+		if err := t.Update(ctx, obj); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	original := obj.DeepCopyObject().(client.Object)
+	ready := metav1.Condition{
+		Type:               cloudamqpcomv1alpha1.TopologyConditionReady,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: obj.GetGeneration(),
+	}
+	result, declareErr := t.declare(ctx, obj, entity, instance, &ready)
+
+	meta.SetStatusCondition(obj.StatusConditions(), ready)
+	if err := t.Status().Patch(ctx, obj, client.MergeFrom(original)); err != nil {
+		if apierrors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	return result, declareErr
+}
+
+func (t topology) declare(ctx context.Context, obj topologyObject, entity topologyEntity, instance *cloudamqpcomv1alpha1.LavinMQ, ready *metav1.Condition) (ctrl.Result, error) {
+	if instance == nil {
+		// Declared once the instance is created, see topologyController.
+		ready.Reason = reasonLavinMQNotFound
+		ready.Message = fmt.Sprintf("LavinMQ %s not found", obj.LavinMQName())
+		return ctrl.Result{}, nil
+	}
+
+	mc, err := t.managementClient(ctx, instance)
+	if err != nil {
+		ready.Reason = reasonLavinMQUnavailable
+		ready.Message = err.Error()
+		return ctrl.Result{RequeueAfter: topologyRetryInterval}, nil
+	}
+	defer mc.CloseIdleConnections()
+
+	previous := meta.FindStatusCondition(*obj.StatusConditions(), cloudamqpcomv1alpha1.TopologyConditionReady)
+	wasDeclared := previous != nil && previous.Status == metav1.ConditionTrue && previous.ObservedGeneration == obj.GetGeneration()
+
+	created, err := entity.declare(ctx, mc)
+	switch {
+	case management.IsBadRequest(err):
+		// Retrying won't help until the spec or the entity on the broker changes.
+		ready.Reason = reasonConflict
+		ready.Message = fmt.Sprintf("Failed to declare %s: %v", entity.describe(), err)
+		return ctrl.Result{RequeueAfter: topologyResyncInterval}, nil
+	case errors.Is(err, errInvalidSpec):
+		ready.Reason = reasonInvalidSpec
+		ready.Message = fmt.Sprintf("Failed to declare %s: %v", entity.describe(), err)
+		return ctrl.Result{}, nil
+	case management.IsNotFound(err) || apierrors.IsNotFound(err):
+		// A vhost or user on the broker, or a Secret the entity reads, that may be created later.
+		ready.Reason = reasonDependencyNotFound
+		ready.Message = fmt.Sprintf("Failed to declare %s: %v", entity.describe(), err)
+		return ctrl.Result{RequeueAfter: topologyRetryInterval}, nil
+	case err != nil:
+		ready.Reason = reasonDeclareFailed
+		ready.Message = fmt.Sprintf("Failed to declare %s: %v", entity.describe(), err)
+		return ctrl.Result{}, err
+	}
+
+	ready.Status = metav1.ConditionTrue
+	ready.Reason = reasonDeclared
+	ready.Message = fmt.Sprintf("Declared %s on %s", entity.describe(), instance.Name)
+	if created && wasDeclared {
+		ready.Reason = reasonRedeclared
+		ready.Message = fmt.Sprintf("Declared %s on %s again, it was missing on the broker", entity.describe(), instance.Name)
+		if t.recorder != nil {
+			t.recorder.Eventf(obj, corev1.EventTypeWarning, "Drift", "%s was missing on %s and has been declared again", entity.describe(), instance.Name)
+		}
+	}
+
+	return ctrl.Result{RequeueAfter: topologyResyncInterval}, nil
+}
+
+// finalize deletes the entity of a deleted resource, there is nothing left to delete when the instance is
+// gone or being deleted.
+func (t topology) finalize(ctx context.Context, obj topologyObject, entity topologyEntity, instance *cloudamqpcomv1alpha1.LavinMQ) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(obj, topologyFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if instance != nil && instance.DeletionTimestamp.IsZero() {
+		if err := t.deleteEntity(ctx, entity, instance); err != nil {
+			original := obj.DeepCopyObject().(client.Object)
+			meta.SetStatusCondition(obj.StatusConditions(), metav1.Condition{
+				Type:               cloudamqpcomv1alpha1.TopologyConditionReady,
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: obj.GetGeneration(),
+				Reason:             reasonDeleteFailed,
+				Message:            fmt.Sprintf("Failed to delete %s: %v", entity.describe(), err),
+			})
+			if patchErr := t.Status().Patch(ctx, obj, client.MergeFrom(original)); patchErr != nil {
+				log.FromContext(ctx).Error(patchErr, "Failed to update status")
+			}
+			return ctrl.Result{}, err
+		}
+	}
+
+	controllerutil.RemoveFinalizer(obj, topologyFinalizer)
+	if err := t.Update(ctx, obj); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (t topology) deleteEntity(ctx context.Context, entity topologyEntity, instance *cloudamqpcomv1alpha1.LavinMQ) error {
+	mc, err := t.managementClient(ctx, instance)
+	if err != nil {
+		return err
+	}
+	defer mc.CloseIdleConnections()
+
+	if err := entity.delete(ctx, mc); err != nil && !management.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// managementClient returns a client for the management API of the leader of the instance.
+func (t topology) managementClient(ctx context.Context, instance *cloudamqpcomv1alpha1.LavinMQ) (*management.Client, error) {
+	resourceReconciler := &reconciler.ResourceReconciler{
+		Instance:           instance,
+		Scheme:             t.scheme,
+		Logger:             log.FromContext(ctx),
+		Client:             t.Client,
+		ManagementEndpoint: t.managementEndpoint,
+	}
+
+	return resourceReconciler.LeaderManagementClient(ctx)
+}
+
+// setupTopologyController sets up the controller of a resource declaring entities.
+func setupTopologyController(mgr ctrl.Manager, obj topologyObject, list client.ObjectList, r reconcile.Reconciler) error {
+	b, err := topologyController(mgr, obj, list)
+	if err != nil {
+		return err
+	}
+
+	return b.Complete(r)
+}
+
+// topologyController builds the controller of a resource declaring entities, reconciling it again when the
+// instance it references is created, deleted or gets a new leader.
+func topologyController(mgr ctrl.Manager, obj topologyObject, list client.ObjectList) (*builder.Builder, error) {
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), obj, lavinmqRefIndex, lavinmqRef)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(obj).
+		Watches(&cloudamqpcomv1alpha1.LavinMQ{},
+			handler.EnqueueRequestsFromMapFunc(referencing(mgr.GetClient(), list, lavinmqRefIndex)),
+			builder.WithPredicates(predicate.Funcs{UpdateFunc: lavinmqAvailabilityChanged})), nil
+}
+
+// lavinmqRef returns the name of the instance a resource declares its entity on, used as field index.
+func lavinmqRef(obj client.Object) []string {
+	return []string{obj.(topologyObject).LavinMQName()}
+}
+
+// referencing maps an object, e.g. a LavinMQ instance, to the resources of the listed kind referencing it by
+// name according to the field index.
+func referencing(c client.Client, list client.ObjectList, index string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		items := list.DeepCopyObject().(client.ObjectList)
+		err := c.List(ctx, items, client.InNamespace(obj.GetNamespace()), client.MatchingFields{index: obj.GetName()})
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list referencing resources", "index", index, "name", obj.GetName())
+			return nil
+		}
+
+		requests := []reconcile.Request{}
+		_ = meta.EachListItem(items, func(item runtime.Object) error {
+			o := item.(client.Object)
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: o.GetName(), Namespace: o.GetNamespace()},
+			})
+			return nil
+		})
+
+		return requests
+	}
+}
+
+// lavinmqAvailabilityChanged filters the updates of an instance down to those that may let declaring succeed.
+func lavinmqAvailabilityChanged(e event.UpdateEvent) bool {
+	oldInstance, okOld := e.ObjectOld.(*cloudamqpcomv1alpha1.LavinMQ)
+	newInstance, okNew := e.ObjectNew.(*cloudamqpcomv1alpha1.LavinMQ)
+	if !okOld || !okNew {
+		return false
+	}
+
+	return oldInstance.Status.Leader != newInstance.Status.Leader ||
+		(oldInstance.Status.ReadyReplicas == 0) != (newInstance.Status.ReadyReplicas == 0)
+}
+
+// decodeArguments decodes the arguments of a queue, exchange or binding, or the definition of a policy. Nil
+// if there are none.
+func decodeArguments(raw *runtime.RawExtension) (map[string]any, error) {
+	if raw == nil || len(raw.Raw) == 0 {
+		return nil, nil
+	}
+
+	arguments := map[string]any{}
+	if err := json.Unmarshal(raw.Raw, &arguments); err != nil {
+		return nil, fmt.Errorf("%w: invalid arguments: %v", errInvalidSpec, err)
+	}
+
+	return arguments, nil
+}
+
+// argumentsEqual compares arguments as returned by the management API, no arguments equals empty arguments.
+func argumentsEqual(a, b map[string]any) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
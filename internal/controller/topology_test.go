@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
+	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
+)
+
+// setupTopology creates a LavinMQ instance with operator user credentials, and a fake management API to
+// reach it through.
+func setupTopology(t *testing.T) (*cloudamqpcomv1alpha1.LavinMQ, *testutils.FakeManagement) {
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: reconciler.OperatorUserSecretName(instance), Namespace: instance.Namespace},
+		Type:       corev1.SecretTypeBasicAuth,
+		StringData: map[string]string{corev1.BasicAuthUsernameKey: "lavinmq-operator", corev1.BasicAuthPasswordKey: "secret"},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), secret))
+
+	return instance, testutils.StartFakeManagement("lavinmq-operator", "secret")
+}
+
+func reconcileRequest(obj client.Object) reconcile.Request {
+	return reconcile.Request{NamespacedName: types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}}
+}
+
+func readyCondition(t *testing.T, obj client.Object) *metav1.Condition {
+	assert.NoError(t, k8sClient.Get(t.Context(), client.ObjectKeyFromObject(obj), obj))
+	return meta.FindStatusCondition(*obj.(topologyObject).StatusConditions(), cloudamqpcomv1alpha1.TopologyConditionReady)
+}
+
+func TestQueueLifecycle(t *testing.T) {
+	t.Parallel()
+	instance, server := setupTopology(t)
+	defer server.Close()
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	recorder := record.NewFakeRecorder(10)
+	r := &QueueReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), Recorder: recorder, ManagementEndpoint: server.URL}
+	mc := management.NewClient(server.URL, "lavinmq-operator", "secret")
+
+	queue := &cloudamqpcomv1alpha1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: instance.Namespace},
+		Spec: cloudamqpcomv1alpha1.QueueSpec{
+			LavinMQRef: corev1.LocalObjectReference{Name: instance.Name},
+			Arguments:  &runtime.RawExtension{Raw: []byte(`{"x-max-length":10}`)},
+		},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), queue))
+
+	result, err := r.Reconcile(t.Context(), reconcileRequest(queue))
+	assert.NoError(t, err)
+	assert.Equal(t, topologyResyncInterval, result.RequeueAfter)
+
+	declared, err := mc.Queue(t.Context(), "/", "orders")
+	assert.NoError(t, err)
+	assert.True(t, declared.Durable, "Expected queues to be durable by default")
+	assert.EqualValues(t, 10, declared.Arguments["x-max-length"])
+	ready := readyCondition(t, queue)
+	assert.Equal(t, metav1.ConditionTrue, ready.Status)
+	assert.Equal(t, reasonDeclared, ready.Reason)
+	assert.Contains(t, queue.Finalizers, topologyFinalizer)
+
+	t.Log("A queue deleted by hand is declared again and reported as drift")
+	assert.NoError(t, mc.DeleteQueue(t.Context(), "/", "orders"))
+	_, err = r.Reconcile(t.Context(), reconcileRequest(queue))
+	assert.NoError(t, err)
+	_, err = mc.Queue(t.Context(), "/", "orders")
+	assert.NoError(t, err)
+	assert.Equal(t, reasonRedeclared, readyCondition(t, queue).Reason)
+	assert.Contains(t, <-recorder.Events, "Drift")
+
+	t.Log("Changing the arguments of a declared queue is a conflict")
+	queue.Spec.Arguments = &runtime.RawExtension{Raw: []byte(`{"x-max-length":20}`)}
+	assert.NoError(t, k8sClient.Update(t.Context(), queue))
+	_, err = r.Reconcile(t.Context(), reconcileRequest(queue))
+	assert.NoError(t, err)
+	ready = readyCondition(t, queue)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, reasonConflict, ready.Reason)
+
+	t.Log("The vhost can't be changed")
+	queue.Spec.Vhost = "other"
+	assert.Error(t, k8sClient.Update(t.Context(), queue))
+
+	t.Log("Deleting the resource deletes the queue")
+	assert.NoError(t, k8sClient.Get(t.Context(), client.ObjectKeyFromObject(queue), queue))
+	assert.NoError(t, k8sClient.Delete(t.Context(), queue))
+	_, err = r.Reconcile(t.Context(), reconcileRequest(queue))
+	assert.NoError(t, err)
+	_, err = mc.Queue(t.Context(), "/", "orders")
+	assert.True(t, management.IsNotFound(err), "Expected the queue to be deleted, got %v", err)
+	err = k8sClient.Get(t.Context(), client.ObjectKeyFromObject(queue), queue)
+	assert.True(t, apierrors.IsNotFound(err), "Expected the finalizer to be removed")
+}
+
+func TestQueueWithoutLavinMQ(t *testing.T) {
+	t.Parallel()
+	instance, server := setupTopology(t)
+	defer server.Close()
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	r := &QueueReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), ManagementEndpoint: server.URL}
+
+	queue := &cloudamqpcomv1alpha1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: instance.Namespace},
+		Spec:       cloudamqpcomv1alpha1.QueueSpec{LavinMQRef: corev1.LocalObjectReference{Name: "missing"}},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), queue))
+
+	_, err := r.Reconcile(t.Context(), reconcileRequest(queue))
+	assert.NoError(t, err)
+	ready := readyCondition(t, queue)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, reasonLavinMQNotFound, ready.Reason)
+
+	t.Log("The finalizer is dropped without an instance to delete the queue from")
+	assert.NoError(t, k8sClient.Delete(t.Context(), queue))
+	_, err = r.Reconcile(t.Context(), reconcileRequest(queue))
+	assert.NoError(t, err)
+	err = k8sClient.Get(t.Context(), client.ObjectKeyFromObject(queue), queue)
+	assert.True(t, apierrors.IsNotFound(err), "Expected the finalizer to be removed")
+}
+
+func TestDecodeArgumentsInvalid(t *testing.T) {
+	t.Parallel()
+
+	arguments, err := decodeArguments(&runtime.RawExtension{Raw: []byte(`{"x-max-length":10}`)})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, arguments["x-max-length"])
+
+	_, err = decodeArguments(&runtime.RawExtension{Raw: []byte(`[1]`)})
+	assert.ErrorIs(t, err, errInvalidSpec, "Retrying won't help until the spec changes")
+}
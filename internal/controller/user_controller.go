@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
+	resource_utils "github.com/cloudamqp/lavinmq-operator/internal/reconciler/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// passwordSecretIndex indexes users by the name of the Secret holding their password
+const passwordSecretIndex = ".spec.passwordSecretRef.name"
+
+// UserReconciler reconciles a User object
+type UserReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// ManagementEndpoint replaces the address of the management API, see reconciler.ResourceReconciler.
+	ManagementEndpoint string
+}
+
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=users,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=users/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=users/finalizers,verbs=update
+
+// Reconcile creates the user on the referenced LavinMQ instance, see topology.reconcile.
+func (r *UserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	user := &cloudamqpcomv1alpha1.User{}
+	if err := r.Get(ctx, req.NamespacedName, user); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	t := topology{Client: r.Client, scheme: r.Scheme, recorder: r.Recorder, managementEndpoint: r.ManagementEndpoint}
+	return t.reconcile(ctx, user, &userEntity{Client: r.Client, scheme: r.Scheme, user: user})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *UserReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &cloudamqpcomv1alpha1.User{}, passwordSecretIndex, passwordSecretRef)
+	if err != nil {
+		return err
+	}
+
+	b, err := topologyController(mgr, &cloudamqpcomv1alpha1.User{}, &cloudamqpcomv1alpha1.UserList{})
+	if err != nil {
+		return err
+	}
+
+	return b.
+		// Changing the password in the Secret changes the password of the user.
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(referencing(mgr.GetClient(), &cloudamqpcomv1alpha1.UserList{}, passwordSecretIndex))).
+		Complete(r)
+}
+
+// passwordSecretRef returns the name of the Secret holding the password of a user, used as field index.
+func passwordSecretRef(obj client.Object) []string {
+	return []string{obj.(*cloudamqpcomv1alpha1.User).PasswordSecretName()}
+}
+
+type userEntity struct {
+	client.Client
+	scheme *runtime.Scheme
+	user   *cloudamqpcomv1alpha1.User
+}
+
+// declare creates the user, or updates it when its tags differ or the password Secret changed since the
+// password was last set.
+func (e *userEntity) declare(ctx context.Context, mc *management.Client) (bool, error) {
+	if e.user.Username() == reconciler.OperatorUsername {
+		return false, fmt.Errorf("%w: user %s is reserved for the operator", errInvalidSpec, reconciler.OperatorUsername)
+	}
+
+	secret, err := e.passwordSecret(ctx)
+	if err != nil {
+		return false, err
+	}
+	password := string(secret.Data[corev1.BasicAuthPasswordKey])
+	if password == "" {
+		return false, fmt.Errorf("%w: secret %s has no %s", errInvalidSpec, secret.Name, corev1.BasicAuthPasswordKey)
+	}
+
+	tags := e.tags()
+	current, err := mc.User(ctx, e.user.Username())
+	if err != nil && !management.IsNotFound(err) {
+		return false, err
+	}
+	missing := err != nil
+	if !missing && sameTags(current.Tags, tags) && e.user.Status.PasswordSecretVersion == secret.ResourceVersion {
+		return false, nil
+	}
+
+	if err := mc.PutUser(ctx, e.user.Username(), management.UserSettings{Password: password, Tags: tags}); err != nil {
+		return missing, err
+	}
+	if !missing && e.user.Status.PasswordSecretVersion != secret.ResourceVersion {
+		log.FromContext(ctx).Info("Updated user password from secret", "user", e.user.Username(), "secret", secret.Name)
+	}
+	e.user.Status.PasswordSecretVersion = secret.ResourceVersion
+
+	return missing, nil
+}
+
+// passwordSecret returns the Secret holding the password, generating it with a random password if it doesn't
+// exist. Generated Secrets are owned by the user, user provided ones are left alone on deletion.
+func (e *userEntity) passwordSecret(ctx context.Context) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	err := e.Get(ctx, types.NamespacedName{Name: e.user.PasswordSecretName(), Namespace: e.user.Namespace}, secret)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return secret, err
+	}
+
+	password, err := resource_utils.GeneratePassword()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      e.user.PasswordSecretName(),
+			Namespace: e.user.Namespace,
+		},
+		Type: corev1.SecretTypeBasicAuth,
+		Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte(e.user.Username()),
+			corev1.BasicAuthPasswordKey: []byte(password),
+		},
+	}
+	if err := ctrl.SetControllerReference(e.user, secret, e.scheme); err != nil {
+		return nil, err
+	}
+
+	log.FromContext(ctx).Info("Password secret not found, generating credentials", "name", secret.Name)
+	return secret, e.Create(ctx, secret)
+}
+
+func (e *userEntity) delete(ctx context.Context, mc *management.Client) error {
+	return mc.DeleteUser(ctx, e.user.Username())
+}
+
+func (e *userEntity) describe() string {
+	return fmt.Sprintf("user %q", e.user.Username())
+}
+
+// tags returns the tags of the spec in the comma separated form of the management API.
+func (e *userEntity) tags() string {
+	tags := make([]string, 0, len(e.user.Spec.Tags))
+	for _, tag := range e.user.Spec.Tags {
+		tags = append(tags, string(tag))
+	}
+	slices.Sort(tags)
+
+	return strings.Join(tags, ",")
+}
+
+// sameTags compares comma separated tags regardless of their order.
+func sameTags(a, b string) bool {
+	split := func(tags string) []string {
+		fields := strings.FieldsFunc(tags, func(r rune) bool { return r == ',' || r == ' ' })
+		slices.Sort(fields)
+		return fields
+	}
+
+	return slices.Equal(split(a), split(b))
+}
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
+)
+
+func TestUserGeneratedPassword(t *testing.T) {
+	t.Parallel()
+	instance, server := setupTopology(t)
+	defer server.Close()
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	r := &UserReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), ManagementEndpoint: server.URL}
+	mc := management.NewClient(server.URL, "lavinmq-operator", "secret")
+
+	user := &cloudamqpcomv1alpha1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: instance.Namespace},
+		Spec: cloudamqpcomv1alpha1.UserSpec{
+			LavinMQRef: corev1.LocalObjectReference{Name: instance.Name},
+			Tags:       []cloudamqpcomv1alpha1.UserTag{cloudamqpcomv1alpha1.UserTagMonitoring, cloudamqpcomv1alpha1.UserTagManagement},
+		},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), user))
+
+	_, err := r.Reconcile(t.Context(), reconcileRequest(user))
+	assert.NoError(t, err)
+	assert.Equal(t, metav1.ConditionTrue, readyCondition(t, user).Status)
+
+	secret := &corev1.Secret{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: "app-credentials", Namespace: instance.Namespace}, secret))
+	assert.Equal(t, "app", string(secret.Data[corev1.BasicAuthUsernameKey]))
+	assert.Equal(t, string(secret.Data[corev1.BasicAuthPasswordKey]), server.Password("app"))
+	assert.Equal(t, secret.ResourceVersion, user.Status.PasswordSecretVersion)
+	assert.Len(t, secret.OwnerReferences, 1, "Expected the generated secret to be owned by the user")
+
+	created, err := mc.User(t.Context(), "app")
+	assert.NoError(t, err)
+	assert.True(t, sameTags("monitoring,management", created.Tags), "Unexpected tags %q", created.Tags)
+
+	t.Log("Changing the password in the secret changes the password of the user")
+	secret.Data[corev1.BasicAuthPasswordKey] = []byte("rotated")
+	assert.NoError(t, k8sClient.Update(t.Context(), secret))
+	_, err = r.Reconcile(t.Context(), reconcileRequest(user))
+	assert.NoError(t, err)
+	assert.Equal(t, "rotated", server.Password("app"))
+
+	t.Log("Tags are updated in place")
+	assert.NoError(t, k8sClient.Get(t.Context(), reconcileRequest(user).NamespacedName, user))
+	user.Spec.Tags = []cloudamqpcomv1alpha1.UserTag{cloudamqpcomv1alpha1.UserTagAdministrator}
+	assert.NoError(t, k8sClient.Update(t.Context(), user))
+	_, err = r.Reconcile(t.Context(), reconcileRequest(user))
+	assert.NoError(t, err)
+	updated, err := mc.User(t.Context(), "app")
+	assert.NoError(t, err)
+	assert.Equal(t, "administrator", updated.Tags)
+
+	t.Log("Deleting the resource deletes the user")
+	assert.NoError(t, k8sClient.Delete(t.Context(), user))
+	_, err = r.Reconcile(t.Context(), reconcileRequest(user))
+	assert.NoError(t, err)
+	_, err = mc.User(t.Context(), "app")
+	assert.True(t, management.IsNotFound(err), "Expected the user to be deleted, got %v", err)
+}
+
+func TestUserPasswordSecretRef(t *testing.T) {
+	t.Parallel()
+	instance, server := setupTopology(t)
+	defer server.Close()
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	r := &UserReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), ManagementEndpoint: server.URL}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-password", Namespace: instance.Namespace},
+		StringData: map[string]string{"other": "value"},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), secret))
+
+	user := &cloudamqpcomv1alpha1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: instance.Namespace},
+		Spec: cloudamqpcomv1alpha1.UserSpec{
+			LavinMQRef:        corev1.LocalObjectReference{Name: instance.Name},
+			PasswordSecretRef: &corev1.LocalObjectReference{Name: secret.Name},
+		},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), user))
+
+	t.Log("A referenced secret without a password isn't filled in")
+	_, err := r.Reconcile(t.Context(), reconcileRequest(user))
+	assert.NoError(t, err)
+	assert.Equal(t, reasonInvalidSpec, readyCondition(t, user).Reason)
+
+	secret.StringData = map[string]string{corev1.BasicAuthPasswordKey: "provided"}
+	assert.NoError(t, k8sClient.Update(t.Context(), secret))
+	_, err = r.Reconcile(t.Context(), reconcileRequest(user))
+	assert.NoError(t, err)
+	assert.Equal(t, metav1.ConditionTrue, readyCondition(t, user).Status)
+	assert.Equal(t, "provided", server.Password("app"))
+}
+
+func TestPermission(t *testing.T) {
+	t.Parallel()
+	instance, server := setupTopology(t)
+	defer server.Close()
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	r := &PermissionReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), ManagementEndpoint: server.URL}
+	mc := management.NewClient(server.URL, "lavinmq-operator", "secret")
+
+	permission := &cloudamqpcomv1alpha1.Permission{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: instance.Namespace},
+		Spec: cloudamqpcomv1alpha1.PermissionSpec{
+			LavinMQRef: corev1.LocalObjectReference{Name: instance.Name},
+			User:       "app",
+			Configure:  "^app\\.",
+			Write:      ".*",
+			Read:       ".*",
+		},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), permission))
+
+	t.Log("The permission waits for its user")
+	_, err := r.Reconcile(t.Context(), reconcileRequest(permission))
+	assert.NoError(t, err)
+	assert.Equal(t, reasonDependencyNotFound, readyCondition(t, permission).Reason)
+
+	assert.NoError(t, mc.PutUser(t.Context(), "app", management.UserSettings{Password: "pw"}))
+	_, err = r.Reconcile(t.Context(), reconcileRequest(permission))
+	assert.NoError(t, err)
+	assert.Equal(t, metav1.ConditionTrue, readyCondition(t, permission).Status)
+
+	t.Log("The regular expressions are updated in place")
+	permission.Spec.Write = "^app$"
+	assert.NoError(t, k8sClient.Update(t.Context(), permission))
+	_, err = r.Reconcile(t.Context(), reconcileRequest(permission))
+	assert.NoError(t, err)
+	granted, err := mc.Permission(t.Context(), "/", "app")
+	assert.NoError(t, err)
+	assert.Equal(t, "^app$", granted.Write)
+	assert.Equal(t, "^app\\.", granted.Configure)
+
+	t.Log("Deleting the resource revokes the permission")
+	assert.NoError(t, k8sClient.Delete(t.Context(), permission))
+	_, err = r.Reconcile(t.Context(), reconcileRequest(permission))
+	assert.NoError(t, err)
+	_, err = mc.Permission(t.Context(), "/", "app")
+	assert.True(t, management.IsNotFound(err), "Expected the permission to be deleted, got %v", err)
+}
@@ -4,16 +4,74 @@ import (
 	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
 )
 
-func LabelsForLavinMQ(instance *cloudamqpcomv1alpha1.LavinMQ) map[string]string {
-	labels := map[string]string{
-		"app.kubernetes.io/name":       "lavinmq-operator",
-		"app.kubernetes.io/managed-by": "LavinMQController",
+const (
+	InstanceLabel  = "app.kubernetes.io/instance"
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	ManagedByValue = "LavinMQController"
+
+	// RoleLabel is maintained by the operator on every LavinMQ pod to tell the current leader from its followers.
+	RoleLabel    = "lavinmq.cloudamqp.com/role"
+	RoleLeader   = "leader"
+	RoleFollower = "follower"
+)
+
+// SelectorLabelsForLavinMQ returns the labels identifying the pods of a single LavinMQ instance.
+// They are used as immutable selectors and must never include user supplied labels.
+func SelectorLabelsForLavinMQ(instance *cloudamqpcomv1alpha1.LavinMQ) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name": "lavinmq-operator",
+		InstanceLabel:            instance.Name,
+		ManagedByLabel:           ManagedByValue,
 	}
+}
+
+// LabelsForLavinMQ returns the labels set on all resources owned by the instance,
+// the labels of the instance itself propagated together with the selector labels.
+func LabelsForLavinMQ(instance *cloudamqpcomv1alpha1.LavinMQ) map[string]string {
+	labels := map[string]string{}
 
 	// Append instance labels
 	for k, v := range instance.Labels {
 		labels[k] = v
 	}
 
+	// Selector labels take precedence so a user label can't make the selector miss its own pods
+	for k, v := range SelectorLabelsForLavinMQ(instance) {
+		labels[k] = v
+	}
+
+	return labels
+}
+
+// LeaderSelectorLabelsForLavinMQ returns the labels selecting the pod currently acting as leader of the instance.
+func LeaderSelectorLabelsForLavinMQ(instance *cloudamqpcomv1alpha1.LavinMQ) map[string]string {
+	labels := SelectorLabelsForLavinMQ(instance)
+	labels[RoleLabel] = RoleLeader
+
+	return labels
+}
+
+// EtcdSelectorLabelsForLavinMQ returns the labels identifying the pods of the managed etcd cluster of an instance.
+// They differ from the LavinMQ selector labels so neither selects the pods of the other.
+func EtcdSelectorLabelsForLavinMQ(instance *cloudamqpcomv1alpha1.LavinMQ) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name": "etcd",
+		InstanceLabel:            instance.Name,
+		ManagedByLabel:           ManagedByValue,
+	}
+}
+
+// EtcdLabelsForLavinMQ returns the labels set on the resources of the managed etcd cluster.
+func EtcdLabelsForLavinMQ(instance *cloudamqpcomv1alpha1.LavinMQ) map[string]string {
+	labels := map[string]string{}
+
+	for k, v := range instance.Labels {
+		labels[k] = v
+	}
+
+	for k, v := range EtcdSelectorLabelsForLavinMQ(instance) {
+		labels[k] = v
+	}
+
 	return labels
 }
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// VhostReconciler reconciles a Vhost object
+type VhostReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// ManagementEndpoint replaces the address of the management API, see reconciler.ResourceReconciler.
+	ManagementEndpoint string
+}
+
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=vhosts,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=vhosts/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cloudamqp.com,resources=vhosts/finalizers,verbs=update
+
+// Reconcile creates the vhost on the referenced LavinMQ instance, see topology.reconcile.
+func (r *VhostReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	vhost := &cloudamqpcomv1alpha1.Vhost{}
+	if err := r.Get(ctx, req.NamespacedName, vhost); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	t := topology{Client: r.Client, scheme: r.Scheme, recorder: r.Recorder, managementEndpoint: r.ManagementEndpoint}
+	return t.reconcile(ctx, vhost, &vhostEntity{vhost: vhost})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *VhostReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return setupTopologyController(mgr, &cloudamqpcomv1alpha1.Vhost{}, &cloudamqpcomv1alpha1.VhostList{}, r)
+}
+
+type vhostEntity struct {
+	vhost *cloudamqpcomv1alpha1.Vhost
+}
+
+func (e *vhostEntity) declare(ctx context.Context, mc *management.Client) (bool, error) {
+	_, err := mc.Vhost(ctx, e.vhost.VhostName())
+	if err == nil || !management.IsNotFound(err) {
+		return false, err
+	}
+
+	return true, mc.PutVhost(ctx, e.vhost.VhostName())
+}
+
+// delete only deletes the vhost, and everything in it, with the Delete deletion policy.
+func (e *vhostEntity) delete(ctx context.Context, mc *management.Client) error {
+	if e.vhost.DeletionPolicy() != cloudamqpcomv1alpha1.VhostDeletionPolicyDelete {
+		log.FromContext(ctx).Info("Retaining vhost", "vhost", e.vhost.VhostName())
+		return nil
+	}
+
+	return mc.DeleteVhost(ctx, e.vhost.VhostName())
+}
+
+func (e *vhostEntity) describe() string {
+	return fmt.Sprintf("vhost %q", e.vhost.VhostName())
+}
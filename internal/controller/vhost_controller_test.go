@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
+)
+
+func TestVhostDeletionPolicy(t *testing.T) {
+	t.Parallel()
+	instance, server := setupTopology(t)
+	defer server.Close()
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	r := &VhostReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), ManagementEndpoint: server.URL}
+	mc := management.NewClient(server.URL, "lavinmq-operator", "secret")
+
+	retained := &cloudamqpcomv1alpha1.Vhost{
+		ObjectMeta: metav1.ObjectMeta{Name: "retained", Namespace: instance.Namespace},
+		Spec:       cloudamqpcomv1alpha1.VhostSpec{LavinMQRef: corev1.LocalObjectReference{Name: instance.Name}},
+	}
+	deleted := &cloudamqpcomv1alpha1.Vhost{
+		ObjectMeta: metav1.ObjectMeta{Name: "deleted", Namespace: instance.Namespace},
+		Spec: cloudamqpcomv1alpha1.VhostSpec{
+			LavinMQRef:     corev1.LocalObjectReference{Name: instance.Name},
+			DeletionPolicy: cloudamqpcomv1alpha1.VhostDeletionPolicyDelete,
+		},
+	}
+
+	for _, vhost := range []*cloudamqpcomv1alpha1.Vhost{retained, deleted} {
+		assert.NoError(t, k8sClient.Create(t.Context(), vhost))
+		_, err := r.Reconcile(t.Context(), reconcileRequest(vhost))
+		assert.NoError(t, err)
+		assert.Equal(t, metav1.ConditionTrue, readyCondition(t, vhost).Status)
+		_, err = mc.Vhost(t.Context(), vhost.Name)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, cloudamqpcomv1alpha1.VhostDeletionPolicyRetain, retained.DeletionPolicy())
+
+	t.Log("The vhost is kept by default")
+	assert.NoError(t, k8sClient.Delete(t.Context(), retained))
+	_, err := r.Reconcile(t.Context(), reconcileRequest(retained))
+	assert.NoError(t, err)
+	_, err = mc.Vhost(t.Context(), retained.Name)
+	assert.NoError(t, err)
+
+	t.Log("The vhost is deleted with the Delete deletion policy")
+	assert.NoError(t, k8sClient.Delete(t.Context(), deleted))
+	_, err = r.Reconcile(t.Context(), reconcileRequest(deleted))
+	assert.NoError(t, err)
+	_, err = mc.Vhost(t.Context(), deleted.Name)
+	assert.True(t, management.IsNotFound(err), "Expected the vhost to be deleted, got %v", err)
+}
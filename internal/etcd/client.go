@@ -0,0 +1,240 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client is a minimal etcd v3 client speaking the JSON gRPC gateway, the same
+// API LavinMQ itself uses for clustering. It only covers what the operator
+// needs to observe and clean up LavinMQ cluster state.
+type Client struct {
+	Endpoints  []string
+	HTTPClient *http.Client
+	// Username and Password authenticate each request when set, for etcd clusters with auth enabled.
+	Username string
+	Password string
+}
+
+// KeyValue is a single key as returned by the etcd range API.
+type KeyValue struct {
+	Key            string
+	Value          string
+	CreateRevision int64
+	ModRevision    int64
+}
+
+type authenticateRequest struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+type authenticateResponse struct {
+	Token string `json:"token"`
+}
+
+type rangeRequest struct {
+	Key      string `json:"key"`
+	RangeEnd string `json:"range_end,omitempty"`
+}
+
+type rangeResponse struct {
+	Kvs []struct {
+		Key            string `json:"key"`
+		Value          string `json:"value"`
+		CreateRevision int64  `json:"create_revision,string"`
+		ModRevision    int64  `json:"mod_revision,string"`
+	} `json:"kvs"`
+}
+
+type deleteRangeResponse struct {
+	Deleted int64 `json:"deleted,string"`
+}
+
+func NewClient(endpoints []string) *Client {
+	return &Client{
+		Endpoints:  endpoints,
+		HTTPClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// CloseIdleConnections closes the connections kept alive by the transport of the client.
+func (c *Client) CloseIdleConnections() {
+	c.HTTPClient.CloseIdleConnections()
+}
+
+// Get returns the key, or nil if it does not exist.
+func (c *Client) Get(ctx context.Context, key string) (*KeyValue, error) {
+	kvs, err := c.rangeKeys(ctx, rangeRequest{Key: encode(key)})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(kvs) == 0 {
+		return nil, nil
+	}
+
+	return &kvs[0], nil
+}
+
+// GetPrefix returns all keys starting with prefix.
+func (c *Client) GetPrefix(ctx context.Context, prefix string) ([]KeyValue, error) {
+	return c.rangeKeys(ctx, rangeRequest{Key: encode(prefix), RangeEnd: encode(prefixRangeEnd(prefix))})
+}
+
+// DeletePrefix deletes all keys starting with prefix and returns the number of keys deleted.
+func (c *Client) DeletePrefix(ctx context.Context, prefix string) (int64, error) {
+	resp := &deleteRangeResponse{}
+	req := rangeRequest{Key: encode(prefix), RangeEnd: encode(prefixRangeEnd(prefix))}
+	if err := c.post(ctx, "/v3/kv/deleterange", req, resp); err != nil {
+		return 0, err
+	}
+
+	return resp.Deleted, nil
+}
+
+func (c *Client) rangeKeys(ctx context.Context, req rangeRequest) ([]KeyValue, error) {
+	resp := &rangeResponse{}
+	if err := c.post(ctx, "/v3/kv/range", req, resp); err != nil {
+		return nil, err
+	}
+
+	kvs := make([]KeyValue, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		key, err := decode(kv.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := decode(kv.Value)
+		if err != nil {
+			return nil, err
+		}
+		kvs = append(kvs, KeyValue{
+			Key:            key,
+			Value:          value,
+			CreateRevision: kv.CreateRevision,
+			ModRevision:    kv.ModRevision,
+		})
+	}
+
+	return kvs, nil
+}
+
+// post sends the request to each endpoint in turn until one of them answers.
+func (c *Client) post(ctx context.Context, path string, body any, out any) error {
+	if len(c.Endpoints) == 0 {
+		return errors.New("no etcd endpoints configured")
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, endpoint := range c.Endpoints {
+		token, err := c.authenticate(ctx, endpoint)
+		if err == nil {
+			err = c.postTo(ctx, endpointURL(endpoint)+path, token, payload, out)
+		}
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// authenticate returns a token for the endpoint, or an empty token if no credentials are configured.
+// Tokens are short lived and requests are rare, so a new one is requested every time.
+func (c *Client) authenticate(ctx context.Context, endpoint string) (string, error) {
+	if c.Username == "" {
+		return "", nil
+	}
+
+	payload, err := json.Marshal(authenticateRequest{Name: c.Username, Password: c.Password})
+	if err != nil {
+		return "", err
+	}
+
+	resp := &authenticateResponse{}
+	if err := c.postTo(ctx, endpointURL(endpoint)+"/v3/auth/authenticate", "", payload, resp); err != nil {
+		return "", fmt.Errorf("etcd authentication failed: %w", err)
+	}
+
+	return resp.Token, nil
+}
+
+func (c *Client) postTo(ctx context.Context, url string, token string, payload []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("etcd %s returned %d: %s", url, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+// endpointURL accepts endpoints both with and without scheme, as LavinMQ does.
+func endpointURL(endpoint string) string {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	if strings.Contains(endpoint, "://") {
+		return endpoint
+	}
+
+	return "http://" + endpoint
+}
+
+// prefixRangeEnd returns the smallest key greater than all keys with the given prefix.
+func prefixRangeEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+
+	// The prefix is all 0xff bytes, range to the end of the keyspace.
+	return "\x00"
+}
+
+func encode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func decode(s string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode etcd key/value: %w", err)
+	}
+
+	return string(b), nil
+}
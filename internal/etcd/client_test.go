@@ -0,0 +1,102 @@
+package etcd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudamqp/lavinmq-operator/internal/etcd"
+	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+	server := testutils.StartFakeEtcd(map[string]string{"broker/leader": "tcp://broker-1.broker.default.svc.cluster.local:5679"})
+	defer server.Close()
+
+	client := etcd.NewClient([]string{server.URL})
+
+	kv, err := client.Get(t.Context(), "broker/leader")
+	assert.NoError(t, err)
+	assert.Equal(t, "tcp://broker-1.broker.default.svc.cluster.local:5679", kv.Value)
+
+	kv, err = client.Get(t.Context(), "broker/missing")
+	assert.NoError(t, err)
+	assert.Nil(t, kv)
+}
+
+func TestGetPrefix(t *testing.T) {
+	t.Parallel()
+	server := testutils.StartFakeEtcd(map[string]string{
+		"broker/leader": "a",
+		"broker/isr":    "b",
+		"brokers/isr":   "c",
+	})
+	defer server.Close()
+
+	client := etcd.NewClient([]string{server.URL})
+
+	kvs, err := client.GetPrefix(t.Context(), "broker/")
+	assert.NoError(t, err)
+	assert.Len(t, kvs, 2)
+}
+
+func TestFallbackEndpoint(t *testing.T) {
+	t.Parallel()
+	server := testutils.StartFakeEtcd(map[string]string{"broker/leader": "a"})
+	defer server.Close()
+
+	// Endpoints without scheme are accepted, and unreachable endpoints are skipped.
+	client := etcd.NewClient([]string{"127.0.0.1:1", strings.TrimPrefix(server.URL, "http://")})
+
+	kv, err := client.Get(t.Context(), "broker/leader")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", kv.Value)
+}
+
+func TestNoEndpoints(t *testing.T) {
+	t.Parallel()
+	client := etcd.NewClient(nil)
+
+	_, err := client.Get(t.Context(), "broker/leader")
+	assert.Error(t, err)
+}
+
+func TestAuthentication(t *testing.T) {
+	t.Parallel()
+	server := testutils.StartFakeEtcd(map[string]string{"broker/leader": "a"})
+	defer server.Close()
+	server.RequireAuth("lavinmq", "secret")
+
+	client := etcd.NewClient([]string{server.URL})
+	_, err := client.Get(t.Context(), "broker/leader")
+	assert.Error(t, err)
+
+	client.Username = "lavinmq"
+	client.Password = "wrong"
+	_, err = client.Get(t.Context(), "broker/leader")
+	assert.Error(t, err)
+
+	client.Password = "secret"
+	kv, err := client.Get(t.Context(), "broker/leader")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", kv.Value)
+}
+
+func TestDeletePrefix(t *testing.T) {
+	t.Parallel()
+	server := testutils.StartFakeEtcd(map[string]string{
+		"broker/leader": "a",
+		"broker/isr":    "b",
+		"brokers/isr":   "c",
+	})
+	defer server.Close()
+
+	client := etcd.NewClient([]string{server.URL})
+
+	deleted, err := client.DeletePrefix(t.Context(), "broker/")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	assert.Equal(t, []string{"brokers/isr"}, server.Keys())
+}
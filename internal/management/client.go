@@ -0,0 +1,417 @@
+package management
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client is a minimal client for the LavinMQ management HTTP API. It covers what the operator needs to
+// observe the nodes and to manage the entities declared through custom resources.
+type Client struct {
+	// BaseURL of the management API, e.g. http://broker-0.broker.default.svc.cluster.local:15672
+	BaseURL    string
+	HTTPClient *http.Client
+	// Username and Password authenticate each request with basic auth.
+	Username string
+	Password string
+}
+
+// Error is returned for requests the management API answered with an error status.
+type Error struct {
+	StatusCode int
+	Reason     string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("management API returned %d: %s", e.StatusCode, e.Reason)
+}
+
+// IsNotFound reports whether the error tells that the requested entity doesn't exist.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsBadRequest reports whether the request was rejected, e.g. redeclaring a queue with other arguments.
+func IsBadRequest(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest
+}
+
+type errorResponse struct {
+	Error  string `json:"error"`
+	Reason string `json:"reason"`
+}
+
+func NewClient(baseURL, username, password string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		Username:   username,
+		Password:   password,
+	}
+}
+
+// CloseIdleConnections closes the connections kept alive by the transport of the client.
+func (c *Client) CloseIdleConnections() {
+	c.HTTPClient.CloseIdleConnections()
+}
+
+// Overview returns the cluster wide overview.
+func (c *Client) Overview(ctx context.Context) (*Overview, error) {
+	overview := &Overview{}
+	if err := c.do(ctx, http.MethodGet, "/api/overview", nil, overview); err != nil {
+		return nil, err
+	}
+
+	return overview, nil
+}
+
+// Nodes returns the nodes of the cluster.
+func (c *Client) Nodes(ctx context.Context) ([]Node, error) {
+	nodes := []Node{}
+	if err := c.do(ctx, http.MethodGet, "/api/nodes", nil, &nodes); err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+// Queues returns the queues of a vhost, or of all vhosts if vhost is empty.
+func (c *Client) Queues(ctx context.Context, vhost string) ([]Queue, error) {
+	queues := []Queue{}
+	if err := c.do(ctx, http.MethodGet, path("queues", vhost), nil, &queues); err != nil {
+		return nil, err
+	}
+
+	return queues, nil
+}
+
+// Queue returns a single queue.
+func (c *Client) Queue(ctx context.Context, vhost, name string) (*Queue, error) {
+	queue := &Queue{}
+	if err := c.do(ctx, http.MethodGet, path("queues", vhost, name), nil, queue); err != nil {
+		return nil, err
+	}
+
+	return queue, nil
+}
+
+// DeclareQueue declares a queue, redeclaring an existing queue with other settings is rejected.
+func (c *Client) DeclareQueue(ctx context.Context, vhost, name string, settings QueueSettings) error {
+	return c.do(ctx, http.MethodPut, path("queues", vhost, name), settings, nil)
+}
+
+// DeleteQueue deletes a queue and the messages in it.
+func (c *Client) DeleteQueue(ctx context.Context, vhost, name string) error {
+	return c.do(ctx, http.MethodDelete, path("queues", vhost, name), nil, nil)
+}
+
+// Exchanges returns the exchanges of a vhost, or of all vhosts if vhost is empty.
+func (c *Client) Exchanges(ctx context.Context, vhost string) ([]Exchange, error) {
+	exchanges := []Exchange{}
+	if err := c.do(ctx, http.MethodGet, path("exchanges", vhost), nil, &exchanges); err != nil {
+		return nil, err
+	}
+
+	return exchanges, nil
+}
+
+// Exchange returns a single exchange.
+func (c *Client) Exchange(ctx context.Context, vhost, name string) (*Exchange, error) {
+	exchange := &Exchange{}
+	if err := c.do(ctx, http.MethodGet, path("exchanges", vhost, name), nil, exchange); err != nil {
+		return nil, err
+	}
+
+	return exchange, nil
+}
+
+// DeclareExchange declares an exchange, redeclaring an existing exchange with other settings is rejected.
+func (c *Client) DeclareExchange(ctx context.Context, vhost, name string, settings ExchangeSettings) error {
+	return c.do(ctx, http.MethodPut, path("exchanges", vhost, name), settings, nil)
+}
+
+// DeleteExchange deletes an exchange and its bindings.
+func (c *Client) DeleteExchange(ctx context.Context, vhost, name string) error {
+	return c.do(ctx, http.MethodDelete, path("exchanges", vhost, name), nil, nil)
+}
+
+// Bindings returns the bindings from an exchange to a queue or another exchange.
+func (c *Client) Bindings(ctx context.Context, vhost, source string, destinationType DestinationType, destination string) ([]Binding, error) {
+	bindings := []Binding{}
+	err := c.do(ctx, http.MethodGet, path("bindings", vhost, "e", source, destinationType.segment(), destination), nil, &bindings)
+	if err != nil {
+		return nil, err
+	}
+
+	return bindings, nil
+}
+
+// Bind binds a queue or another exchange to an exchange.
+func (c *Client) Bind(ctx context.Context, vhost, source string, destinationType DestinationType, destination string, settings BindingSettings) error {
+	return c.do(ctx, http.MethodPost, path("bindings", vhost, "e", source, destinationType.segment(), destination), settings, nil)
+}
+
+// Unbind deletes a binding, identified by its properties key.
+func (c *Client) Unbind(ctx context.Context, vhost, source string, destinationType DestinationType, destination, propertiesKey string) error {
+	return c.do(ctx, http.MethodDelete, path("bindings", vhost, "e", source, destinationType.segment(), destination, propertiesKey), nil, nil)
+}
+
+// Users returns all users.
+func (c *Client) Users(ctx context.Context) ([]User, error) {
+	users := []User{}
+	if err := c.do(ctx, http.MethodGet, "/api/users", nil, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// User returns a single user.
+func (c *Client) User(ctx context.Context, name string) (*User, error) {
+	user := &User{}
+	if err := c.do(ctx, http.MethodGet, path("users", name), nil, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// PutUser creates a user or updates its password and tags.
+func (c *Client) PutUser(ctx context.Context, name string, settings UserSettings) error {
+	return c.do(ctx, http.MethodPut, path("users", name), settings, nil)
+}
+
+// DeleteUser deletes a user and its permissions.
+func (c *Client) DeleteUser(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, path("users", name), nil, nil)
+}
+
+// Permissions returns the permissions of all users in all vhosts.
+func (c *Client) Permissions(ctx context.Context) ([]Permission, error) {
+	permissions := []Permission{}
+	if err := c.do(ctx, http.MethodGet, "/api/permissions", nil, &permissions); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// Permission returns the permissions of a user in a vhost.
+func (c *Client) Permission(ctx context.Context, vhost, user string) (*Permission, error) {
+	permission := &Permission{}
+	if err := c.do(ctx, http.MethodGet, path("permissions", vhost, user), nil, permission); err != nil {
+		return nil, err
+	}
+
+	return permission, nil
+}
+
+// SetPermissions grants a user access to a vhost.
+func (c *Client) SetPermissions(ctx context.Context, vhost, user string, settings PermissionSettings) error {
+	return c.do(ctx, http.MethodPut, path("permissions", vhost, user), settings, nil)
+}
+
+// DeletePermissions revokes the access of a user to a vhost.
+func (c *Client) DeletePermissions(ctx context.Context, vhost, user string) error {
+	return c.do(ctx, http.MethodDelete, path("permissions", vhost, user), nil, nil)
+}
+
+// Vhosts returns all vhosts.
+func (c *Client) Vhosts(ctx context.Context) ([]Vhost, error) {
+	vhosts := []Vhost{}
+	if err := c.do(ctx, http.MethodGet, "/api/vhosts", nil, &vhosts); err != nil {
+		return nil, err
+	}
+
+	return vhosts, nil
+}
+
+// Vhost returns a single vhost.
+func (c *Client) Vhost(ctx context.Context, name string) (*Vhost, error) {
+	vhost := &Vhost{}
+	if err := c.do(ctx, http.MethodGet, path("vhosts", name), nil, vhost); err != nil {
+		return nil, err
+	}
+
+	return vhost, nil
+}
+
+// PutVhost creates a vhost, an existing vhost is left as is.
+func (c *Client) PutVhost(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodPut, path("vhosts", name), nil, nil)
+}
+
+// DeleteVhost deletes a vhost and everything in it.
+func (c *Client) DeleteVhost(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, path("vhosts", name), nil, nil)
+}
+
+// Policies returns the policies of a vhost, or of all vhosts if vhost is empty.
+func (c *Client) Policies(ctx context.Context, vhost string) ([]Policy, error) {
+	policies := []Policy{}
+	if err := c.do(ctx, http.MethodGet, path("policies", vhost), nil, &policies); err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// Policy returns a single policy.
+func (c *Client) Policy(ctx context.Context, vhost, name string) (*Policy, error) {
+	policy := &Policy{}
+	if err := c.do(ctx, http.MethodGet, path("policies", vhost, name), nil, policy); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// PutPolicy creates or replaces a policy.
+func (c *Client) PutPolicy(ctx context.Context, vhost, name string, settings PolicySettings) error {
+	return c.do(ctx, http.MethodPut, path("policies", vhost, name), settings, nil)
+}
+
+// DeletePolicy deletes a policy.
+func (c *Client) DeletePolicy(ctx context.Context, vhost, name string) error {
+	return c.do(ctx, http.MethodDelete, path("policies", vhost, name), nil, nil)
+}
+
+// OperatorPolicies returns the operator policies of a vhost, or of all vhosts if vhost is empty.
+func (c *Client) OperatorPolicies(ctx context.Context, vhost string) ([]Policy, error) {
+	policies := []Policy{}
+	if err := c.do(ctx, http.MethodGet, path("operator-policies", vhost), nil, &policies); err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// OperatorPolicy returns a single operator policy.
+func (c *Client) OperatorPolicy(ctx context.Context, vhost, name string) (*Policy, error) {
+	policy := &Policy{}
+	if err := c.do(ctx, http.MethodGet, path("operator-policies", vhost, name), nil, policy); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// PutOperatorPolicy creates or replaces an operator policy, limiting what policies and clients can set on queues.
+func (c *Client) PutOperatorPolicy(ctx context.Context, vhost, name string, settings PolicySettings) error {
+	return c.do(ctx, http.MethodPut, path("operator-policies", vhost, name), settings, nil)
+}
+
+// DeleteOperatorPolicy deletes an operator policy.
+func (c *Client) DeleteOperatorPolicy(ctx context.Context, vhost, name string) error {
+	return c.do(ctx, http.MethodDelete, path("operator-policies", vhost, name), nil, nil)
+}
+
+// Parameter returns a runtime parameter of a component, e.g. ComponentShovel.
+func (c *Client) Parameter(ctx context.Context, component, vhost, name string) (*Parameter, error) {
+	parameter := &Parameter{}
+	if err := c.do(ctx, http.MethodGet, path("parameters", component, vhost, name), nil, parameter); err != nil {
+		return nil, err
+	}
+
+	return parameter, nil
+}
+
+// PutParameter creates or replaces a runtime parameter, value is e.g. ShovelSettings.
+func (c *Client) PutParameter(ctx context.Context, component, vhost, name string, value any) error {
+	body := map[string]any{"value": value}
+	return c.do(ctx, http.MethodPut, path("parameters", component, vhost, name), body, nil)
+}
+
+// DeleteParameter deletes a runtime parameter, stopping the shovel or federation link it defines.
+func (c *Client) DeleteParameter(ctx context.Context, component, vhost, name string) error {
+	return c.do(ctx, http.MethodDelete, path("parameters", component, vhost, name), nil, nil)
+}
+
+// Shovels returns the state of the dynamic shovels of a vhost, or of all vhosts if vhost is empty.
+func (c *Client) Shovels(ctx context.Context, vhost string) ([]Shovel, error) {
+	shovels := []Shovel{}
+	if err := c.do(ctx, http.MethodGet, path("shovels", vhost), nil, &shovels); err != nil {
+		return nil, err
+	}
+
+	return shovels, nil
+}
+
+// Definitions returns the definitions of all entities on the broker.
+func (c *Client) Definitions(ctx context.Context) (*Definitions, error) {
+	definitions := &Definitions{}
+	if err := c.do(ctx, http.MethodGet, "/api/definitions", nil, definitions); err != nil {
+		return nil, err
+	}
+
+	return definitions, nil
+}
+
+// path joins the segments of an API path, escaping them as vhost names like "/" are used as segments.
+func path(resource string, segments ...string) string {
+	escaped := []string{"/api", resource}
+	for _, segment := range segments {
+		if segment == "" {
+			break
+		}
+		escaped = append(escaped, url.PathEscape(segment))
+	}
+
+	return strings.Join(escaped, "/")
+}
+
+func (c *Client) do(ctx context.Context, method, apiPath string, body any, out any) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+apiPath, payload)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.Username, c.Password)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		reason := strings.TrimSpace(string(data))
+		errResp := &errorResponse{}
+		if json.Unmarshal(data, errResp) == nil && errResp.Reason != "" {
+			reason = errResp.Reason
+		}
+		return &Error{StatusCode: resp.StatusCode, Reason: reason}
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,222 @@
+package management_test
+
+import (
+	"testing"
+
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverview(t *testing.T) {
+	t.Parallel()
+	server := testutils.StartFakeManagement("operator", "secret")
+	defer server.Close()
+
+	client := management.NewClient(server.URL, "operator", "secret")
+
+	overview, err := client.Overview(t.Context())
+	assert.NoError(t, err)
+	assert.NotEmpty(t, overview.LavinMQVersion)
+
+	server.SetNodes(management.Node{Name: "broker-0", Running: true}, management.Node{Name: "broker-1"})
+	nodes, err := client.Nodes(t.Context())
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 2)
+	assert.Equal(t, "broker-0", nodes[0].Name)
+}
+
+func TestAuthentication(t *testing.T) {
+	t.Parallel()
+	server := testutils.StartFakeManagement("operator", "secret")
+	defer server.Close()
+
+	client := management.NewClient(server.URL, "operator", "wrong")
+
+	_, err := client.Overview(t.Context())
+	assert.ErrorContains(t, err, "401")
+}
+
+func TestQueues(t *testing.T) {
+	t.Parallel()
+	server := testutils.StartFakeManagement("operator", "secret")
+	defer server.Close()
+
+	client := management.NewClient(server.URL, "operator", "secret")
+
+	_, err := client.Queue(t.Context(), "/", "orders")
+	assert.True(t, management.IsNotFound(err), "Expected not found, got %v", err)
+
+	settings := management.QueueSettings{Durable: true, Arguments: map[string]any{"x-max-length": 10}}
+	assert.NoError(t, client.DeclareQueue(t.Context(), "/", "orders", settings))
+	assert.NoError(t, client.DeclareQueue(t.Context(), "/", "orders", settings), "Redeclaring with the same settings is a no-op")
+
+	queue, err := client.Queue(t.Context(), "/", "orders")
+	assert.NoError(t, err)
+	assert.True(t, queue.Durable)
+	assert.Equal(t, "/", queue.Vhost)
+	assert.EqualValues(t, 10, queue.Arguments["x-max-length"])
+
+	err = client.DeclareQueue(t.Context(), "/", "orders", management.QueueSettings{Durable: false})
+	assert.True(t, management.IsBadRequest(err), "Expected the redeclaration to be rejected, got %v", err)
+
+	queues, err := client.Queues(t.Context(), "/")
+	assert.NoError(t, err)
+	assert.Len(t, queues, 1)
+
+	assert.NoError(t, client.DeleteQueue(t.Context(), "/", "orders"))
+	assert.True(t, management.IsNotFound(client.DeleteQueue(t.Context(), "/", "orders")))
+}
+
+func TestExchangesAndBindings(t *testing.T) {
+	t.Parallel()
+	server := testutils.StartFakeManagement("operator", "secret")
+	defer server.Close()
+
+	client := management.NewClient(server.URL, "operator", "secret")
+
+	settings := management.ExchangeSettings{Type: "topic", Durable: true}
+	assert.NoError(t, client.DeclareExchange(t.Context(), "/", "events", settings))
+	assert.NoError(t, client.DeclareExchange(t.Context(), "/", "events", settings), "Redeclaring with the same settings is a no-op")
+	err := client.DeclareExchange(t.Context(), "/", "events", management.ExchangeSettings{Type: "fanout", Durable: true})
+	assert.True(t, management.IsBadRequest(err), "Expected the redeclaration to be rejected, got %v", err)
+
+	exchange, err := client.Exchange(t.Context(), "/", "events")
+	assert.NoError(t, err)
+	assert.Equal(t, "topic", exchange.Type)
+
+	err = client.Bind(t.Context(), "/", "events", management.DestinationTypeQueue, "orders", management.BindingSettings{RoutingKey: "order.*"})
+	assert.True(t, management.IsNotFound(err), "Expected the destination to be required, got %v", err)
+
+	assert.NoError(t, client.DeclareQueue(t.Context(), "/", "orders", management.QueueSettings{Durable: true}))
+	assert.NoError(t, client.Bind(t.Context(), "/", "events", management.DestinationTypeQueue, "orders", management.BindingSettings{RoutingKey: "order.*"}))
+
+	bindings, err := client.Bindings(t.Context(), "/", "events", management.DestinationTypeQueue, "orders")
+	assert.NoError(t, err)
+	assert.Len(t, bindings, 1)
+	assert.Equal(t, "order.*", bindings[0].RoutingKey)
+	assert.Equal(t, management.DestinationTypeQueue, bindings[0].DestinationType)
+
+	assert.NoError(t, client.Unbind(t.Context(), "/", "events", management.DestinationTypeQueue, "orders", bindings[0].PropertiesKey))
+	bindings, err = client.Bindings(t.Context(), "/", "events", management.DestinationTypeQueue, "orders")
+	assert.NoError(t, err)
+	assert.Empty(t, bindings)
+
+	assert.NoError(t, client.DeleteExchange(t.Context(), "/", "events"))
+	_, err = client.Exchange(t.Context(), "/", "events")
+	assert.True(t, management.IsNotFound(err))
+}
+
+func TestUsersAndPermissions(t *testing.T) {
+	t.Parallel()
+	server := testutils.StartFakeManagement("operator", "secret")
+	defer server.Close()
+
+	client := management.NewClient(server.URL, "operator", "secret")
+
+	assert.NoError(t, client.PutVhost(t.Context(), "team-a"))
+	assert.NoError(t, client.PutUser(t.Context(), "app", management.UserSettings{Password: "pw", Tags: "monitoring"}))
+	assert.NoError(t, client.SetPermissions(t.Context(), "team-a", "app", management.PermissionSettings{Configure: "^app\\.", Write: ".*", Read: ".*"}))
+
+	user, err := client.User(t.Context(), "app")
+	assert.NoError(t, err)
+	assert.Equal(t, "monitoring", user.Tags)
+	assert.Equal(t, "pw", server.Password("app"))
+
+	permission, err := client.Permission(t.Context(), "team-a", "app")
+	assert.NoError(t, err)
+	assert.Equal(t, "^app\\.", permission.Configure)
+
+	definitions, err := client.Definitions(t.Context())
+	assert.NoError(t, err)
+	assert.Len(t, definitions.Users, 2)
+	assert.Len(t, definitions.Vhosts, 2)
+	assert.Len(t, definitions.Permissions, 1)
+
+	assert.NoError(t, client.DeleteVhost(t.Context(), "team-a"))
+	permissions, err := client.Permissions(t.Context())
+	assert.NoError(t, err)
+	assert.Empty(t, permissions, "Deleting a vhost removes the permissions in it")
+
+	assert.NoError(t, client.DeleteUser(t.Context(), "app"))
+	_, err = client.User(t.Context(), "app")
+	assert.True(t, management.IsNotFound(err))
+}
+
+func TestPolicies(t *testing.T) {
+	t.Parallel()
+	server := testutils.StartFakeManagement("operator", "secret")
+	defer server.Close()
+
+	client := management.NewClient(server.URL+"/", "operator", "secret")
+
+	err := client.PutPolicy(t.Context(), "missing", "ttl", management.PolicySettings{Pattern: ".*"})
+	assert.True(t, management.IsNotFound(err), "Expected the vhost to be required, got %v", err)
+
+	settings := management.PolicySettings{Pattern: "^orders$", ApplyTo: "queues", Priority: 1, Definition: map[string]any{"message-ttl": 60000}}
+	assert.NoError(t, client.PutPolicy(t.Context(), "/", "ttl", settings))
+
+	policy, err := client.Policy(t.Context(), "/", "ttl")
+	assert.NoError(t, err)
+	assert.Equal(t, "queues", policy.ApplyTo)
+	assert.EqualValues(t, 60000, policy.Definition["message-ttl"])
+
+	policies, err := client.Policies(t.Context(), "")
+	assert.NoError(t, err)
+	assert.Len(t, policies, 1)
+
+	assert.NoError(t, client.DeclareQueue(t.Context(), "/", "orders", management.QueueSettings{Durable: true}))
+	assert.NoError(t, client.PutOperatorPolicy(t.Context(), "/", "limit", management.PolicySettings{Pattern: ".*", ApplyTo: "queues", Definition: map[string]any{"max-length": 1000}}))
+	queues, err := client.Queues(t.Context(), "/")
+	assert.NoError(t, err)
+	assert.Equal(t, "ttl", queues[0].Policy, "Expected the policy to be in effect for the queue")
+	assert.Equal(t, "limit", queues[0].OperatorPolicy)
+
+	operatorPolicies, err := client.OperatorPolicies(t.Context(), "/")
+	assert.NoError(t, err)
+	assert.Len(t, operatorPolicies, 1)
+	assert.NoError(t, client.DeleteOperatorPolicy(t.Context(), "/", "limit"))
+
+	assert.NoError(t, client.DeletePolicy(t.Context(), "/", "ttl"))
+	policies, err = client.Policies(t.Context(), "/")
+	assert.NoError(t, err)
+	assert.Empty(t, policies)
+}
+
+func TestParametersAndShovels(t *testing.T) {
+	t.Parallel()
+	server := testutils.StartFakeManagement("operator", "secret")
+	defer server.Close()
+
+	client := management.NewClient(server.URL, "operator", "secret")
+
+	err := client.PutParameter(t.Context(), management.ComponentShovel, "/", "orders", management.ShovelSettings{SrcQueue: "orders"})
+	assert.True(t, management.IsBadRequest(err), "Expected the URIs to be required, got %v", err)
+
+	settings := management.ShovelSettings{SrcURI: "amqp://a", SrcQueue: "orders", DestURI: "amqp://b", DestExchange: "orders", AckMode: "on-confirm"}
+	assert.NoError(t, client.PutParameter(t.Context(), management.ComponentShovel, "/", "orders", settings))
+
+	parameter, err := client.Parameter(t.Context(), management.ComponentShovel, "/", "orders")
+	assert.NoError(t, err)
+	assert.Equal(t, "amqp://b", parameter.Value["dest-uri"])
+	assert.NotContains(t, parameter.Value, "src-exchange")
+
+	server.SetShovelState("/", "orders", "Terminated", "connection refused")
+	shovels, err := client.Shovels(t.Context(), "/")
+	assert.NoError(t, err)
+	assert.Equal(t, []management.Shovel{{Name: "orders", Vhost: "/", State: "Terminated", Error: "connection refused"}}, shovels)
+
+	upstream := management.FederationUpstreamSettings{URI: "amqp://upstream", Exchange: "orders", MaxHops: 1}
+	assert.NoError(t, client.PutParameter(t.Context(), management.ComponentFederationUpstream, "/", "eu", upstream))
+	parameter, err = client.Parameter(t.Context(), management.ComponentFederationUpstream, "/", "eu")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, parameter.Value["max-hops"])
+
+	assert.NoError(t, client.DeleteParameter(t.Context(), management.ComponentShovel, "/", "orders"))
+	shovels, err = client.Shovels(t.Context(), "")
+	assert.NoError(t, err)
+	assert.Empty(t, shovels)
+	_, err = client.Parameter(t.Context(), management.ComponentShovel, "/", "orders")
+	assert.True(t, management.IsNotFound(err), "Expected the shovel to be deleted, got %v", err)
+}
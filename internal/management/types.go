@@ -0,0 +1,227 @@
+package management
+
+// Overview is the cluster wide summary returned by /api/overview.
+type Overview struct {
+	LavinMQVersion string       `json:"lavinmq_version"`
+	Node           string       `json:"node"`
+	Uptime         int64        `json:"uptime"`
+	ObjectTotals   ObjectTotals `json:"object_totals"`
+	QueueTotals    QueueTotals  `json:"queue_totals"`
+}
+
+type ObjectTotals struct {
+	Channels    int `json:"channels"`
+	Connections int `json:"connections"`
+	Consumers   int `json:"consumers"`
+	Exchanges   int `json:"exchanges"`
+	Queues      int `json:"queues"`
+}
+
+type QueueTotals struct {
+	Messages               int64 `json:"messages"`
+	MessagesReady          int64 `json:"messages_ready"`
+	MessagesUnacknowledged int64 `json:"messages_unacknowledged"`
+}
+
+// Node is a single node as returned by /api/nodes.
+type Node struct {
+	Name      string `json:"name"`
+	Running   bool   `json:"running"`
+	Uptime    int64  `json:"uptime"`
+	MemUsed   int64  `json:"mem_used"`
+	MemLimit  int64  `json:"mem_limit"`
+	DiskFree  int64  `json:"disk_free"`
+	DiskTotal int64  `json:"disk_total"`
+}
+
+type Queue struct {
+	Name                   string         `json:"name"`
+	Vhost                  string         `json:"vhost"`
+	Durable                bool           `json:"durable"`
+	AutoDelete             bool           `json:"auto_delete"`
+	Exclusive              bool           `json:"exclusive"`
+	Arguments              map[string]any `json:"arguments"`
+	Policy                 string         `json:"policy,omitempty"`
+	OperatorPolicy         string         `json:"operator_policy,omitempty"`
+	State                  string         `json:"state,omitempty"`
+	Consumers              int            `json:"consumers"`
+	Messages               int64          `json:"messages"`
+	MessagesReady          int64          `json:"messages_ready"`
+	MessagesUnacknowledged int64          `json:"messages_unacknowledged"`
+}
+
+// QueueSettings is the body of a queue declaration.
+type QueueSettings struct {
+	Durable    bool           `json:"durable"`
+	AutoDelete bool           `json:"auto_delete"`
+	Arguments  map[string]any `json:"arguments,omitempty"`
+}
+
+type Exchange struct {
+	Name       string         `json:"name"`
+	Vhost      string         `json:"vhost"`
+	Type       string         `json:"type"`
+	Durable    bool           `json:"durable"`
+	AutoDelete bool           `json:"auto_delete"`
+	Internal   bool           `json:"internal"`
+	Arguments  map[string]any `json:"arguments"`
+	Policy     string         `json:"policy,omitempty"`
+}
+
+// ExchangeSettings is the body of an exchange declaration.
+type ExchangeSettings struct {
+	Type       string         `json:"type"`
+	Durable    bool           `json:"durable"`
+	AutoDelete bool           `json:"auto_delete"`
+	Internal   bool           `json:"internal"`
+	Arguments  map[string]any `json:"arguments,omitempty"`
+}
+
+// DestinationType tells whether a binding routes to a queue or to another exchange.
+type DestinationType string
+
+const (
+	DestinationTypeQueue    DestinationType = "queue"
+	DestinationTypeExchange DestinationType = "exchange"
+)
+
+// segment returns the abbreviation used for the destination type in binding paths.
+func (t DestinationType) segment() string {
+	if t == DestinationTypeExchange {
+		return "e"
+	}
+
+	return "q"
+}
+
+type Binding struct {
+	Source          string          `json:"source"`
+	Vhost           string          `json:"vhost"`
+	Destination     string          `json:"destination"`
+	DestinationType DestinationType `json:"destination_type"`
+	RoutingKey      string          `json:"routing_key"`
+	Arguments       map[string]any  `json:"arguments"`
+	PropertiesKey   string          `json:"properties_key"`
+}
+
+// BindingSettings is the body of a binding creation.
+type BindingSettings struct {
+	RoutingKey string         `json:"routing_key"`
+	Arguments  map[string]any `json:"arguments,omitempty"`
+}
+
+type User struct {
+	Name             string `json:"name"`
+	PasswordHash     string `json:"password_hash,omitempty"`
+	HashingAlgorithm string `json:"hashing_algorithm,omitempty"`
+	// Tags is a comma separated list, e.g. "administrator,monitoring".
+	Tags string `json:"tags"`
+}
+
+// UserSettings is the body of a user creation or update, either the password or its hash is set.
+type UserSettings struct {
+	Password     string `json:"password,omitempty"`
+	PasswordHash string `json:"password_hash,omitempty"`
+	Tags         string `json:"tags"`
+}
+
+type Permission struct {
+	User      string `json:"user"`
+	Vhost     string `json:"vhost"`
+	Configure string `json:"configure"`
+	Write     string `json:"write"`
+	Read      string `json:"read"`
+}
+
+// PermissionSettings holds the regular expressions matching the resources a user may access.
+type PermissionSettings struct {
+	Configure string `json:"configure"`
+	Write     string `json:"write"`
+	Read      string `json:"read"`
+}
+
+type Vhost struct {
+	Name     string `json:"name"`
+	Messages int64  `json:"messages"`
+}
+
+type Policy struct {
+	Name       string         `json:"name"`
+	Vhost      string         `json:"vhost"`
+	Pattern    string         `json:"pattern"`
+	ApplyTo    string         `json:"apply-to"`
+	Priority   int            `json:"priority"`
+	Definition map[string]any `json:"definition"`
+}
+
+// PolicySettings is the body of a policy creation or update.
+type PolicySettings struct {
+	Pattern    string         `json:"pattern"`
+	ApplyTo    string         `json:"apply-to,omitempty"`
+	Priority   int            `json:"priority"`
+	Definition map[string]any `json:"definition"`
+}
+
+// Components of the runtime parameters managed by the operator.
+const (
+	ComponentShovel             = "shovel"
+	ComponentFederationUpstream = "federation-upstream"
+)
+
+// Parameter is a runtime parameter of a component, e.g. the definition of a dynamic shovel.
+type Parameter struct {
+	Name      string         `json:"name"`
+	Vhost     string         `json:"vhost"`
+	Component string         `json:"component"`
+	Value     map[string]any `json:"value"`
+}
+
+// ShovelSettings is the value of a shovel parameter.
+type ShovelSettings struct {
+	SrcURI           string `json:"src-uri"`
+	SrcQueue         string `json:"src-queue,omitempty"`
+	SrcExchange      string `json:"src-exchange,omitempty"`
+	SrcExchangeKey   string `json:"src-exchange-key,omitempty"`
+	SrcPrefetchCount int    `json:"src-prefetch-count,omitempty"`
+	DestURI          string `json:"dest-uri"`
+	DestQueue        string `json:"dest-queue,omitempty"`
+	DestExchange     string `json:"dest-exchange,omitempty"`
+	DestExchangeKey  string `json:"dest-exchange-key,omitempty"`
+	AckMode          string `json:"ack-mode,omitempty"`
+	// ReconnectDelay is in seconds.
+	ReconnectDelay int `json:"reconnect-delay,omitempty"`
+}
+
+// Shovel is the state of a dynamic shovel as returned by /api/shovels, e.g. Running or Terminated.
+type Shovel struct {
+	Name  string `json:"name"`
+	Vhost string `json:"vhost"`
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+// FederationUpstreamSettings is the value of a federation-upstream parameter.
+type FederationUpstreamSettings struct {
+	URI           string `json:"uri"`
+	Exchange      string `json:"exchange,omitempty"`
+	Queue         string `json:"queue,omitempty"`
+	PrefetchCount int    `json:"prefetch-count,omitempty"`
+	// ReconnectDelay is in seconds, Expires and MessageTTL in milliseconds.
+	ReconnectDelay int    `json:"reconnect-delay,omitempty"`
+	AckMode        string `json:"ack-mode,omitempty"`
+	MaxHops        int    `json:"max-hops,omitempty"`
+	Expires        int64  `json:"expires,omitempty"`
+	MessageTTL     int64  `json:"message-ttl,omitempty"`
+}
+
+// Definitions is the export of all entities returned by /api/definitions.
+type Definitions struct {
+	LavinMQVersion string       `json:"lavinmq_version"`
+	Users          []User       `json:"users"`
+	Vhosts         []Vhost      `json:"vhosts"`
+	Permissions    []Permission `json:"permissions"`
+	Queues         []Queue      `json:"queues"`
+	Exchanges      []Exchange   `json:"exchanges"`
+	Bindings       []Binding    `json:"bindings"`
+	Policies       []Policy     `json:"policies"`
+}
@@ -0,0 +1,164 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudamqp/lavinmq-operator/internal/controller/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// cert-manager is optional, Certificates are handled as unstructured objects so the operator
+// doesn't depend on it being installed.
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// CertificateReconciler requests the broker certificate from cert-manager when spec.tls is set.
+// The DNS names follow the services and replicas of the instance, so they're kept up to date on scaling.
+type CertificateReconciler struct {
+	*ResourceReconciler
+}
+
+func (reconciler *ResourceReconciler) CertificateReconciler() *CertificateReconciler {
+	return &CertificateReconciler{
+		ResourceReconciler: reconciler,
+	}
+}
+
+func (b *CertificateReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	certificate := b.newObject()
+
+	err := b.GetItem(ctx, certificate)
+	if b.Instance.Spec.Tls == nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return ctrl.Result{}, nil
+		}
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// A Certificate with the same name requested by hand is left alone.
+		if !metav1.IsControlledBy(certificate, b.Instance) {
+			return ctrl.Result{}, nil
+		}
+
+		b.Logger.Info("TLS removed from spec, deleting certificate", "name", certificate.GetName())
+		if err := b.Client.Delete(ctx, certificate); err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+
+		if err := b.updateFields(ctx, certificate); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := b.CreateItem(ctx, certificate); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		return b.waitForSecret(ctx)
+	}
+
+	if err := b.updateFields(ctx, certificate); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = b.Client.Update(ctx, certificate)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return b.waitForSecret(ctx)
+}
+
+// waitForSecret requeues until cert-manager has issued the certificate.
+func (b *CertificateReconciler) waitForSecret(ctx context.Context) (ctrl.Result, error) {
+	secret := &corev1.Secret{}
+	secret.Name = b.Instance.TlsSecretName()
+	secret.Namespace = b.Instance.Namespace
+
+	if err := b.GetItem(ctx, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			b.Logger.Info("Waiting for certificate to be issued", "secret", secret.Name)
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (b *CertificateReconciler) newObject() *unstructured.Unstructured {
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(certificateGVK)
+	certificate.SetName(b.Instance.Name)
+	certificate.SetNamespace(b.Instance.Namespace)
+
+	return certificate
+}
+
+func (b *CertificateReconciler) updateFields(_ context.Context, certificate *unstructured.Unstructured) error {
+	spec := b.Instance.Spec.Tls
+
+	dnsNames := []any{}
+	for _, name := range b.dnsNames() {
+		dnsNames = append(dnsNames, name)
+	}
+
+	issuerRef := map[string]any{"name": spec.IssuerRef.Name}
+	if spec.IssuerRef.Kind != "" {
+		issuerRef["kind"] = spec.IssuerRef.Kind
+	}
+	if spec.IssuerRef.Group != "" {
+		issuerRef["group"] = spec.IssuerRef.Group
+	}
+
+	certificate.SetLabels(utils.LabelsForLavinMQ(b.Instance))
+	if err := unstructured.SetNestedField(certificate.Object, b.Instance.TlsSecretName(), "spec", "secretName"); err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedSlice(certificate.Object, dnsNames, "spec", "dnsNames"); err != nil {
+		return err
+	}
+
+	return unstructured.SetNestedMap(certificate.Object, issuerRef, "spec", "issuerRef")
+}
+
+// dnsNames returns the names clients and peers reach the instance on: the headless and leader services,
+// each pod as advertised for clustering, and the additional names from the spec.
+func (b *CertificateReconciler) dnsNames() []string {
+	name := b.Instance.Name
+	namespace := b.Instance.Namespace
+
+	names := []string{}
+	for _, service := range []string{name, name + "-leader"} {
+		names = append(names,
+			service,
+			fmt.Sprintf("%s.%s", service, namespace),
+			fmt.Sprintf("%s.%s.svc", service, namespace),
+			fmt.Sprintf("%s.%s.svc.cluster.local", service, namespace),
+		)
+	}
+
+	for i := range b.Instance.Spec.Replicas {
+		names = append(names, fmt.Sprintf("%s-%d.%s.%s.svc.cluster.local", name, i, name, namespace))
+	}
+
+	return append(names, b.Instance.Spec.Tls.DNSNames...)
+}
+
+// Name returns the name of the certificate reconciler
+func (b *CertificateReconciler) Name() string {
+	return "certificate"
+}
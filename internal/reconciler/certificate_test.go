@@ -0,0 +1,63 @@
+package reconciler_test
+
+import (
+	"testing"
+
+	"github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
+	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestNoCertificateByDefault(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	rc := &reconciler.CertificateReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	// cert-manager isn't installed in the test environment, which is fine as long as it isn't used
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, "", instance.TlsSecretName())
+}
+
+func TestCertificateRequiresCertManager(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+
+	instance.Spec.Tls = &v1alpha1.TlsSpec{IssuerRef: v1alpha1.IssuerReference{Name: "ca", Kind: "ClusterIssuer"}}
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	rc := &reconciler.CertificateReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	_, err = rc.Reconcile(t.Context())
+	assert.True(t, meta.IsNoMatchError(err), "Expected the missing cert-manager CRD to be reported")
+	assert.Equal(t, instance.Name+"-tls", instance.TlsSecretName())
+}
@@ -21,6 +21,12 @@ type ConfigReconciler struct {
 
 var ConfigFileName = "lavinmq.ini"
 
+// Mount paths of the secrets from spec.etcd, referenced from the clustering section.
+const (
+	etcdTlsPath         = "/etc/lavinmq/etcd/tls"
+	etcdCredentialsPath = "/etc/lavinmq/etcd/credentials"
+)
+
 var (
 	defaultConfig = `
 [main]
@@ -164,7 +170,7 @@ func (b *ConfigReconciler) AppendMainConfig(cfg *ini.File) {
 	if mainConfig.TlsMinVersion != "" {
 		cfg.Section("main").Key("tls_min_version").SetValue(mainConfig.TlsMinVersion)
 	}
-	if b.Instance.Spec.TlsSecret != nil {
+	if b.Instance.TlsSecretName() != "" {
 		cfg.Section("main").Key("tls_cert").SetValue(fmt.Sprintf("/etc/lavinmq/tls/%s", "tls.crt"))
 		cfg.Section("main").Key("tls_key").SetValue(fmt.Sprintf("/etc/lavinmq/tls/%s", "tls.key"))
 	}
@@ -172,10 +178,28 @@ func (b *ConfigReconciler) AppendMainConfig(cfg *ini.File) {
 
 func (b *ConfigReconciler) AppendClusteringConfig(cfg *ini.File) {
 
-	if b.Instance.Spec.EtcdEndpoints != nil {
-		cfg.Section("clustering").Key("etcd_prefix").SetValue(b.Instance.Name)
-		cfg.Section("clustering").Key("etcd_endpoints").SetValue(strings.Join(b.Instance.Spec.EtcdEndpoints, ","))
+	if b.Instance.EtcdEndpoints() != nil {
+		cfg.Section("clustering").Key("etcd_prefix").SetValue(b.EtcdPrefix())
+		cfg.Section("clustering").Key("etcd_endpoints").SetValue(strings.Join(b.Instance.EtcdEndpoints(), ","))
 		cfg.Section("clustering").Key("enabled").SetValue("true")
+
+		// Only paths of the mounted secrets are rendered, the secrets themselves never end up in the ConfigMap.
+		if b.Instance.EtcdTlsSecretName() != "" {
+			cfg.Section("clustering").Key("etcd_tls_cert").SetValue(fmt.Sprintf("%s/%s", etcdTlsPath, corev1.TLSCertKey))
+			cfg.Section("clustering").Key("etcd_tls_key").SetValue(fmt.Sprintf("%s/%s", etcdTlsPath, corev1.TLSPrivateKeyKey))
+			cfg.Section("clustering").Key("etcd_tls_ca_cert").SetValue(fmt.Sprintf("%s/%s", etcdTlsPath, "ca.crt"))
+		}
+		if etcd := b.Instance.Spec.Etcd; etcd != nil {
+			if etcd.CredentialsSecretRef != nil {
+				cfg.Section("clustering").Key("etcd_username_file").SetValue(fmt.Sprintf("%s/%s", etcdCredentialsPath, corev1.BasicAuthUsernameKey))
+				cfg.Section("clustering").Key("etcd_password_file").SetValue(fmt.Sprintf("%s/%s", etcdCredentialsPath, corev1.BasicAuthPasswordKey))
+			}
+		}
+	}
+
+	// Replication between the nodes uses the broker certificate from the main section.
+	if b.Instance.Spec.Config.Clustering.Tls {
+		cfg.Section("clustering").Key("tls").SetValue("true")
 	}
 
 	if b.Instance.Spec.Config.Clustering.MaxUnsyncedActions != 0 {
@@ -183,6 +207,28 @@ func (b *ConfigReconciler) AppendClusteringConfig(cfg *ini.File) {
 	}
 }
 
+// ConfiguredEtcdPrefix returns the etcd prefix in the existing ConfigMap, empty if there is none. Instances
+// created before the prefix was configurable used their name and have to stay on it.
+func (reconciler *ResourceReconciler) ConfiguredEtcdPrefix(ctx context.Context) (string, error) {
+	configMap := &corev1.ConfigMap{}
+	configMap.Name = reconciler.Instance.Name
+	configMap.Namespace = reconciler.Instance.Namespace
+
+	if err := reconciler.GetItem(ctx, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	cfg, err := ini.Load([]byte(configMap.Data[ConfigFileName]))
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return cfg.Section("clustering").Key("etcd_prefix").String(), nil
+}
+
 func (b *ConfigReconciler) AppendAmqpConfig(cfg *ini.File) {
 	amqpConfig := b.Instance.Spec.Config.Amqp
 
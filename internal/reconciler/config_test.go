@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
 	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
 	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
 
@@ -182,3 +183,49 @@ func TestDisablingNonTlsPorts(t *testing.T) {
 	assert.Equal(t, instance.Name, configMap.Name)
 	verifyConfigMapEquality(t, configMap, expectedConfig)
 }
+
+func TestEtcdClientConfig(t *testing.T) {
+	t.Parallel()
+	replicas := int32(3)
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{Replicas: &replicas})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+
+	instance.Spec.EtcdEndpoints = []string{"https://etcd:2379"}
+	instance.Spec.TlsSecret = &corev1.SecretReference{Name: "tls"}
+	instance.Spec.Etcd = &v1alpha1.EtcdSpec{
+		TlsSecretRef:         &corev1.LocalObjectReference{Name: "etcd-client"},
+		CredentialsSecretRef: &corev1.LocalObjectReference{Name: "etcd-credentials"},
+	}
+	instance.Spec.Config.Clustering.Tls = true
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	rc := &reconciler.ConfigReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	configMap := &corev1.ConfigMap{}
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, configMap)
+	assert.NoError(t, err)
+
+	conf, err := ini.Load([]byte(configMap.Data[reconciler.ConfigFileName]))
+	assert.NoError(t, err)
+	clustering := conf.Section("clustering")
+	assert.Equal(t, "https://etcd:2379", clustering.Key("etcd_endpoints").Value())
+	assert.Equal(t, "/etc/lavinmq/etcd/tls/tls.crt", clustering.Key("etcd_tls_cert").Value())
+	assert.Equal(t, "/etc/lavinmq/etcd/tls/tls.key", clustering.Key("etcd_tls_key").Value())
+	assert.Equal(t, "/etc/lavinmq/etcd/tls/ca.crt", clustering.Key("etcd_tls_ca_cert").Value())
+	assert.Equal(t, "/etc/lavinmq/etcd/credentials/username", clustering.Key("etcd_username_file").Value())
+	assert.Equal(t, "/etc/lavinmq/etcd/credentials/password", clustering.Key("etcd_password_file").Value())
+	assert.Equal(t, "true", clustering.Key("tls").Value())
+}
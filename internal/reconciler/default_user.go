@@ -0,0 +1,160 @@
+package reconciler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"strconv"
+
+	"github.com/cloudamqp/lavinmq-operator/internal/controller/utils"
+	resource_utils "github.com/cloudamqp/lavinmq-operator/internal/reconciler/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Keys of the default user Secret
+const (
+	DefaultUserUsernameKey     = "username"
+	DefaultUserPasswordKey     = "password"
+	DefaultUserPasswordHashKey = "password_hash"
+)
+
+const defaultUsername = "admin"
+
+// DefaultUserReconciler maintains the Secret referenced by spec.defaultUserSecretRef. It generates the
+// credentials if needed, the password hash LavinMQ is started with and connection URIs for applications.
+type DefaultUserReconciler struct {
+	*ResourceReconciler
+}
+
+func (reconciler *ResourceReconciler) DefaultUserReconciler() *DefaultUserReconciler {
+	return &DefaultUserReconciler{
+		ResourceReconciler: reconciler,
+	}
+}
+
+func (b *DefaultUserReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	if b.Instance.Spec.DefaultUserSecretRef == nil {
+		return ctrl.Result{}, nil
+	}
+
+	secret := b.newObject()
+
+	err := b.GetItem(ctx, secret)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+
+		// Generated secrets are owned by the instance, user provided ones are left alone on deletion.
+		b.Logger.Info("Default user secret not found, generating credentials", "name", secret.Name)
+		if err := b.updateFields(ctx, secret); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		return ctrl.Result{}, b.CreateItem(ctx, secret)
+	}
+
+	if err := b.updateFields(ctx, secret); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = b.Client.Update(ctx, secret)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (b *DefaultUserReconciler) newObject() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.Instance.Spec.DefaultUserSecretRef.Name,
+			Namespace: b.Instance.Namespace,
+			Labels:    utils.LabelsForLavinMQ(b.Instance),
+		},
+		Type: corev1.SecretTypeOpaque,
+	}
+}
+
+func (b *DefaultUserReconciler) updateFields(_ context.Context, secret *corev1.Secret) error {
+	data := maps.Clone(secret.Data)
+	if data == nil {
+		data = map[string][]byte{}
+	}
+
+	if len(data[DefaultUserUsernameKey]) == 0 {
+		data[DefaultUserUsernameKey] = []byte(defaultUsername)
+	}
+
+	if len(data[DefaultUserPasswordKey]) == 0 {
+		password, err := resource_utils.GeneratePassword()
+		if err != nil {
+			return fmt.Errorf("failed to generate password: %w", err)
+		}
+		data[DefaultUserPasswordKey] = []byte(password)
+	}
+
+	// The hash is salted, only rehash when the password changed so the pods aren't restarted needlessly.
+	password := string(data[DefaultUserPasswordKey])
+	if !resource_utils.VerifyPasswordHash(string(data[DefaultUserPasswordHashKey]), password) {
+		b.Logger.Info("Default user password changed, updating hash", "name", secret.Name)
+		hash, err := resource_utils.HashPassword(password)
+		if err != nil {
+			return fmt.Errorf("failed to hash password: %w", err)
+		}
+		data[DefaultUserPasswordHashKey] = []byte(hash)
+	}
+
+	for key, uri := range b.connectionURIs(string(data[DefaultUserUsernameKey]), password) {
+		if uri == "" {
+			delete(data, key)
+			continue
+		}
+		data[key] = []byte(uri)
+	}
+
+	if !maps.EqualFunc(secret.Data, data, bytes.Equal) {
+		secret.Data = data
+	}
+
+	return nil
+}
+
+// connectionURIs returns the URI for each protocol, empty for disabled ports. The URIs point
+// to the leader service as that is where clients are served.
+func (b *DefaultUserReconciler) connectionURIs(username, password string) map[string]string {
+	host := fmt.Sprintf("%s-leader.%s.svc", b.Instance.Name, b.Instance.Namespace)
+	config := b.Instance.Spec.Config
+
+	uri := func(scheme string, port int32, enabled bool) string {
+		if !enabled {
+			return ""
+		}
+		u := url.URL{
+			Scheme: scheme,
+			User:   url.UserPassword(username, password),
+			Host:   net.JoinHostPort(host, strconv.Itoa(int(port))),
+		}
+		return u.String()
+	}
+
+	return map[string]string{
+		"amqp_uri":  uri("amqp", config.Amqp.Port, config.Amqp.Port > 0),
+		"amqps_uri": uri("amqps", config.Amqp.TlsPort, config.Amqp.TlsPort != 0),
+		"mqtt_uri":  uri("mqtt", config.Mqtt.Port, config.Mqtt.Port > 0),
+		"mqtts_uri": uri("mqtts", config.Mqtt.TlsPort, config.Mqtt.TlsPort != 0),
+	}
+}
+
+// Name returns the name of the default user reconciler
+func (b *DefaultUserReconciler) Name() string {
+	return "default-user"
+}
@@ -0,0 +1,107 @@
+package reconciler_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
+	resource_utils "github.com/cloudamqp/lavinmq-operator/internal/reconciler/utils"
+	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestDefaultUserSecretGenerated(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+
+	instance.Spec.DefaultUserSecretRef = &corev1.LocalObjectReference{Name: instance.Name + "-default-user"}
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	rc := &reconciler.DefaultUserReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	secret := &corev1.Secret{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name + "-default-user", Namespace: instance.Namespace}, secret))
+	assert.True(t, metav1.IsControlledBy(secret, instance))
+
+	username := string(secret.Data[reconciler.DefaultUserUsernameKey])
+	password := string(secret.Data[reconciler.DefaultUserPasswordKey])
+	assert.Equal(t, "admin", username)
+	assert.NotEmpty(t, password)
+	assert.True(t, resource_utils.VerifyPasswordHash(string(secret.Data[reconciler.DefaultUserPasswordHashKey]), password))
+	assert.Equal(t, fmt.Sprintf("amqp://admin:%s@%s-leader.%s.svc:5672", password, instance.Name, instance.Namespace), string(secret.Data["amqp_uri"]))
+	assert.Contains(t, secret.Data, "mqtt_uri")
+	assert.NotContains(t, secret.Data, "amqps_uri")
+
+	t.Log("Reconciling again keeps the generated password and hash")
+	hash := string(secret.Data[reconciler.DefaultUserPasswordHashKey])
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name + "-default-user", Namespace: instance.Namespace}, secret))
+	assert.Equal(t, password, string(secret.Data[reconciler.DefaultUserPasswordKey]))
+	assert.Equal(t, hash, string(secret.Data[reconciler.DefaultUserPasswordHashKey]))
+}
+
+func TestDefaultUserSecretProvided(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: instance.Namespace},
+		StringData: map[string]string{"username": "app", "password": "first"},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), secret))
+
+	instance.Spec.DefaultUserSecretRef = &corev1.LocalObjectReference{Name: "credentials"}
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	rc := &reconciler.DefaultUserReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: "credentials", Namespace: instance.Namespace}, secret))
+	assert.False(t, metav1.IsControlledBy(secret, instance))
+	assert.Equal(t, "app", string(secret.Data[reconciler.DefaultUserUsernameKey]))
+	assert.True(t, resource_utils.VerifyPasswordHash(string(secret.Data[reconciler.DefaultUserPasswordHashKey]), "first"))
+
+	t.Log("Rotating the password updates the hash")
+	secret.Data[reconciler.DefaultUserPasswordKey] = []byte("second")
+	assert.NoError(t, k8sClient.Update(t.Context(), secret))
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: "credentials", Namespace: instance.Namespace}, secret))
+	assert.True(t, resource_utils.VerifyPasswordHash(string(secret.Data[reconciler.DefaultUserPasswordHashKey]), "second"))
+	assert.Contains(t, string(secret.Data["amqp_uri"]), "app:second@")
+}
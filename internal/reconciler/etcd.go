@@ -0,0 +1,442 @@
+package reconciler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/cloudamqp/lavinmq-operator/internal/controller/utils"
+	resource_utils "github.com/cloudamqp/lavinmq-operator/internal/reconciler/utils"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	etcdClientPort  = 2379
+	etcdPeerPort    = 2380
+	etcdMetricsPort = 2381
+
+	etcdTlsMountPath = "/etc/etcd/tls"
+
+	// The certificates aren't rotated, they're only used between the members, LavinMQ and the operator.
+	etcdPeerCertificateValidity = 10 * 365 * 24 * time.Hour
+
+	// Key of the server Secret holding the client certificate, which acts as its own CA.
+	etcdClientCAKey = "client-ca.crt"
+)
+
+// EtcdReconciler provisions the etcd cluster used for clustering when spec.etcd.managed is set. The members
+// serve both peers and clients over mutual TLS with certificates generated by the operator. The client
+// certificate is shared by the LavinMQ nodes and the operator, nothing else in the cluster can access etcd.
+type EtcdReconciler struct {
+	*ResourceReconciler
+}
+
+func (reconciler *ResourceReconciler) EtcdReconciler() *EtcdReconciler {
+	return &EtcdReconciler{
+		ResourceReconciler: reconciler,
+	}
+}
+
+func (b *EtcdReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	if !b.Instance.ManagedEtcd() {
+		return ctrl.Result{}, b.deleteCluster(ctx)
+	}
+
+	if err := b.reconcileSecret(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := b.reconcileService(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := b.reconcileStatefulSet(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	ready, err := b.etcdQuorumReady(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !ready {
+		b.Logger.Info("Waiting for etcd quorum", "name", b.Instance.ManagedEtcdName())
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// etcdQuorumReady reports whether enough members of the managed etcd cluster are ready to form a quorum.
+// Always true when etcd isn't managed by the operator.
+func (reconciler *ResourceReconciler) etcdQuorumReady(ctx context.Context) (bool, error) {
+	if !reconciler.Instance.ManagedEtcd() {
+		return true, nil
+	}
+
+	sts := &appsv1.StatefulSet{}
+	sts.Name = reconciler.Instance.ManagedEtcdName()
+	sts.Namespace = reconciler.Instance.Namespace
+	if err := reconciler.GetItem(ctx, sts); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	quorum := reconciler.Instance.Spec.Etcd.MemberCount()/2 + 1
+
+	return sts.Status.ReadyReplicas >= quorum, nil
+}
+
+// deleteCluster removes the managed etcd cluster after spec.etcd.managed has been turned off.
+// The volumes are kept like for any StatefulSet. Only objects controlled by the instance are deleted,
+// objects with the same names written by hand are left alone.
+func (b *EtcdReconciler) deleteCluster(ctx context.Context) error {
+	for _, obj := range []client.Object{b.newStatefulSet(), b.newService(), b.newSecret(), b.newClientSecret()} {
+		if obj.GetName() == "" {
+			continue
+		}
+		err := b.GetItem(ctx, obj)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !metav1.IsControlledBy(obj, b.Instance) {
+			continue
+		}
+
+		b.Logger.Info("Managed etcd removed from spec, deleting", "name", obj.GetName())
+		if err := b.Client.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *EtcdReconciler) newSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.Instance.ManagedEtcdName() + "-tls",
+			Namespace: b.Instance.Namespace,
+			Labels:    utils.EtcdLabelsForLavinMQ(b.Instance),
+		},
+		Type: corev1.SecretTypeTLS,
+	}
+}
+
+// newClientSecret returns the generated client certificate Secret, by its fixed name as spec.etcd.tlsSecretRef
+// names a Secret of the user when etcd isn't managed.
+func (b *EtcdReconciler) newClientSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.Instance.ManagedEtcdClientSecretName(),
+			Namespace: b.Instance.Namespace,
+			Labels:    utils.EtcdLabelsForLavinMQ(b.Instance),
+		},
+		Type: corev1.SecretTypeTLS,
+	}
+}
+
+// reconcileSecret generates the server and client certificates once. The server certificate is shared by all
+// members and acts as its own CA, the members only trust the client certificate for client connections.
+// Secrets created before clients had to authenticate get the client certificate added.
+func (b *EtcdReconciler) reconcileSecret(ctx context.Context) error {
+	secret, err := b.ensureCertificate(ctx, b.newSecret(), b.Instance.ManagedEtcdName(), b.peerDNSNames())
+	if err != nil {
+		return fmt.Errorf("failed to generate etcd peer certificate: %w", err)
+	}
+
+	clientSecret, err := b.ensureCertificate(ctx, b.newClientSecret(), b.Instance.ManagedEtcdName()+"-client", nil)
+	if err != nil {
+		return fmt.Errorf("failed to generate etcd client certificate: %w", err)
+	}
+
+	if !bytes.Equal(clientSecret.Data["ca.crt"], secret.Data[corev1.TLSCertKey]) {
+		clientSecret.Data["ca.crt"] = secret.Data[corev1.TLSCertKey]
+		if err := b.Client.Update(ctx, clientSecret); err != nil {
+			return err
+		}
+	}
+
+	if !bytes.Equal(secret.Data[etcdClientCAKey], clientSecret.Data[corev1.TLSCertKey]) {
+		secret.Data[etcdClientCAKey] = clientSecret.Data[corev1.TLSCertKey]
+		if err := b.Client.Update(ctx, secret); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ensureCertificate returns the secret, creating it with a newly generated self-signed certificate if missing.
+func (b *EtcdReconciler) ensureCertificate(ctx context.Context, secret *corev1.Secret, commonName string, dnsNames []string) (*corev1.Secret, error) {
+	err := b.GetItem(ctx, secret)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return secret, err
+	}
+
+	cert, key, err := resource_utils.GenerateSelfSignedCertificate(commonName, dnsNames, etcdPeerCertificateValidity)
+	if err != nil {
+		return nil, err
+	}
+
+	secret.Data = map[string][]byte{
+		"ca.crt":                cert,
+		corev1.TLSCertKey:       cert,
+		corev1.TLSPrivateKeyKey: key,
+	}
+
+	return secret, b.CreateItem(ctx, secret)
+}
+
+func (b *EtcdReconciler) peerDNSNames() []string {
+	name := b.Instance.ManagedEtcdName()
+	namespace := b.Instance.Namespace
+
+	return []string{
+		fmt.Sprintf("*.%s.%s.svc.cluster.local", name, namespace),
+		fmt.Sprintf("*.%s.%s.svc", name, namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", name, namespace),
+		fmt.Sprintf("%s.%s.svc", name, namespace),
+		name,
+	}
+}
+
+func (b *EtcdReconciler) newService() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.Instance.ManagedEtcdName(),
+			Namespace: b.Instance.Namespace,
+			Labels:    utils.EtcdLabelsForLavinMQ(b.Instance),
+		},
+		Spec: corev1.ServiceSpec{
+			Selector:  utils.EtcdSelectorLabelsForLavinMQ(b.Instance),
+			ClusterIP: "None",
+			Ports: []corev1.ServicePort{
+				{Name: "client", Port: etcdClientPort, TargetPort: intstr.FromInt32(etcdClientPort), Protocol: corev1.ProtocolTCP},
+				{Name: "peer", Port: etcdPeerPort, TargetPort: intstr.FromInt32(etcdPeerPort), Protocol: corev1.ProtocolTCP},
+			},
+			// Members have to find each other to bootstrap the cluster, before any of them is ready.
+			PublishNotReadyAddresses: true,
+		},
+	}
+}
+
+func (b *EtcdReconciler) reconcileService(ctx context.Context) error {
+	service := b.newService()
+
+	err := b.GetItem(ctx, service)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+
+		return b.CreateItem(ctx, service)
+	}
+
+	newService := b.newService()
+	if !maps.Equal(service.Labels, newService.Labels) {
+		service.Labels = newService.Labels
+	}
+
+	if !maps.Equal(service.Spec.Selector, newService.Spec.Selector) {
+		service.Spec.Selector = newService.Spec.Selector
+	}
+
+	if !reflect.DeepEqual(service.Spec.Ports, newService.Spec.Ports) {
+		service.Spec.Ports = newService.Spec.Ports
+	}
+
+	service.Spec.PublishNotReadyAddresses = newService.Spec.PublishNotReadyAddresses
+
+	return b.Client.Update(ctx, service)
+}
+
+func (b *EtcdReconciler) newStatefulSet() *appsv1.StatefulSet {
+	name := b.Instance.ManagedEtcdName()
+	spec := b.Instance.Spec.Etcd
+	replicas := int32(0)
+	image := ""
+	resources := corev1.ResourceRequirements{}
+	if spec != nil {
+		replicas = spec.MemberCount()
+		image = spec.Image
+		resources = spec.Resources
+	}
+
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: b.Instance.Namespace,
+			Labels:    utils.EtcdLabelsForLavinMQ(b.Instance),
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			ServiceName: name,
+			// All members have to be started for the initial cluster to form.
+			PodManagementPolicy: appsv1.ParallelPodManagement,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: utils.EtcdSelectorLabelsForLavinMQ(b.Instance),
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: utils.EtcdLabelsForLavinMQ(b.Instance),
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:      "etcd",
+							Image:     image,
+							Resources: resources,
+							Command:   b.etcdCommand(),
+							Ports: []corev1.ContainerPort{
+								{Name: "client", ContainerPort: etcdClientPort, Protocol: corev1.ProtocolTCP},
+								{Name: "peer", ContainerPort: etcdPeerPort, Protocol: corev1.ProtocolTCP},
+								{Name: "metrics", ContainerPort: etcdMetricsPort, Protocol: corev1.ProtocolTCP},
+							},
+							Env: []corev1.EnvVar{
+								{
+									Name: "POD_NAME",
+									ValueFrom: &corev1.EnvVarSource{
+										FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+									},
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "data", MountPath: "/var/lib/etcd"},
+								{Name: "tls", MountPath: etcdTlsMountPath, ReadOnly: true},
+							},
+							// The client port requires a client certificate, the health endpoint is also served on the
+							// plain metrics port.
+							ReadinessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									HTTPGet: &corev1.HTTPGetAction{
+										Path: "/health",
+										Port: intstr.FromInt32(etcdMetricsPort),
+									},
+								},
+								PeriodSeconds: 10,
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "tls",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{SecretName: b.newSecret().Name},
+							},
+						},
+					},
+					Affinity:          b.Instance.Spec.Affinity,
+					Tolerations:       b.Instance.Spec.Tolerations,
+					NodeSelector:      b.Instance.Spec.NodeSelector,
+					PriorityClassName: b.Instance.Spec.PriorityClassName,
+					ImagePullSecrets:  b.Instance.Spec.ImagePullSecrets,
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "data",
+						Namespace: b.Instance.Namespace,
+					},
+					Spec: b.dataVolumeClaimSpec(),
+				},
+			},
+		},
+	}
+}
+
+func (b *EtcdReconciler) dataVolumeClaimSpec() corev1.PersistentVolumeClaimSpec {
+	if spec := b.Instance.Spec.Etcd; spec != nil && spec.DataVolumeClaimSpec != nil {
+		return *spec.DataVolumeClaimSpec
+	}
+
+	return corev1.PersistentVolumeClaimSpec{
+		AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+		Resources: corev1.VolumeResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceStorage: resource.MustParse("1Gi"),
+			},
+		},
+	}
+}
+
+// etcdCommand bootstraps a static cluster, the members are known up front from the StatefulSet ordinals.
+func (b *EtcdReconciler) etcdCommand() []string {
+	name := b.Instance.ManagedEtcdName()
+	domain := fmt.Sprintf("%s.%s.svc.cluster.local", name, b.Instance.Namespace)
+
+	members := []string{}
+	if b.Instance.Spec.Etcd != nil {
+		for i := range b.Instance.Spec.Etcd.MemberCount() {
+			member := fmt.Sprintf("%s-%d", name, i)
+			members = append(members, fmt.Sprintf("%s=https://%s.%s:%d", member, member, domain, etcdPeerPort))
+		}
+	}
+
+	return []string{
+		"/usr/local/bin/etcd",
+		"--name=$(POD_NAME)",
+		"--data-dir=/var/lib/etcd",
+		fmt.Sprintf("--listen-client-urls=https://0.0.0.0:%d", etcdClientPort),
+		fmt.Sprintf("--advertise-client-urls=https://$(POD_NAME).%s:%d", domain, etcdClientPort),
+		fmt.Sprintf("--listen-metrics-urls=http://0.0.0.0:%d", etcdMetricsPort),
+		"--client-cert-auth",
+		"--trusted-ca-file=" + etcdTlsMountPath + "/" + etcdClientCAKey,
+		"--cert-file=" + etcdTlsMountPath + "/" + corev1.TLSCertKey,
+		"--key-file=" + etcdTlsMountPath + "/" + corev1.TLSPrivateKeyKey,
+		fmt.Sprintf("--listen-peer-urls=https://0.0.0.0:%d", etcdPeerPort),
+		fmt.Sprintf("--initial-advertise-peer-urls=https://$(POD_NAME).%s:%d", domain, etcdPeerPort),
+		"--initial-cluster=" + strings.Join(members, ","),
+		"--initial-cluster-state=new",
+		fmt.Sprintf("--initial-cluster-token=%s-%s", b.Instance.Namespace, name),
+		"--peer-client-cert-auth",
+		"--peer-trusted-ca-file=" + etcdTlsMountPath + "/ca.crt",
+		"--peer-cert-file=" + etcdTlsMountPath + "/" + corev1.TLSCertKey,
+		"--peer-key-file=" + etcdTlsMountPath + "/" + corev1.TLSPrivateKeyKey,
+	}
+}
+
+func (b *EtcdReconciler) reconcileStatefulSet(ctx context.Context) error {
+	sts := b.newStatefulSet()
+
+	err := b.GetItem(ctx, sts)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+
+		return b.CreateItem(ctx, sts)
+	}
+
+	desired := b.newStatefulSet()
+	if !maps.Equal(sts.Labels, desired.Labels) {
+		sts.Labels = desired.Labels
+	}
+
+	// The number of members is fixed once the cluster is bootstrapped, only the pod template follows the spec.
+	sts.Spec.Template = desired.Spec.Template
+
+	return b.Client.Update(ctx, sts)
+}
+
+// Name returns the name of the etcd reconciler
+func (b *EtcdReconciler) Name() string {
+	return "etcd"
+}
@@ -0,0 +1,160 @@
+package reconciler_test
+
+import (
+	"testing"
+
+	"github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
+	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestNoEtcdByDefault(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	rc := &reconciler.EtcdReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	result, err := rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.False(t, result.Requeue)
+
+	sts := &appsv1.StatefulSet{}
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.ManagedEtcdName(), Namespace: instance.Namespace}, sts)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestUnmanagedEtcdClientSecretKept(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+
+	instance.Spec.EtcdEndpoints = []string{"https://etcd.example.com:2379"}
+	instance.Spec.Etcd = &v1alpha1.EtcdSpec{
+		Managed:      false,
+		TlsSecretRef: &corev1.LocalObjectReference{Name: "etcd-client"},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "etcd-client", Namespace: instance.Namespace},
+		StringData: map[string]string{"ca.crt": "ca", "tls.crt": "cert", "tls.key": "key"},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), secret))
+
+	rc := &reconciler.EtcdReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: "etcd-client", Namespace: instance.Namespace}, secret)
+	assert.NoError(t, err, "Expected the client certificate Secret of the user to be kept")
+}
+
+func TestManagedEtcd(t *testing.T) {
+	t.Parallel()
+	replicas := int32(3)
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{Replicas: &replicas})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+
+	instance.Spec.Etcd = &v1alpha1.EtcdSpec{Managed: true}
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	rc := &reconciler.EtcdReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	t.Log("Waiting for quorum until the members are ready")
+	result, err := rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+
+	name := types.NamespacedName{Name: instance.ManagedEtcdName(), Namespace: instance.Namespace}
+
+	service := &corev1.Service{}
+	assert.NoError(t, k8sClient.Get(t.Context(), name, service))
+	assert.Equal(t, "None", service.Spec.ClusterIP)
+	assert.Equal(t, "etcd", service.Spec.Selector["app.kubernetes.io/name"])
+
+	secret := &corev1.Secret{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: name.Name + "-tls", Namespace: name.Namespace}, secret))
+	assert.NotEmpty(t, secret.Data["ca.crt"])
+	assert.NotEmpty(t, secret.Data["tls.key"])
+
+	t.Log("Clients have to authenticate with the generated client certificate")
+	clientSecret := &corev1.Secret{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.EtcdTlsSecretName(), Namespace: name.Namespace}, clientSecret))
+	assert.Equal(t, secret.Data["tls.crt"], clientSecret.Data["ca.crt"])
+	assert.Equal(t, clientSecret.Data["tls.crt"], secret.Data["client-ca.crt"])
+
+	sts := &appsv1.StatefulSet{}
+	assert.NoError(t, k8sClient.Get(t.Context(), name, sts))
+	assert.Equal(t, int32(3), *sts.Spec.Replicas)
+	assert.Equal(t, appsv1.ParallelPodManagement, sts.Spec.PodManagementPolicy)
+	assert.Contains(t, sts.Spec.Template.Spec.Containers[0].Command, "--peer-client-cert-auth")
+	assert.Contains(t, sts.Spec.Template.Spec.Containers[0].Command, "--client-cert-auth")
+
+	t.Log("The endpoints of the members are used for clustering")
+	assert.Len(t, instance.EtcdEndpoints(), 3)
+	assert.Equal(t, "https://"+name.Name+"-0."+name.Name+"."+name.Namespace+".svc.cluster.local:2379", instance.EtcdEndpoints()[0])
+
+	t.Log("The LavinMQ StatefulSet isn't created before quorum")
+	stsRc := &reconciler.StatefulSetReconciler{ResourceReconciler: rc.ResourceReconciler}
+	result, err = stsRc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, &appsv1.StatefulSet{})
+	assert.True(t, apierrors.IsNotFound(err))
+
+	t.Log("Quorum is reached with two of three members ready")
+	sts.Status.Replicas = 3
+	sts.Status.ReadyReplicas = 2
+	assert.NoError(t, k8sClient.Status().Update(t.Context(), sts))
+
+	result, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.False(t, result.Requeue)
+
+	result, err = stsRc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.False(t, result.Requeue)
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, &appsv1.StatefulSet{}))
+}
@@ -0,0 +1,160 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/cloudamqp/lavinmq-operator/internal/controller/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// ExternalServiceReconciler owns the LoadBalancer or NodePort Service configured through spec.service.
+// Like the leader service it only routes to the current leader.
+type ExternalServiceReconciler struct {
+	*ResourceReconciler
+}
+
+func (reconciler *ResourceReconciler) ExternalServiceReconciler() *ExternalServiceReconciler {
+	return &ExternalServiceReconciler{
+		ResourceReconciler: reconciler,
+	}
+}
+
+func (b *ExternalServiceReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	service := b.newObject()
+
+	err := b.GetItem(ctx, service)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+
+		if b.Instance.Spec.Service == nil {
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, b.CreateItem(ctx, service)
+	}
+
+	if b.Instance.Spec.Service == nil {
+		// A Service with the same name written by hand is left alone.
+		if !metav1.IsControlledBy(service, b.Instance) {
+			return ctrl.Result{}, nil
+		}
+
+		b.Logger.Info("External service removed from spec, deleting", "name", service.Name)
+		if err := b.Client.Delete(ctx, service); err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Taking over a Service written by hand would silently change where its clients are routed, the
+	// error reports the conflict in the Degraded condition instead.
+	if !metav1.IsControlledBy(service, b.Instance) {
+		return ctrl.Result{}, fmt.Errorf("service %s exists and isn't controlled by LavinMQ %s, refusing to take it over", service.Name, b.Instance.Name)
+	}
+
+	b.updateFields(ctx, service)
+
+	err = b.Client.Update(ctx, service)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (b *ExternalServiceReconciler) newObject() *corev1.Service {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.Instance.Name + "-external",
+			Namespace: b.Instance.Namespace,
+			Labels:    utils.LabelsForLavinMQ(b.Instance),
+		},
+	}
+
+	spec := b.Instance.Spec.Service
+	if spec == nil {
+		return service
+	}
+
+	ports := b.clientServicePorts()
+	for i := range ports {
+		ports[i].NodePort = spec.NodePorts.ForPort(ports[i].Name)
+	}
+
+	service.Annotations = spec.Annotations
+	service.Spec = corev1.ServiceSpec{
+		Type:                     spec.Type,
+		Selector:                 utils.LeaderSelectorLabelsForLavinMQ(b.Instance),
+		Ports:                    ports,
+		LoadBalancerSourceRanges: spec.LoadBalancerSourceRanges,
+		ExternalTrafficPolicy:    spec.ExternalTrafficPolicy,
+	}
+
+	if service.Spec.Type == "" {
+		service.Spec.Type = corev1.ServiceTypeLoadBalancer
+	}
+
+	return service
+}
+
+func (b *ExternalServiceReconciler) updateFields(_ context.Context, service *corev1.Service) {
+	newService := b.newObject()
+
+	if !maps.Equal(service.Labels, newService.Labels) {
+		service.Labels = newService.Labels
+	}
+
+	if !maps.Equal(service.Annotations, newService.Annotations) {
+		b.Logger.Info("External service annotations changed, updating")
+		service.Annotations = newService.Annotations
+	}
+
+	if service.Spec.Type != newService.Spec.Type {
+		b.Logger.Info("External service type changed, updating", "old", service.Spec.Type, "new", newService.Spec.Type)
+		service.Spec.Type = newService.Spec.Type
+	}
+
+	if !maps.Equal(service.Spec.Selector, newService.Spec.Selector) {
+		service.Spec.Selector = newService.Spec.Selector
+	}
+
+	if !slices.Equal(service.Spec.LoadBalancerSourceRanges, newService.Spec.LoadBalancerSourceRanges) {
+		service.Spec.LoadBalancerSourceRanges = newService.Spec.LoadBalancerSourceRanges
+	}
+
+	// Unset means the default chosen by Kubernetes
+	if newService.Spec.ExternalTrafficPolicy != "" && service.Spec.ExternalTrafficPolicy != newService.Spec.ExternalTrafficPolicy {
+		service.Spec.ExternalTrafficPolicy = newService.Spec.ExternalTrafficPolicy
+	}
+
+	// Keep node ports allocated by Kubernetes for the ports without a fixed one
+	for i, port := range newService.Spec.Ports {
+		if port.NodePort != 0 {
+			continue
+		}
+		idx := slices.IndexFunc(service.Spec.Ports, func(p corev1.ServicePort) bool { return p.Name == port.Name })
+		if idx != -1 {
+			newService.Spec.Ports[i].NodePort = service.Spec.Ports[idx].NodePort
+		}
+	}
+
+	if !reflect.DeepEqual(service.Spec.Ports, newService.Spec.Ports) {
+		b.Logger.Info("External service ports changed, updating")
+		service.Spec.Ports = newService.Spec.Ports
+	}
+}
+
+// Name returns the name of the external service reconciler
+func (b *ExternalServiceReconciler) Name() string {
+	return "external-service"
+}
@@ -0,0 +1,183 @@
+package reconciler_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/controller/utils"
+	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
+	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestNoExternalServiceByDefault(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	rc := &reconciler.ExternalServiceReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	service := &corev1.Service{}
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name + "-external", Namespace: instance.Namespace}, service)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestExternalNodePortService(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+
+	instance.Spec.Service = &v1alpha1.ServiceSpec{
+		Type:                  corev1.ServiceTypeNodePort,
+		Annotations:           map[string]string{"example.com/team": "messaging"},
+		ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyLocal,
+		NodePorts:             v1alpha1.NodePorts{Amqp: 30672},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	rc := &reconciler.ExternalServiceReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	service := &corev1.Service{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name + "-external", Namespace: instance.Namespace}, service))
+	assert.Equal(t, corev1.ServiceTypeNodePort, service.Spec.Type)
+	assert.Equal(t, "messaging", service.Annotations["example.com/team"])
+	assert.Equal(t, corev1.ServiceExternalTrafficPolicyLocal, service.Spec.ExternalTrafficPolicy)
+	assert.Equal(t, utils.LeaderSelectorLabelsForLavinMQ(instance), service.Spec.Selector)
+	assert.Len(t, service.Spec.Ports, 3)
+
+	amqp := slices.IndexFunc(service.Spec.Ports, func(port corev1.ServicePort) bool { return port.Name == "amqp" })
+	assert.Equal(t, int32(30672), service.Spec.Ports[amqp].NodePort)
+	mqtt := slices.IndexFunc(service.Spec.Ports, func(port corev1.ServicePort) bool { return port.Name == "mqtt" })
+	allocated := service.Spec.Ports[mqtt].NodePort
+	assert.NotZero(t, allocated)
+
+	t.Log("Reconciling again keeps the allocated node ports")
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name + "-external", Namespace: instance.Namespace}, service))
+	mqtt = slices.IndexFunc(service.Spec.Ports, func(port corev1.ServicePort) bool { return port.Name == "mqtt" })
+	assert.Equal(t, allocated, service.Spec.Ports[mqtt].NodePort)
+}
+
+func TestExternalServiceRemoved(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+
+	instance.Spec.Service = &v1alpha1.ServiceSpec{
+		Type:                     corev1.ServiceTypeLoadBalancer,
+		LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	rc := &reconciler.ExternalServiceReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	service := &corev1.Service{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name + "-external", Namespace: instance.Namespace}, service))
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, service.Spec.Type)
+	assert.Equal(t, []string{"10.0.0.0/8"}, service.Spec.LoadBalancerSourceRanges)
+
+	instance.Spec.Service = nil
+	assert.NoError(t, k8sClient.Update(t.Context(), instance))
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name + "-external", Namespace: instance.Namespace}, service)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestExternalServiceNotOwnedKept(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.Name + "-external",
+			Namespace: instance.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Type:  corev1.ServiceTypeLoadBalancer,
+			Ports: []corev1.ServicePort{{Name: "amqp", Port: 5672}},
+		},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), service))
+
+	rc := &reconciler.ExternalServiceReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name + "-external", Namespace: instance.Namespace}, service)
+	assert.NoError(t, err, "Expected a Service not created by the operator to be kept")
+
+	t.Log("Nor is it taken over once spec.service is set")
+	instance.Spec.Service = &v1alpha1.ServiceSpec{Type: corev1.ServiceTypeNodePort}
+	_, err = rc.Reconcile(t.Context())
+	assert.ErrorContains(t, err, "refusing to take it over")
+
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name + "-external", Namespace: instance.Namespace}, service)
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, service.Spec.Type)
+	assert.Empty(t, service.OwnerReferences)
+}
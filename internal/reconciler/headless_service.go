@@ -2,6 +2,7 @@ package reconciler
 
 import (
 	"context"
+	"maps"
 	"reflect"
 
 	"github.com/cloudamqp/lavinmq-operator/internal/controller/utils"
@@ -48,48 +49,58 @@ func (b *HeadlessServiceReconciler) Reconcile(ctx context.Context) (ctrl.Result,
 
 func (b *HeadlessServiceReconciler) newObject() *corev1.Service {
 	servicePorts := []corev1.ServicePort{}
-	if b.Instance.Spec.EtcdEndpoints != nil {
+	if b.Instance.EtcdEndpoints() != nil {
 		servicePorts = appendServicePorts(servicePorts, 5679, "clustering")
 	}
+	servicePorts = append(servicePorts, b.clientServicePorts()...)
 
-	if b.Instance.Spec.Config.Mgmt.Port > 0 {
-		servicePorts = appendServicePorts(servicePorts, b.Instance.Spec.Config.Mgmt.Port, "http")
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.Instance.Name,
+			Namespace: b.Instance.Namespace,
+			Labels:    utils.LabelsForLavinMQ(b.Instance),
+		},
+		Spec: corev1.ServiceSpec{
+			Selector:  utils.SelectorLabelsForLavinMQ(b.Instance),
+			ClusterIP: "None",
+			Ports:     servicePorts,
+			// Peers have to be able to resolve each other before they're ready, clustering
+			// and leader election happen before the readiness probe passes.
+			PublishNotReadyAddresses: true,
+		},
 	}
 
-	if b.Instance.Spec.Config.Mgmt.TlsPort != 0 {
-		servicePorts = appendServicePorts(servicePorts, b.Instance.Spec.Config.Mgmt.TlsPort, "https")
+	return service
+}
+
+// clientServicePorts returns the enabled client facing ports, shared by all services exposing the instance.
+func (reconciler *ResourceReconciler) clientServicePorts() []corev1.ServicePort {
+	servicePorts := []corev1.ServicePort{}
+	if reconciler.Instance.Spec.Config.Mgmt.Port > 0 {
+		servicePorts = appendServicePorts(servicePorts, reconciler.Instance.Spec.Config.Mgmt.Port, "http")
 	}
 
-	if b.Instance.Spec.Config.Amqp.Port > 0 {
-		servicePorts = appendServicePorts(servicePorts, b.Instance.Spec.Config.Amqp.Port, "amqp")
+	if reconciler.Instance.Spec.Config.Mgmt.TlsPort != 0 {
+		servicePorts = appendServicePorts(servicePorts, reconciler.Instance.Spec.Config.Mgmt.TlsPort, "https")
 	}
 
-	if b.Instance.Spec.Config.Amqp.TlsPort != 0 {
-		servicePorts = appendServicePorts(servicePorts, b.Instance.Spec.Config.Amqp.TlsPort, "amqps")
+	if reconciler.Instance.Spec.Config.Amqp.Port > 0 {
+		servicePorts = appendServicePorts(servicePorts, reconciler.Instance.Spec.Config.Amqp.Port, "amqp")
 	}
 
-	if b.Instance.Spec.Config.Mqtt.Port > 0 {
-		servicePorts = appendServicePorts(servicePorts, b.Instance.Spec.Config.Mqtt.Port, "mqtt")
+	if reconciler.Instance.Spec.Config.Amqp.TlsPort != 0 {
+		servicePorts = appendServicePorts(servicePorts, reconciler.Instance.Spec.Config.Amqp.TlsPort, "amqps")
 	}
 
-	if b.Instance.Spec.Config.Mqtt.TlsPort != 0 {
-		servicePorts = appendServicePorts(servicePorts, b.Instance.Spec.Config.Mqtt.TlsPort, "mqtts")
+	if reconciler.Instance.Spec.Config.Mqtt.Port > 0 {
+		servicePorts = appendServicePorts(servicePorts, reconciler.Instance.Spec.Config.Mqtt.Port, "mqtt")
 	}
 
-	service := &corev1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      b.Instance.Name,
-			Namespace: b.Instance.Namespace,
-			Labels:    utils.LabelsForLavinMQ(b.Instance),
-		},
-		Spec: corev1.ServiceSpec{
-			Selector:  b.Instance.Labels,
-			ClusterIP: "None",
-			Ports:     servicePorts,
-		},
+	if reconciler.Instance.Spec.Config.Mqtt.TlsPort != 0 {
+		servicePorts = appendServicePorts(servicePorts, reconciler.Instance.Spec.Config.Mqtt.TlsPort, "mqtts")
 	}
 
-	return service
+	return servicePorts
 }
 
 func appendServicePorts(servicePorts []corev1.ServicePort, port int32, name string) []corev1.ServicePort {
@@ -105,6 +116,26 @@ func appendServicePorts(servicePorts []corev1.ServicePort, port int32, name stri
 func (b *HeadlessServiceReconciler) updateFields(ctx context.Context, service *corev1.Service) {
 	newService := b.newObject()
 
+	if !maps.Equal(service.Labels, newService.Labels) {
+		b.Logger.Info("Service labels changed, updating")
+		service.Labels = newService.Labels
+	}
+
+	if !maps.Equal(service.Annotations, newService.Annotations) {
+		b.Logger.Info("Service annotations changed, updating")
+		service.Annotations = newService.Annotations
+	}
+
+	if !maps.Equal(service.Spec.Selector, newService.Spec.Selector) {
+		b.Logger.Info("Service selector changed, updating")
+		service.Spec.Selector = newService.Spec.Selector
+	}
+
+	if service.Spec.PublishNotReadyAddresses != newService.Spec.PublishNotReadyAddresses {
+		b.Logger.Info("Service publishNotReadyAddresses changed, updating")
+		service.Spec.PublishNotReadyAddresses = newService.Spec.PublishNotReadyAddresses
+	}
+
 	if !reflect.DeepEqual(service.Spec.Ports, newService.Spec.Ports) {
 		service.Spec.Ports = newService.Spec.Ports
 	}
@@ -4,6 +4,7 @@ import (
 	"slices"
 	"testing"
 
+	"github.com/cloudamqp/lavinmq-operator/internal/controller/utils"
 	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
 	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
 
@@ -170,3 +171,78 @@ func TestPortChanges(t *testing.T) {
 	})
 	assert.Equal(t, int32(1111), service.Spec.Ports[idx].Port)
 }
+
+func TestHeadlessServiceSelector(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	instance.Labels = map[string]string{"team": "messaging"}
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	rc := &reconciler.HeadlessServiceReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	service := &corev1.Service{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, service))
+	assert.Equal(t, utils.SelectorLabelsForLavinMQ(instance), service.Spec.Selector)
+	assert.True(t, service.Spec.PublishNotReadyAddresses)
+	assert.Equal(t, "messaging", service.Labels["team"])
+}
+
+func TestHeadlessServiceDriftRepaired(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	rc := &reconciler.HeadlessServiceReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	service := &corev1.Service{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, service))
+	expected := service.DeepCopy()
+
+	t.Log("Editing the service by hand")
+	service.Labels = map[string]string{"edited": "true"}
+	service.Annotations = map[string]string{"edited": "true"}
+	service.Spec.Selector = map[string]string{"app": "something-else"}
+	service.Spec.PublishNotReadyAddresses = false
+	service.Spec.Ports = service.Spec.Ports[:1]
+	assert.NoError(t, k8sClient.Update(t.Context(), service))
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, service))
+	assert.Equal(t, expected.Labels, service.Labels)
+	assert.Empty(t, service.Annotations)
+	assert.Equal(t, expected.Spec.Selector, service.Spec.Selector)
+	assert.True(t, service.Spec.PublishNotReadyAddresses)
+	assert.Equal(t, expected.Spec.Ports, service.Spec.Ports)
+}
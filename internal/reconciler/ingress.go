@@ -0,0 +1,284 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"reflect"
+
+	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/controller/utils"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The Gateway API is an optional CRD, HTTPRoutes are handled as unstructured objects
+// so the operator doesn't depend on it being installed.
+var httpRouteGVK = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "HTTPRoute"}
+
+// IngressReconciler routes HTTP traffic to the management interface of the leader, either
+// through an Ingress or a Gateway API HTTPRoute as configured in spec.ingress.
+type IngressReconciler struct {
+	*ResourceReconciler
+}
+
+func (reconciler *ResourceReconciler) IngressReconciler() *IngressReconciler {
+	return &IngressReconciler{
+		ResourceReconciler: reconciler,
+	}
+}
+
+func (b *IngressReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	kind := b.kind()
+
+	// Remove whichever object isn't asked for, also covers switching between the kinds.
+	if kind != cloudamqpcomv1alpha1.IngressKindIngress {
+		if err := b.deleteIfExists(ctx, b.newIngress()); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if kind != cloudamqpcomv1alpha1.IngressKindHTTPRoute {
+		if err := b.deleteIfExists(ctx, b.newHTTPRoute()); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	switch kind {
+	case cloudamqpcomv1alpha1.IngressKindIngress:
+		return ctrl.Result{}, b.reconcileIngress(ctx)
+	case cloudamqpcomv1alpha1.IngressKindHTTPRoute:
+		return ctrl.Result{}, b.reconcileHTTPRoute(ctx)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// kind returns the kind of object requested, empty if spec.ingress isn't set.
+func (b *IngressReconciler) kind() cloudamqpcomv1alpha1.IngressKind {
+	spec := b.Instance.Spec.Ingress
+	if spec == nil {
+		return ""
+	}
+
+	if spec.Kind == "" {
+		return cloudamqpcomv1alpha1.IngressKindIngress
+	}
+
+	return spec.Kind
+}
+
+func (b *IngressReconciler) reconcileIngress(ctx context.Context) error {
+	port, err := b.mgmtPort()
+	if err != nil {
+		return err
+	}
+
+	ingress := b.newIngress()
+	err = b.GetItem(ctx, ingress)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+
+		b.updateIngressFields(ingress, port)
+		return b.CreateItem(ctx, ingress)
+	}
+
+	b.updateIngressFields(ingress, port)
+
+	return b.Client.Update(ctx, ingress)
+}
+
+func (b *IngressReconciler) reconcileHTTPRoute(ctx context.Context) error {
+	port, err := b.mgmtPort()
+	if err != nil {
+		return err
+	}
+
+	if len(b.Instance.Spec.Ingress.ParentRefs) == 0 {
+		return errors.New("spec.ingress.parentRefs is required for HTTPRoutes")
+	}
+
+	route := b.newHTTPRoute()
+	err = b.GetItem(ctx, route)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+
+		if err := b.updateHTTPRouteFields(route, port); err != nil {
+			return err
+		}
+		return b.CreateItem(ctx, route)
+	}
+
+	if err := b.updateHTTPRouteFields(route, port); err != nil {
+		return err
+	}
+
+	return b.Client.Update(ctx, route)
+}
+
+func (b *IngressReconciler) deleteIfExists(ctx context.Context, obj client.Object) error {
+	err := b.GetItem(ctx, obj)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Objects with the same name written by hand are left alone.
+	if !metav1.IsControlledBy(obj, b.Instance) {
+		return nil
+	}
+
+	b.Logger.Info("Ingress removed from spec, deleting", "name", obj.GetName(), "kind", obj.GetObjectKind().GroupVersionKind().Kind)
+	if err := b.Client.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// mgmtPort returns the management port of the leader service the traffic is routed to,
+// the plain HTTP port is preferred so TLS can be terminated by the ingress controller.
+func (b *IngressReconciler) mgmtPort() (int32, error) {
+	mgmt := b.Instance.Spec.Config.Mgmt
+	if mgmt.Port > 0 {
+		return mgmt.Port, nil
+	}
+
+	if mgmt.TlsPort != 0 {
+		return mgmt.TlsPort, nil
+	}
+
+	return 0, errors.New("spec.ingress requires the management interface to be enabled")
+}
+
+func (b *IngressReconciler) newIngress() *networkingv1.Ingress {
+	return &networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.Instance.Name + "-mgmt",
+			Namespace: b.Instance.Namespace,
+		},
+	}
+}
+
+func (b *IngressReconciler) updateIngressFields(ingress *networkingv1.Ingress, port int32) {
+	spec := b.Instance.Spec.Ingress
+
+	pathType := networkingv1.PathTypePrefix
+	backend := networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{
+			Name: b.Instance.Name + "-leader",
+			Port: networkingv1.ServiceBackendPort{Number: port},
+		},
+	}
+
+	rules := make([]networkingv1.IngressRule, 0, len(spec.Hostnames))
+	for _, host := range spec.Hostnames {
+		rules = append(rules, networkingv1.IngressRule{
+			Host: host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{
+						Path:     "/",
+						PathType: &pathType,
+						Backend:  backend,
+					}},
+				},
+			},
+		})
+	}
+
+	var tls []networkingv1.IngressTLS
+	if spec.TlsSecretName != "" {
+		tls = []networkingv1.IngressTLS{{Hosts: spec.Hostnames, SecretName: spec.TlsSecretName}}
+	}
+
+	labels := utils.LabelsForLavinMQ(b.Instance)
+	if !maps.Equal(ingress.Labels, labels) {
+		ingress.Labels = labels
+	}
+
+	if !maps.Equal(ingress.Annotations, spec.Annotations) {
+		b.Logger.Info("Ingress annotations changed, updating")
+		ingress.Annotations = spec.Annotations
+	}
+
+	if !reflect.DeepEqual(ingress.Spec.IngressClassName, spec.IngressClassName) {
+		ingress.Spec.IngressClassName = spec.IngressClassName
+	}
+
+	if !reflect.DeepEqual(ingress.Spec.Rules, rules) {
+		b.Logger.Info("Ingress rules changed, updating")
+		ingress.Spec.Rules = rules
+	}
+
+	if !reflect.DeepEqual(ingress.Spec.TLS, tls) {
+		ingress.Spec.TLS = tls
+	}
+}
+
+func (b *IngressReconciler) newHTTPRoute() *unstructured.Unstructured {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(httpRouteGVK)
+	route.SetName(b.Instance.Name + "-mgmt")
+	route.SetNamespace(b.Instance.Namespace)
+
+	return route
+}
+
+func (b *IngressReconciler) updateHTTPRouteFields(route *unstructured.Unstructured, port int32) error {
+	spec := b.Instance.Spec.Ingress
+
+	parentRefs := make([]any, 0, len(spec.ParentRefs))
+	for _, ref := range spec.ParentRefs {
+		parentRef := map[string]any{"name": ref.Name}
+		if ref.Namespace != "" {
+			parentRef["namespace"] = ref.Namespace
+		}
+		if ref.SectionName != "" {
+			parentRef["sectionName"] = ref.SectionName
+		}
+		parentRefs = append(parentRefs, parentRef)
+	}
+
+	hostnames := make([]any, 0, len(spec.Hostnames))
+	for _, host := range spec.Hostnames {
+		hostnames = append(hostnames, host)
+	}
+
+	route.SetLabels(utils.LabelsForLavinMQ(b.Instance))
+	route.SetAnnotations(spec.Annotations)
+	if err := unstructured.SetNestedSlice(route.Object, parentRefs, "spec", "parentRefs"); err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedSlice(route.Object, hostnames, "spec", "hostnames"); err != nil {
+		return err
+	}
+
+	rules := []any{map[string]any{
+		"backendRefs": []any{map[string]any{
+			"name": b.Instance.Name + "-leader",
+			"port": int64(port),
+		}},
+	}}
+
+	return unstructured.SetNestedSlice(route.Object, rules, "spec", "rules")
+}
+
+// Name returns the name of the ingress reconciler
+func (b *IngressReconciler) Name() string {
+	return "ingress"
+}
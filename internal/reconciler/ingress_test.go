@@ -0,0 +1,154 @@
+package reconciler_test
+
+import (
+	"testing"
+
+	"github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
+	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
+
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestNoIngressByDefault(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	rc := &reconciler.IngressReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	// The Gateway API CRDs aren't installed in the test environment, which must not fail the reconcile
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	ingress := &networkingv1.Ingress{}
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name + "-mgmt", Namespace: instance.Namespace}, ingress)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestIngressFollowsMgmtPort(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+
+	className := "nginx"
+	instance.Spec.Ingress = &v1alpha1.IngressSpec{
+		Kind:             v1alpha1.IngressKindIngress,
+		Hostnames:        []string{"lavinmq.example.com"},
+		IngressClassName: &className,
+		TlsSecretName:    "lavinmq-example-tls",
+		Annotations:      map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt"},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	rc := &reconciler.IngressReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	ingress := &networkingv1.Ingress{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name + "-mgmt", Namespace: instance.Namespace}, ingress))
+	assert.Equal(t, "nginx", *ingress.Spec.IngressClassName)
+	assert.Equal(t, "letsencrypt", ingress.Annotations["cert-manager.io/cluster-issuer"])
+	assert.Equal(t, []networkingv1.IngressTLS{{Hosts: []string{"lavinmq.example.com"}, SecretName: "lavinmq-example-tls"}}, ingress.Spec.TLS)
+	assert.Len(t, ingress.Spec.Rules, 1)
+	backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service
+	assert.Equal(t, instance.Name+"-leader", backend.Name)
+	assert.Equal(t, int32(15672), backend.Port.Number)
+
+	t.Log("Changing the mgmt port updates the backend")
+	instance.Spec.Config.Mgmt.Port = 8080
+	assert.NoError(t, k8sClient.Update(t.Context(), instance))
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name + "-mgmt", Namespace: instance.Namespace}, ingress))
+	assert.Equal(t, int32(8080), ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port.Number)
+
+	t.Log("Removing the ingress block deletes the Ingress")
+	instance.Spec.Ingress = nil
+	assert.NoError(t, k8sClient.Update(t.Context(), instance))
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name + "-mgmt", Namespace: instance.Namespace}, ingress)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestIngressNotOwnedKept(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	pathType := networkingv1.PathTypePrefix
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.Name + "-mgmt",
+			Namespace: instance.Namespace,
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{{
+				Host: "mgmt.example.com",
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{
+						Path:     "/",
+						PathType: &pathType,
+						Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+							Name: instance.Name,
+							Port: networkingv1.ServiceBackendPort{Number: 15672},
+						}},
+					}},
+				}},
+			}},
+		},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), ingress))
+
+	rc := &reconciler.IngressReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name + "-mgmt", Namespace: instance.Namespace}, ingress)
+	assert.NoError(t, err, "Expected an Ingress not created by the operator to be kept")
+}
@@ -0,0 +1,165 @@
+package reconciler
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cloudamqp/lavinmq-operator/internal/etcd"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// EtcdPrefix is the key prefix under which the LavinMQ nodes of this instance keep their clustering state.
+func (reconciler *ResourceReconciler) EtcdPrefix() string {
+	return reconciler.Instance.EtcdPrefix()
+}
+
+// DeleteEtcdPrefix deletes the clustering state of the instance from etcd and returns the number of keys deleted.
+func (reconciler *ResourceReconciler) DeleteEtcdPrefix(ctx context.Context) (int64, error) {
+	client, err := reconciler.etcdClient(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer client.CloseIdleConnections()
+
+	// The trailing slash keeps instances sharing a name prefix, e.g. broker and broker2, apart.
+	deleted, err := client.DeletePrefix(ctx, reconciler.EtcdPrefix()+"/")
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete etcd prefix: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// CurrentLeader returns the name of the pod currently acting as leader, or an empty string if there is none.
+// A single node without etcd is always its own leader. For clustered instances the leader is read from
+// the election key LavinMQ maintains in etcd.
+func (reconciler *ResourceReconciler) CurrentLeader(ctx context.Context) (string, error) {
+	if reconciler.Instance.EtcdEndpoints() == nil {
+		return fmt.Sprintf("%s-0", reconciler.Instance.Name), nil
+	}
+
+	leader, err := reconciler.leaderKey(ctx)
+	if err != nil || leader == nil {
+		return "", err
+	}
+
+	return podNameFromAdvertisedURI(leader.Value), nil
+}
+
+// leaderKey returns the election key of the current leader, nil if there is none. Its create revision tells
+// elections apart, also when the same pod wins again.
+func (reconciler *ResourceReconciler) leaderKey(ctx context.Context) (*etcd.KeyValue, error) {
+	client, err := reconciler.etcdClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer client.CloseIdleConnections()
+
+	kvs, err := client.GetPrefix(ctx, reconciler.EtcdPrefix()+"/leader")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read leader from etcd: %w", err)
+	}
+
+	// Election candidates may be stored as keys below the election key, the
+	// oldest one holds the leadership.
+	var leader *etcd.KeyValue
+	for i := range kvs {
+		if leader == nil || kvs[i].CreateRevision < leader.CreateRevision {
+			leader = &kvs[i]
+		}
+	}
+
+	return leader, nil
+}
+
+// etcdClient returns a client for the etcd cluster of the instance, authenticating with the client
+// certificate and credentials like the LavinMQ nodes do. The client has a transport of its
+// own, callers close its idle connections when done.
+func (reconciler *ResourceReconciler) etcdClient(ctx context.Context) (*etcd.Client, error) {
+	client := etcd.NewClient(reconciler.Instance.EtcdEndpoints())
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	client.HTTPClient.Transport = transport
+
+	if name := reconciler.Instance.EtcdTlsSecretName(); name != "" {
+		secret, err := reconciler.getSecret(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch etcd TLS secret: %w", err)
+		}
+
+		cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, fmt.Errorf("invalid etcd client certificate in secret %s: %w", secret.Name, err)
+		}
+
+		tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+		if ca := secret.Data["ca.crt"]; len(ca) > 0 {
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("invalid etcd CA certificate in secret %s", secret.Name)
+			}
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	if spec := reconciler.Instance.Spec.Etcd; spec != nil && spec.CredentialsSecretRef != nil {
+		secret, err := reconciler.getSecret(ctx, spec.CredentialsSecretRef.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch etcd credentials secret: %w", err)
+		}
+
+		client.Username = string(secret.Data[corev1.BasicAuthUsernameKey])
+		client.Password = string(secret.Data[corev1.BasicAuthPasswordKey])
+	}
+
+	return client, nil
+}
+
+func (reconciler *ResourceReconciler) getSecret(ctx context.Context, name string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	secret.Name = name
+	secret.Namespace = reconciler.Instance.Namespace
+
+	if err := reconciler.GetItem(ctx, secret); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// InSyncReplicas returns the number of nodes in sync with the leader, including the leader itself,
+// from the ISR key LavinMQ maintains in etcd as a comma separated list of node ids.
+func (reconciler *ResourceReconciler) InSyncReplicas(ctx context.Context) (int, error) {
+	client, err := reconciler.etcdClient(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer client.CloseIdleConnections()
+
+	kv, err := client.Get(ctx, reconciler.EtcdPrefix()+"/isr")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read in-sync replicas from etcd: %w", err)
+	}
+
+	if kv == nil || strings.TrimSpace(kv.Value) == "" {
+		return 0, nil
+	}
+
+	return len(strings.Split(kv.Value, ",")), nil
+}
+
+// podNameFromAdvertisedURI extracts the pod name from a clustering URI such as
+// tcp://<pod>.<service>.<namespace>.svc.cluster.local:5679
+func podNameFromAdvertisedURI(uri string) string {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+
+	return strings.SplitN(u.Hostname(), ".", 2)[0]
+}
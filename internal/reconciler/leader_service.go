@@ -0,0 +1,172 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/controller/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// How often the leader is looked up in etcd, leadership changes aren't observable through the Kubernetes API.
+const leaderPollInterval = 10 * time.Second
+
+// defaultAmqpPort is the port LavinMQ serves AMQP on when config.amqp.port isn't set.
+const defaultAmqpPort = 5672
+
+// LeaderServiceName returns the name of the service routing client traffic to the leader.
+func LeaderServiceName(instance *v1alpha1.LavinMQ) string {
+	return instance.Name + "-leader"
+}
+
+// LeaderAMQPURI returns the AMQP URI of the leader service, authenticated as user, preferring plain AMQP like
+// managementURL. The vhost is escaped into the path as AMQP URIs require, "/" becomes %2F.
+func LeaderAMQPURI(instance *v1alpha1.LavinMQ, user *url.Userinfo, vhost string) (string, error) {
+	amqp := instance.Spec.Config.Amqp
+	scheme, port := "amqp", int(amqp.Port)
+	switch {
+	case amqp.Port == 0:
+		port = defaultAmqpPort
+	case amqp.Port > 0:
+	case amqp.TlsPort > 0 && instance.TlsSecretName() != "":
+		scheme, port = "amqps", int(amqp.TlsPort)
+	default:
+		return "", errors.New("AMQP is disabled, config.amqp.port or config.amqp.tls_port is required")
+	}
+
+	host := fmt.Sprintf("%s.%s.svc.cluster.local", LeaderServiceName(instance), instance.Namespace)
+	return fmt.Sprintf("%s://%s@%s/%s", scheme, user.String(), net.JoinHostPort(host, strconv.Itoa(port)), url.PathEscape(vhost)), nil
+}
+
+// LeaderServiceReconciler maintains the role label on the LavinMQ pods and a ClusterIP service
+// for client traffic that only routes to the current leader. Followers don't serve clients, and
+// readiness probes can't express "leader only", hence the operator maintained label.
+type LeaderServiceReconciler struct {
+	*ResourceReconciler
+}
+
+func (reconciler *ResourceReconciler) LeaderServiceReconciler() *LeaderServiceReconciler {
+	return &LeaderServiceReconciler{
+		ResourceReconciler: reconciler,
+	}
+}
+
+func (b *LeaderServiceReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	result := ctrl.Result{}
+	if b.Instance.EtcdEndpoints() != nil {
+		result.RequeueAfter = leaderPollInterval
+	}
+
+	if err := b.labelPods(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	service := b.newObject()
+
+	err := b.GetItem(ctx, service)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return result, b.CreateItem(ctx, service)
+		}
+
+		return ctrl.Result{}, err
+	}
+
+	b.updateFields(ctx, service)
+
+	err = b.Client.Update(ctx, service)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return result, nil
+}
+
+// labelPods sets the role label of each pod from the leader currently elected in etcd.
+func (b *LeaderServiceReconciler) labelPods(ctx context.Context) error {
+	leader, err := b.CurrentLeader(ctx)
+	if err != nil {
+		// Keep the current labels rather than cutting off clients while etcd is unreachable.
+		b.Logger.Error(err, "Failed to determine leader, keeping current pod roles")
+		return nil
+	}
+
+	pods := &corev1.PodList{}
+	err = b.Client.List(ctx, pods,
+		client.InNamespace(b.Instance.Namespace),
+		client.MatchingLabels(utils.SelectorLabelsForLavinMQ(b.Instance)))
+	if err != nil {
+		return err
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		role := utils.RoleFollower
+		if pod.Name == leader {
+			role = utils.RoleLeader
+		}
+
+		if pod.Labels[utils.RoleLabel] == role {
+			continue
+		}
+
+		b.Logger.Info("Updating pod role", "pod", pod.Name, "role", role)
+		patch := client.MergeFrom(pod.DeepCopy())
+		pod.Labels[utils.RoleLabel] = role
+		if err := b.Client.Patch(ctx, pod, patch); err != nil {
+			return fmt.Errorf("failed to label pod %s: %w", pod.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (b *LeaderServiceReconciler) newObject() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      LeaderServiceName(b.Instance),
+			Namespace: b.Instance.Namespace,
+			Labels:    utils.LabelsForLavinMQ(b.Instance),
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: utils.LeaderSelectorLabelsForLavinMQ(b.Instance),
+			Ports:    b.clientServicePorts(),
+		},
+	}
+}
+
+func (b *LeaderServiceReconciler) updateFields(_ context.Context, service *corev1.Service) {
+	newService := b.newObject()
+
+	if !maps.Equal(service.Labels, newService.Labels) {
+		service.Labels = newService.Labels
+	}
+
+	if !maps.Equal(service.Spec.Selector, newService.Spec.Selector) {
+		b.Logger.Info("Leader service selector changed, updating")
+		service.Spec.Selector = newService.Spec.Selector
+	}
+
+	if !reflect.DeepEqual(service.Spec.Ports, newService.Spec.Ports) {
+		service.Spec.Ports = newService.Spec.Ports
+	}
+}
+
+// Name returns the name of the leader service reconciler
+func (b *LeaderServiceReconciler) Name() string {
+	return "leader-service"
+}
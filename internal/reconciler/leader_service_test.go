@@ -0,0 +1,150 @@
+package reconciler_test
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/controller/utils"
+	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
+	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestLeaderServiceSingleNode(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+	createLavinMQPods(t, instance)
+
+	rc := &reconciler.LeaderServiceReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	result, err := rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter, "A single node without etcd has nothing to poll")
+
+	pod := &corev1.Pod{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name + "-0", Namespace: instance.Namespace}, pod))
+	assert.Equal(t, utils.RoleLeader, pod.Labels[utils.RoleLabel])
+
+	service := &corev1.Service{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name + "-leader", Namespace: instance.Namespace}, service))
+	assert.Equal(t, corev1.ServiceTypeClusterIP, service.Spec.Type)
+	assert.Equal(t, utils.LeaderSelectorLabelsForLavinMQ(instance), service.Spec.Selector)
+	assert.Len(t, service.Spec.Ports, 3)
+}
+
+func TestLeaderAMQPURI(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	user := url.UserPassword("shovel", "p@ss")
+
+	uri, err := reconciler.LeaderAMQPURI(instance, user, "/")
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("amqp://shovel:p%%40ss@%s-leader.%s.svc.cluster.local:5672/%%2F", instance.Name, instance.Namespace), uri)
+
+	t.Log("AMQPS is used when plain AMQP is disabled")
+	instance.Spec.Config.Amqp.Port = -1
+	instance.Spec.Config.Amqp.TlsPort = 5671
+	instance.Spec.TlsSecret = &corev1.SecretReference{Name: "tls"}
+	uri, err = reconciler.LeaderAMQPURI(instance, user, "orders")
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("amqps://shovel:p%%40ss@%s-leader.%s.svc.cluster.local:5671/orders", instance.Name, instance.Namespace), uri)
+
+	instance.Spec.TlsSecret = nil
+	_, err = reconciler.LeaderAMQPURI(instance, user, "/")
+	assert.Error(t, err)
+}
+
+func TestLeaderServiceFollowsEtcdLeader(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{Replicas: &[]int32{3}[0]})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	etcd := testutils.StartFakeEtcd(nil)
+	defer etcd.Close()
+
+	instance.Spec.EtcdEndpoints = []string{etcd.URL}
+	defer k8sClient.Delete(t.Context(), instance)
+
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+	// The default prefix includes the UID, only known once created
+	etcd.Set(instance.EtcdPrefix()+"/leader", fmt.Sprintf("tcp://%s-1.%s.%s.svc.cluster.local:5679", instance.Name, instance.Name, instance.Namespace))
+	createLavinMQPods(t, instance)
+
+	rc := &reconciler.LeaderServiceReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	result, err := rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.NotZero(t, result.RequeueAfter, "Clustered instances poll etcd for leader changes")
+	assert.Equal(t, []string{"follower", "leader", "follower"}, podRoles(t, instance))
+
+	service := &corev1.Service{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name + "-leader", Namespace: instance.Namespace}, service))
+	assert.False(t, slices.ContainsFunc(service.Spec.Ports, func(port corev1.ServicePort) bool {
+		return port.Name == "clustering"
+	}), "The clustering port is not for clients")
+
+	t.Log("Leadership moves to another pod")
+	etcd.Set(instance.EtcdPrefix()+"/leader", fmt.Sprintf("tcp://%s-2.%s.%s.svc.cluster.local:5679", instance.Name, instance.Name, instance.Namespace))
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"follower", "follower", "leader"}, podRoles(t, instance))
+}
+
+// createLavinMQPods creates the pods the StatefulSet controller would have created, which doesn't run in envtest.
+func createLavinMQPods(t *testing.T, instance *v1alpha1.LavinMQ) {
+	for i := range int(instance.Spec.Replicas) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%d", instance.Name, i),
+				Namespace: instance.Namespace,
+				Labels:    utils.LabelsForLavinMQ(instance),
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "lavinmq", Image: instance.Spec.Image}},
+			},
+		}
+		assert.NoError(t, k8sClient.Create(t.Context(), pod))
+	}
+}
+
+func podRoles(t *testing.T, instance *v1alpha1.LavinMQ) []string {
+	roles := []string{}
+	for i := range int(instance.Spec.Replicas) {
+		pod := &corev1.Pod{}
+		err := k8sClient.Get(t.Context(), types.NamespacedName{Name: fmt.Sprintf("%s-%d", instance.Name, i), Namespace: instance.Namespace}, pod)
+		assert.NoError(t, err)
+		roles = append(roles, pod.Labels[utils.RoleLabel])
+	}
+
+	return roles
+}
@@ -0,0 +1,95 @@
+package reconciler
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// defaultMgmtPort is the port LavinMQ serves the management API on when config.mgmt.port isn't set.
+const defaultMgmtPort = 15672
+
+// ErrNoLeader is returned when the management API of the leader is needed while no node leads the cluster.
+var ErrNoLeader = errors.New("no leader elected")
+
+// LeaderManagementClient returns a client for the management API of the leader, the only node serving it in
+// a cluster.
+func (reconciler *ResourceReconciler) LeaderManagementClient(ctx context.Context) (*management.Client, error) {
+	leader, err := reconciler.CurrentLeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if leader == "" {
+		return nil, ErrNoLeader
+	}
+
+	return reconciler.ManagementClient(ctx, leader)
+}
+
+// ManagementClient returns a client for the management API of a pod, reached through the headless service and
+// authenticated as the operator user. The client has a transport of its own, callers close its idle
+// connections when done.
+func (reconciler *ResourceReconciler) ManagementClient(ctx context.Context, pod string) (*management.Client, error) {
+	secret, err := reconciler.getSecret(ctx, OperatorUserSecretName(reconciler.Instance))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch operator user secret: %w", err)
+	}
+
+	baseURL, tlsEnabled, err := reconciler.managementURL(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	client := management.NewClient(baseURL,
+		string(secret.Data[corev1.BasicAuthUsernameKey]),
+		string(secret.Data[corev1.BasicAuthPasswordKey]))
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	client.HTTPClient.Transport = transport
+
+	if tlsEnabled {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		tlsSecret, err := reconciler.getSecret(ctx, reconciler.Instance.TlsSecretName())
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch TLS secret: %w", err)
+		}
+		if ca := tlsSecret.Data["ca.crt"]; len(ca) > 0 {
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("invalid CA certificate in secret %s", tlsSecret.Name)
+			}
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return client, nil
+}
+
+// managementURL returns the base URL of the management API of a pod, preferring plain HTTP as the traffic
+// stays within the cluster. ManagementEndpoint replaces the pod address when set.
+func (reconciler *ResourceReconciler) managementURL(pod string) (string, bool, error) {
+	if reconciler.ManagementEndpoint != "" {
+		return reconciler.ManagementEndpoint, false, nil
+	}
+
+	mgmt := reconciler.Instance.Spec.Config.Mgmt
+	host := fmt.Sprintf("%s.%s.%s.svc.cluster.local", pod, reconciler.Instance.Name, reconciler.Instance.Namespace)
+	switch {
+	case mgmt.Port == 0:
+		return "http://" + net.JoinHostPort(host, strconv.Itoa(defaultMgmtPort)), false, nil
+	case mgmt.Port > 0:
+		return "http://" + net.JoinHostPort(host, strconv.Itoa(int(mgmt.Port))), false, nil
+	case mgmt.TlsPort > 0 && reconciler.Instance.TlsSecretName() != "":
+		return "https://" + net.JoinHostPort(host, strconv.Itoa(int(mgmt.TlsPort))), true, nil
+	}
+
+	return "", false, errors.New("the management API is disabled, config.mgmt.port or config.mgmt.tls_port is required")
+}
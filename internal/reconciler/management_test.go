@@ -0,0 +1,57 @@
+package reconciler_test
+
+import (
+	"testing"
+
+	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
+	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestManagementClient(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	resourceReconciler := &reconciler.ResourceReconciler{
+		Instance: instance,
+		Scheme:   scheme.Scheme,
+		Client:   k8sClient,
+	}
+
+	_, err = resourceReconciler.ManagementClient(t.Context(), instance.Name+"-0")
+	assert.Error(t, err, "Expected an error without the operator user")
+
+	_, err = resourceReconciler.OperatorUserReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	secret := &corev1.Secret{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: reconciler.OperatorUserSecretName(instance), Namespace: instance.Namespace}, secret))
+
+	server := testutils.StartFakeManagement(string(secret.Data[corev1.BasicAuthUsernameKey]), string(secret.Data[corev1.BasicAuthPasswordKey]))
+	defer server.Close()
+
+	t.Log("The management API can be disabled")
+	instance.Spec.Config.Mgmt.Port = -1
+	_, err = resourceReconciler.ManagementClient(t.Context(), instance.Name+"-0")
+	assert.ErrorContains(t, err, "management API is disabled")
+
+	t.Log("The single node is the leader")
+	resourceReconciler.ManagementEndpoint = server.URL
+	client, err := resourceReconciler.LeaderManagementClient(t.Context())
+	assert.NoError(t, err)
+	defer client.CloseIdleConnections()
+
+	overview, err := client.Overview(t.Context())
+	assert.NoError(t, err)
+	assert.NotEmpty(t, overview.LavinMQVersion)
+}
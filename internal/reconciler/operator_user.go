@@ -0,0 +1,87 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/controller/utils"
+	resource_utils "github.com/cloudamqp/lavinmq-operator/internal/reconciler/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// OperatorUsername is the administrator the operator uses for the management API.
+const OperatorUsername = "lavinmq-operator"
+
+// OperatorUserSecretName returns the name of the Secret holding the credentials of the operator user.
+func OperatorUserSecretName(instance *v1alpha1.LavinMQ) string {
+	return fmt.Sprintf("%s-operator-user", instance.Name)
+}
+
+// OperatorUserReconciler generates the credentials of the administrator the operator uses for the management
+// API. The user is created by the LavinMQ pods themselves, see appendOperatorUser.
+type OperatorUserReconciler struct {
+	*ResourceReconciler
+}
+
+func (reconciler *ResourceReconciler) OperatorUserReconciler() *OperatorUserReconciler {
+	return &OperatorUserReconciler{
+		ResourceReconciler: reconciler,
+	}
+}
+
+func (b *OperatorUserReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      OperatorUserSecretName(b.Instance),
+			Namespace: b.Instance.Namespace,
+			Labels:    utils.LabelsForLavinMQ(b.Instance),
+		},
+		Type: corev1.SecretTypeBasicAuth,
+	}
+
+	err := b.GetItem(ctx, secret)
+	if err == nil {
+		if len(secret.Data[corev1.BasicAuthUsernameKey]) > 0 && len(secret.Data[corev1.BasicAuthPasswordKey]) > 0 {
+			return ctrl.Result{}, nil
+		}
+		// Emptied by hand, regenerate in place so the pods pick up the new password on their next restart.
+		if err := b.generate(secret); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, b.Client.Update(ctx, secret)
+	}
+	if !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+
+	b.Logger.Info("Operator user secret not found, generating credentials", "name", secret.Name)
+	if err := b.generate(secret); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, b.CreateItem(ctx, secret)
+}
+
+func (b *OperatorUserReconciler) generate(secret *corev1.Secret) error {
+	password, err := resource_utils.GeneratePassword()
+	if err != nil {
+		return fmt.Errorf("failed to generate password: %w", err)
+	}
+
+	secret.Data = map[string][]byte{
+		corev1.BasicAuthUsernameKey: []byte(OperatorUsername),
+		corev1.BasicAuthPasswordKey: []byte(password),
+	}
+
+	return nil
+}
+
+// Name returns the name of the operator user reconciler
+func (b *OperatorUserReconciler) Name() string {
+	return "operator-user"
+}
@@ -0,0 +1,70 @@
+package reconciler_test
+
+import (
+	"testing"
+
+	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
+	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestOperatorUserSecretGenerated(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	configMap := createConfigMap(t, instance, "initial_config")
+	defer deleteConfigMap(t, configMap)
+
+	resourceReconciler := &reconciler.ResourceReconciler{
+		Instance: instance,
+		Scheme:   scheme.Scheme,
+		Client:   k8sClient,
+	}
+
+	_, err = resourceReconciler.OperatorUserReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Name: reconciler.OperatorUserSecretName(instance), Namespace: instance.Namespace}
+	assert.NoError(t, k8sClient.Get(t.Context(), key, secret))
+	assert.True(t, metav1.IsControlledBy(secret, instance))
+	assert.Equal(t, "lavinmq-operator", string(secret.Data[corev1.BasicAuthUsernameKey]))
+	password := string(secret.Data[corev1.BasicAuthPasswordKey])
+	assert.NotEmpty(t, password)
+
+	t.Log("The password is kept")
+	_, err = resourceReconciler.OperatorUserReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.NoError(t, k8sClient.Get(t.Context(), key, secret))
+	assert.Equal(t, password, string(secret.Data[corev1.BasicAuthPasswordKey]))
+
+	t.Log("The pods create the user from the credentials")
+	_, err = resourceReconciler.StatefulSetReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	sts := &appsv1.StatefulSet{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts))
+	container := sts.Spec.Template.Spec.Containers[0]
+	assert.Contains(t, container.Env, corev1.EnvVar{
+		Name: "LAVINMQ_OPERATOR_PASSWORD",
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: secret.Name},
+			Key:                  corev1.BasicAuthPasswordKey,
+		}},
+	})
+	assert.NotNil(t, container.Lifecycle)
+	assert.Contains(t, container.Lifecycle.PostStart.Exec.Command[2], "add_user")
+	assert.NotEmpty(t, sts.Spec.Template.Annotations["operator-user-hash"])
+}
@@ -0,0 +1,111 @@
+package reconciler
+
+import (
+	"context"
+	"maps"
+	"reflect"
+
+	"github.com/cloudamqp/lavinmq-operator/internal/controller/utils"
+
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// PDBReconciler owns the PodDisruptionBudget of clustered instances, so a node drain
+// can't evict several members of the cluster at once.
+type PDBReconciler struct {
+	*ResourceReconciler
+}
+
+func (reconciler *ResourceReconciler) PDBReconciler() *PDBReconciler {
+	return &PDBReconciler{
+		ResourceReconciler: reconciler,
+	}
+}
+
+func (b *PDBReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	pdb := b.newObject()
+
+	err := b.GetItem(ctx, pdb)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+
+		if b.Instance.Spec.Replicas <= 1 {
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, b.CreateItem(ctx, pdb)
+	}
+
+	// A budget with the same name written by hand is neither taken over nor deleted.
+	if !metav1.IsControlledBy(pdb, b.Instance) {
+		b.Logger.Info("PodDisruptionBudget not controlled by the instance, leaving it alone", "name", pdb.Name)
+		return ctrl.Result{}, nil
+	}
+
+	// A single node can't tolerate any disruption, a budget would only block node drains.
+	if b.Instance.Spec.Replicas <= 1 {
+		b.Logger.Info("Scaled down to a single node, deleting PodDisruptionBudget", "name", pdb.Name)
+		if err := b.Client.Delete(ctx, pdb); err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	b.updateFields(ctx, pdb)
+
+	err = b.Client.Update(ctx, pdb)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (b *PDBReconciler) newObject() *policyv1.PodDisruptionBudget {
+	maxUnavailable := intstr.FromInt32(1)
+	if spec := b.Instance.Spec.PodDisruptionBudget; spec != nil && spec.MaxUnavailable != nil {
+		maxUnavailable = *spec.MaxUnavailable
+	}
+
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.Instance.Name,
+			Namespace: b.Instance.Namespace,
+			Labels:    utils.LabelsForLavinMQ(b.Instance),
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: utils.SelectorLabelsForLavinMQ(b.Instance),
+			},
+		},
+	}
+}
+
+func (b *PDBReconciler) updateFields(_ context.Context, pdb *policyv1.PodDisruptionBudget) {
+	newPDB := b.newObject()
+
+	if !maps.Equal(pdb.Labels, newPDB.Labels) {
+		pdb.Labels = newPDB.Labels
+	}
+
+	if !reflect.DeepEqual(pdb.Spec.MaxUnavailable, newPDB.Spec.MaxUnavailable) {
+		b.Logger.Info("PodDisruptionBudget maxUnavailable changed, updating", "new", newPDB.Spec.MaxUnavailable.String())
+		pdb.Spec.MaxUnavailable = newPDB.Spec.MaxUnavailable
+	}
+
+	if !reflect.DeepEqual(pdb.Spec.Selector, newPDB.Spec.Selector) {
+		pdb.Spec.Selector = newPDB.Spec.Selector
+	}
+}
+
+// Name returns the name of the PDB reconciler
+func (b *PDBReconciler) Name() string {
+	return "pdb"
+}
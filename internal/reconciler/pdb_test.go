@@ -0,0 +1,137 @@
+package reconciler_test
+
+import (
+	"testing"
+
+	"github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
+	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
+
+	"github.com/stretchr/testify/assert"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestNoPDBForSingleNode(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	rc := &reconciler.PDBReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	pdb := &policyv1.PodDisruptionBudget{}
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, pdb)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestPDBForCluster(t *testing.T) {
+	t.Parallel()
+	replicas := int32(3)
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{Replicas: &replicas})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+
+	instance.Spec.EtcdEndpoints = []string{"http://etcd:2379"}
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	rc := &reconciler.PDBReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	pdb := &policyv1.PodDisruptionBudget{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, pdb))
+	assert.Equal(t, intstr.FromInt32(1), *pdb.Spec.MaxUnavailable)
+	assert.Equal(t, instance.Name, pdb.Spec.Selector.MatchLabels["app.kubernetes.io/instance"])
+
+	t.Log("Overriding maxUnavailable updates the budget")
+	maxUnavailable := intstr.FromString("50%")
+	instance.Spec.PodDisruptionBudget = &v1alpha1.PodDisruptionBudgetSpec{MaxUnavailable: &maxUnavailable}
+	assert.NoError(t, k8sClient.Update(t.Context(), instance))
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, pdb))
+	assert.Equal(t, maxUnavailable, *pdb.Spec.MaxUnavailable)
+
+	t.Log("Scaling down to a single node deletes the budget")
+	instance.Spec.Replicas = 1
+	assert.NoError(t, k8sClient.Update(t.Context(), instance))
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, pdb)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestPDBNotOwnedKept(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	minAvailable := intstr.FromInt32(1)
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: instance.Name, Namespace: instance.Namespace},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}},
+		},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), pdb))
+
+	rc := &reconciler.PDBReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	t.Log("A budget written by hand isn't deleted on a single node")
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, pdb))
+
+	t.Log("Nor taken over when clustered")
+	instance.Spec.Replicas = 3
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, pdb))
+	assert.Equal(t, "other", pdb.Spec.Selector.MatchLabels["app"])
+	assert.Nil(t, pdb.Spec.MaxUnavailable)
+}
@@ -3,17 +3,33 @@ package reconciler
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
 	"github.com/cloudamqp/lavinmq-operator/internal/controller/utils"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// fileSystemResizeGracePeriod is how long the kubelet gets to grow the file system of a mounted volume before
+// the pod is restarted, storage providers without online expansion only finish the resize on the next mount.
+const fileSystemResizeGracePeriod = 2 * time.Minute
+
 type PVCReconciler struct {
 	*ResourceReconciler
+
+	// progress lists the volumes being resized, reported in the status.
+	progress string
 }
 
 func (reconciler *ResourceReconciler) PVCReconciler() *PVCReconciler {
@@ -23,8 +39,16 @@ func (reconciler *ResourceReconciler) PVCReconciler() *PVCReconciler {
 }
 
 func (b *PVCReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	// The claim being replaced by a volume migration is deleted and recreated by the VolumeMigrationReconciler.
+	migrating := b.migratingClaim()
+
 	pvcs := b.newObjects()
+	claims := []corev1.PersistentVolumeClaim{}
 	for _, pvc := range pvcs {
+		if pvc.Name == migrating {
+			continue
+		}
+
 		err := b.GetItem(ctx, &pvc)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
@@ -33,6 +57,7 @@ func (b *PVCReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
 					b.Logger.Error(err, "Failed to create PVC")
 					return ctrl.Result{}, err
 				}
+				claims = append(claims, pvc)
 				continue
 			}
 
@@ -49,9 +74,198 @@ func (b *PVCReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
 			b.Logger.Error(err, "Failed to update PVC")
 			return ctrl.Result{}, err
 		}
+		claims = append(claims, pvc)
+	}
+
+	if b.Instance.Spec.PersistentVolumeClaimRetentionPolicy == v1alpha1.PersistentVolumeClaimRetentionPolicyDelete {
+		if err := b.deleteOrphanedVolumes(ctx); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return b.reportResizes(ctx, claims)
+}
+
+// reportResizes records the size and resize state of the claims in the status. Resizes are waited on, a file
+// system resize that needs a restart is left to the RolloutReconciler once the grace period has passed.
+func (b *PVCReconciler) reportResizes(ctx context.Context, claims []corev1.PersistentVolumeClaim) (ctrl.Result, error) {
+	volumes := []v1alpha1.VolumeStatus{}
+	resizing := []string{}
+	result := ctrl.Result{}
+	for i := range claims {
+		pvc := &claims[i]
+		volume := v1alpha1.VolumeStatus{
+			Claim:     pvc.Name,
+			Requested: pvc.Spec.Resources.Requests.Storage().DeepCopy(),
+			Capacity:  pvc.Status.Capacity.Storage().DeepCopy(),
+		}
+		volume.ResizePhase, volume.Message = resizeState(pvc)
+		volumes = append(volumes, volume)
+
+		switch volume.ResizePhase {
+		case "":
+			continue
+		case v1alpha1.VolumeResizePhaseFailed:
+			b.Logger.Info("Volume resize failed", "name", pvc.Name, "message", volume.Message)
+			continue
+		case v1alpha1.VolumeResizePhaseFileSystemResizePending:
+			wait := fileSystemResizeWait(pvc, time.Now())
+			if wait > 0 && (result.RequeueAfter == 0 || wait < result.RequeueAfter) {
+				result.RequeueAfter = wait
+			}
+		}
+		resizing = append(resizing, pvc.Name)
+		result.Requeue = true
+	}
+
+	b.progress = ""
+	if len(resizing) > 0 {
+		b.progress = fmt.Sprintf("resizing %s", strings.Join(resizing, ", "))
+	}
+
+	if equality.Semantic.DeepEqual(b.Instance.Status.Volumes, volumes) {
+		return result, nil
+	}
+
+	// The patch is applied to a copy so the response doesn't replace the instance being reconciled.
+	patched := b.Instance.DeepCopy()
+	patched.Status.Volumes = volumes
+	if err := b.Client.Status().Patch(ctx, patched, client.MergeFrom(b.Instance)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to record volume status: %w", err)
+	}
+	b.Instance.Status.Volumes = volumes
+	b.Instance.ResourceVersion = patched.ResourceVersion
+
+	return result, nil
+}
+
+// resizeState derives the resize step of a claim from its conditions and capacity.
+func resizeState(pvc *corev1.PersistentVolumeClaim) (v1alpha1.VolumeResizePhase, string) {
+	for _, condition := range pvc.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case corev1.PersistentVolumeClaimControllerResizeError, corev1.PersistentVolumeClaimNodeResizeError:
+			return v1alpha1.VolumeResizePhaseFailed, condition.Message
+		}
+	}
+
+	for _, condition := range pvc.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case corev1.PersistentVolumeClaimFileSystemResizePending:
+			return v1alpha1.VolumeResizePhaseFileSystemResizePending, condition.Message
+		case corev1.PersistentVolumeClaimResizing:
+			return v1alpha1.VolumeResizePhaseResizing, condition.Message
+		}
+	}
+
+	// An unbound claim has no capacity yet and is provisioned with the requested size.
+	capacity := pvc.Status.Capacity.Storage()
+	if pvc.Status.Phase == corev1.ClaimBound && !capacity.IsZero() && capacity.Cmp(*pvc.Spec.Resources.Requests.Storage()) < 0 {
+		return v1alpha1.VolumeResizePhaseResizing, ""
+	}
+
+	return "", ""
+}
+
+// fileSystemResizeWait returns how long the kubelet still gets to grow the file system of the claim while it's
+// mounted, zero once the pod has to be restarted.
+func fileSystemResizeWait(pvc *corev1.PersistentVolumeClaim, now time.Time) time.Duration {
+	for _, condition := range pvc.Status.Conditions {
+		if condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending && condition.Status == corev1.ConditionTrue {
+			return max(condition.LastTransitionTime.Add(fileSystemResizeGracePeriod).Sub(now), 0)
+		}
 	}
 
-	return ctrl.Result{}, nil
+	return 0
+}
+
+// offlineResizePending reports whether the pod has to be restarted to finish the file system resize of its
+// volume. A pod started after the resize became pending has mounted the volume again and isn't restarted twice.
+func (reconciler *ResourceReconciler) offlineResizePending(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	name := fmt.Sprintf("data-%s", pod.Name)
+	pending := slices.ContainsFunc(reconciler.Instance.Status.Volumes, func(volume v1alpha1.VolumeStatus) bool {
+		return volume.Claim == name && volume.ResizePhase == v1alpha1.VolumeResizePhaseFileSystemResizePending
+	})
+	if !pending {
+		return false, nil
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	pvc.Name = name
+	pvc.Namespace = pod.Namespace
+	if err := reconciler.GetItem(ctx, pvc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	for _, condition := range pvc.Status.Conditions {
+		if condition.Type != corev1.PersistentVolumeClaimFileSystemResizePending || condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		if pod.Status.StartTime == nil || !pod.Status.StartTime.Before(&condition.LastTransitionTime) {
+			return false, nil
+		}
+		return fileSystemResizeWait(pvc, time.Now()) == 0, nil
+	}
+
+	return false, nil
+}
+
+// deleteOrphanedVolumes deletes the volumes left behind by a scale down. A volume is only orphaned once the
+// StatefulSet has been scaled below its ordinal and the pod using it is gone.
+func (b *PVCReconciler) deleteOrphanedVolumes(ctx context.Context) error {
+	sts := &appsv1.StatefulSet{}
+	sts.Name = b.Instance.Name
+	sts.Namespace = b.Instance.Namespace
+	stsReplicas := int32(0)
+	if err := b.GetItem(ctx, sts); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+	} else if sts.Spec.Replicas != nil {
+		stsReplicas = *sts.Spec.Replicas
+	}
+
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	err := b.Client.List(ctx, pvcList,
+		client.InNamespace(b.Instance.Namespace),
+		client.MatchingLabels(utils.SelectorLabelsForLavinMQ(b.Instance)))
+	if err != nil {
+		return err
+	}
+
+	prefix := fmt.Sprintf("data-%s-", b.Instance.Name)
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
+		ordinal, err := strconv.Atoi(strings.TrimPrefix(pvc.Name, prefix))
+		if !strings.HasPrefix(pvc.Name, prefix) || err != nil {
+			continue
+		}
+		if int32(ordinal) < b.Instance.Spec.Replicas || int32(ordinal) < stsReplicas || pvc.DeletionTimestamp != nil {
+			continue
+		}
+
+		pod := &corev1.Pod{}
+		pod.Name = fmt.Sprintf("%s-%d", b.Instance.Name, ordinal)
+		pod.Namespace = b.Instance.Namespace
+		if err := b.GetItem(ctx, pod); err == nil || !apierrors.IsNotFound(err) {
+			continue
+		}
+
+		b.Logger.Info("Deleting volume orphaned by scale down", "name", pvc.Name)
+		if err := b.Client.Delete(ctx, pvc); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete PVC %s: %w", pvc.Name, err)
+		}
+	}
+
+	return nil
 }
 
 func (b *PVCReconciler) newObjects() []corev1.PersistentVolumeClaim {
@@ -79,11 +293,18 @@ func (b *PVCReconciler) updateFields(ctx context.Context, pvc *corev1.Persistent
 
 	switch sizeComp {
 	case -1:
+		if err := b.checkExpansion(ctx, pvc); err != nil {
+			return err
+		}
 		b.Logger.Info("Volume size changed, increasing",
 			"old", pvc.Spec.Resources.Requests.Storage(),
 			"new", b.Instance.Spec.DataVolumeClaimSpec.Resources.Requests.Storage())
 		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = b.Instance.Spec.DataVolumeClaimSpec.Resources.Requests[corev1.ResourceStorage]
 	case 1:
+		if b.Instance.Spec.VolumeMigration != nil {
+			b.Logger.Info("Volume size decreased, left to volume migration", "name", pvc.Name)
+			return nil
+		}
 		b.Logger.Info("Volume size decreased, not supported")
 		return fmt.Errorf("volume size decreased, not supported")
 	}
@@ -91,6 +312,29 @@ func (b *PVCReconciler) updateFields(ctx context.Context, pvc *corev1.Persistent
 	return nil
 }
 
+// checkExpansion fails when the StorageClass of the claim doesn't allow growing it, the API server would
+// reject the update.
+func (b *PVCReconciler) checkExpansion(ctx context.Context, pvc *corev1.PersistentVolumeClaim) error {
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return fmt.Errorf("volume %s has no storage class and can't be expanded", pvc.Name)
+	}
+
+	storageClass := &storagev1.StorageClass{}
+	if err := b.Client.Get(ctx, client.ObjectKey{Name: *pvc.Spec.StorageClassName}, storageClass); err != nil {
+		return fmt.Errorf("failed to get storage class %s: %w", *pvc.Spec.StorageClassName, err)
+	}
+	if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+		return fmt.Errorf("storage class %s doesn't allow volume expansion", storageClass.Name)
+	}
+
+	return nil
+}
+
+// Progress returns the volumes being resized, empty if there are none.
+func (b *PVCReconciler) Progress() string {
+	return b.progress
+}
+
 // Name returns the name of the PVC reconciler
 func (b *PVCReconciler) Name() string {
 	return "pvc"
@@ -3,6 +3,7 @@ package reconciler_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
 	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
@@ -171,9 +172,13 @@ func TestStorageSizeDecrease(t *testing.T) {
 }
 
 func createStorageClass(t *testing.T) *storagev1.StorageClass {
+	return createStorageClassWithExpansion(t, true)
+}
+
+func createStorageClassWithExpansion(t *testing.T, allowExpansion bool) *storagev1.StorageClass {
 	storageClass := &storagev1.StorageClass{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: "default-sc",
+			GenerateName: "default-sc-",
 		},
 		Parameters:  map[string]string{},
 		Provisioner: "k8s.io/dummy-test",
@@ -183,7 +188,7 @@ func createStorageClass(t *testing.T) *storagev1.StorageClass {
 		VolumeBindingMode: &[]storagev1.VolumeBindingMode{
 			storagev1.VolumeBindingWaitForFirstConsumer,
 		}[0],
-		AllowVolumeExpansion: &[]bool{true}[0],
+		AllowVolumeExpansion: &allowExpansion,
 	}
 
 	assert.NoError(t, k8sClient.Create(t.Context(), storageClass))
@@ -218,3 +223,188 @@ func cleanupPvcResources(t *testing.T, instance *v1alpha1.LavinMQ) {
 		}
 	}
 }
+
+func TestOrphanedPVCRetentionPolicy(t *testing.T) {
+	t.Parallel()
+	replicas := int32(3)
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{Replicas: &replicas})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	rc := &reconciler.PVCReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	pvcExists := func(ordinal int) bool {
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := k8sClient.Get(t.Context(), types.NamespacedName{Name: fmt.Sprintf("data-%s-%d", instance.Name, ordinal), Namespace: instance.Namespace}, pvc)
+		if err != nil && !apierrors.IsNotFound(err) {
+			assert.NoError(t, err)
+		}
+		// Without a PVC protection controller the object is deleted right away or left terminating.
+		return err == nil && pvc.DeletionTimestamp == nil
+	}
+
+	t.Log("Volumes are retained by default")
+	instance.Spec.Replicas = 1
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.True(t, pvcExists(1))
+	assert.True(t, pvcExists(2))
+
+	t.Log("Volumes still in use by a pod aren't deleted")
+	instance.Spec.PersistentVolumeClaimRetentionPolicy = v1alpha1.PersistentVolumeClaimRetentionPolicyDelete
+	createRevisionPod(t, instance, 2, "current")
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.True(t, pvcExists(0))
+	assert.False(t, pvcExists(1))
+	assert.True(t, pvcExists(2))
+}
+
+func TestStorageSizeIncreaseNotAllowed(t *testing.T) {
+	t.Parallel()
+	storageClass := createStorageClassWithExpansion(t, false)
+	defer k8sClient.Delete(t.Context(), storageClass)
+
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	instance.Spec.DataVolumeClaimSpec.StorageClassName = &storageClass.Name
+	rc := &reconciler.PVCReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+	defer cleanupPvcResources(t, instance)
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	instance.Spec.DataVolumeClaimSpec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("20Gi")
+	assert.NoError(t, k8sClient.Update(t.Context(), instance))
+
+	_, err = rc.Reconcile(t.Context())
+	assert.ErrorContains(t, err, "doesn't allow volume expansion")
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: fmt.Sprintf("data-%s-0", instance.Name), Namespace: instance.Namespace}, pvc))
+	assert.Equal(t, "10Gi", pvc.Spec.Resources.Requests.Storage().String())
+}
+
+func TestVolumeResizeStatus(t *testing.T) {
+	t.Parallel()
+	storageClass := createStorageClass(t)
+	defer k8sClient.Delete(t.Context(), storageClass)
+
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	instance.Spec.DataVolumeClaimSpec.StorageClassName = &storageClass.Name
+	rc := &reconciler.PVCReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+	defer cleanupPvcResources(t, instance)
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	name := fmt.Sprintf("data-%s-0", instance.Name)
+	updateClaimStatus := func(capacity string, conditions ...corev1.PersistentVolumeClaimCondition) {
+		pvc := &corev1.PersistentVolumeClaim{}
+		assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: name, Namespace: instance.Namespace}, pvc))
+		pvc.Status.Phase = corev1.ClaimBound
+		pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)}
+		pvc.Status.Conditions = conditions
+		assert.NoError(t, k8sClient.Status().Update(t.Context(), pvc))
+	}
+	volumeStatus := func() v1alpha1.VolumeStatus {
+		stored := &v1alpha1.LavinMQ{}
+		assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, stored))
+		assert.Len(t, stored.Status.Volumes, 1)
+		if len(stored.Status.Volumes) == 0 {
+			return v1alpha1.VolumeStatus{}
+		}
+		return stored.Status.Volumes[0]
+	}
+
+	updateClaimStatus("10Gi")
+	result, err := rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.False(t, result.Requeue)
+	assert.Equal(t, name, volumeStatus().Claim)
+	assert.Empty(t, volumeStatus().ResizePhase)
+
+	t.Log("Increasing the storage size waits for the volume to be expanded")
+	instance.Spec.DataVolumeClaimSpec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("20Gi")
+	assert.NoError(t, k8sClient.Update(t.Context(), instance))
+
+	result, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Contains(t, rc.Progress(), name)
+	volume := volumeStatus()
+	assert.Equal(t, v1alpha1.VolumeResizePhaseResizing, volume.ResizePhase)
+	assert.Equal(t, "20Gi", volume.Requested.String())
+	assert.Equal(t, "10Gi", volume.Capacity.String())
+
+	t.Log("A pending file system resize is given time to finish while mounted")
+	updateClaimStatus("10Gi", corev1.PersistentVolumeClaimCondition{
+		Type:               corev1.PersistentVolumeClaimFileSystemResizePending,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Message:            "Waiting for user to (re-)start a pod to finish file system resize of volume on node.",
+	})
+	result, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.Greater(t, result.RequeueAfter, time.Duration(0))
+	assert.Equal(t, v1alpha1.VolumeResizePhaseFileSystemResizePending, volumeStatus().ResizePhase)
+	assert.Contains(t, volumeStatus().Message, "file system resize")
+
+	t.Log("A failed resize is reported")
+	updateClaimStatus("10Gi", corev1.PersistentVolumeClaimCondition{
+		Type:    corev1.PersistentVolumeClaimControllerResizeError,
+		Status:  corev1.ConditionTrue,
+		Message: "quota exceeded",
+	})
+	result, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.False(t, result.Requeue)
+	assert.Equal(t, v1alpha1.VolumeResizePhaseFailed, volumeStatus().ResizePhase)
+	assert.Equal(t, "quota exceeded", volumeStatus().Message)
+
+	t.Log("The resize is done once the capacity matches the request")
+	updateClaimStatus("20Gi")
+	result, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.False(t, result.Requeue)
+	assert.Empty(t, rc.Progress())
+	volume = volumeStatus()
+	assert.Empty(t, volume.ResizePhase)
+	assert.Equal(t, "20Gi", volume.Capacity.String())
+}
@@ -17,19 +17,35 @@ type ResourceReconciler struct {
 	Scheme   *runtime.Scheme
 	Logger   logr.Logger
 	Client   client.Client
+
+	// ManagementEndpoint replaces the address of the pods for the management API when set, e.g. to reach the
+	// instance through a port forward or a fake server in tests.
+	ManagementEndpoint string
 }
 
 func (reconciler *ResourceReconciler) Reconcilers() []Reconciler {
 	return []Reconciler{
 		reconciler.ConfigReconciler(),
+		reconciler.DefaultUserReconciler(),
+		reconciler.OperatorUserReconciler(),
+		reconciler.CertificateReconciler(),
+		reconciler.EtcdReconciler(),
 		reconciler.HeadlessServiceReconciler(),
 		reconciler.PVCReconciler(),
+		reconciler.VolumeMigrationReconciler(),
 		reconciler.StatefulSetReconciler(),
+		reconciler.PDBReconciler(),
+		reconciler.LeaderServiceReconciler(),
+		reconciler.RolloutReconciler(),
+		reconciler.ExternalServiceReconciler(),
+		reconciler.IngressReconciler(),
 	}
 }
 
 type Reconciler interface {
 	// TODO: Fix config restart context.
+	// Reconcile returns Requeue while a multi-step operation is in progress,
+	// RequeueAfter is used for periodic polling of state outside of Kubernetes.
 	Reconcile(ctx context.Context) (ctrl.Result, error)
 	Name() string
 }
@@ -0,0 +1,225 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudamqp/lavinmq-operator/internal/controller/utils"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// unreadyRestartDelay is how long an outdated pod has to be unready before it's restarted out of turn.
+const unreadyRestartDelay = 5 * time.Minute
+
+// RolloutReconciler replaces the pods running an outdated revision of the StatefulSet, which uses the
+// OnDelete update strategy, and the pods that need a restart to finish resizing their volume. One pod is
+// restarted at a time, followers first, and only when every node is in sync, so the leader is restarted
+// last and the cluster fails over exactly once.
+type RolloutReconciler struct {
+	*ResourceReconciler
+
+	// progress describes the current step of an ongoing rollout, reported in the status.
+	progress string
+}
+
+func (reconciler *ResourceReconciler) RolloutReconciler() *RolloutReconciler {
+	return &RolloutReconciler{
+		ResourceReconciler: reconciler,
+	}
+}
+
+func (b *RolloutReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	sts := &appsv1.StatefulSet{}
+	sts.Name = b.Instance.Name
+	sts.Namespace = b.Instance.Namespace
+	if err := b.GetItem(ctx, sts); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if sts.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType {
+		return ctrl.Result{}, nil
+	}
+
+	// The update revision is only known once the StatefulSet controller has seen the latest template.
+	if sts.Status.ObservedGeneration < sts.Generation || sts.Status.UpdateRevision == "" {
+		return b.wait("waiting for the StatefulSet controller")
+	}
+
+	pods, err := b.pods(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	outdated := []*corev1.Pod{}
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			return b.wait(fmt.Sprintf("waiting for %s to terminate", pod.Name))
+		}
+		if pod.Labels[appsv1.ControllerRevisionHashLabelKey] != sts.Status.UpdateRevision {
+			outdated = append(outdated, pod)
+			continue
+		}
+		// Storage providers without online expansion grow the file system when the volume is mounted again.
+		resizePending, err := b.offlineResizePending(ctx, pod)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if resizePending {
+			outdated = append(outdated, pod)
+		}
+	}
+
+	if len(outdated) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	unready := []*corev1.Pod{}
+	for _, pod := range pods {
+		if !podReady(pod) {
+			unready = append(unready, pod)
+		}
+	}
+	// An outdated pod stuck unready, e.g. after a failed rollout, is replaced once it has been unready for
+	// unreadyRestartDelay, a pod starting up or resyncing gets the time it needs. Only when no other pod is
+	// down, and the leader only once every follower runs the new revision.
+	if len(unready) == 1 && slices.Contains(outdated, unready[0]) && unreadyFor(unready[0]) >= unreadyRestartDelay {
+		pod := unready[0]
+		leader, err := b.CurrentLeader(ctx)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if pod.Name != leader || len(outdated) == 1 {
+			return b.restart(ctx, pod, "restarting unready pod")
+		}
+	}
+
+	if len(unready) > 0 {
+		return b.wait(fmt.Sprintf("waiting for %s to become ready", unready[0].Name))
+	}
+
+	if int32(len(pods)) < b.Instance.Spec.Replicas {
+		return b.wait("waiting for all replicas to be created")
+	}
+
+	if b.Instance.EtcdEndpoints() == nil {
+		return b.restart(ctx, outdated[0], "restarting")
+	}
+
+	inSync, err := b.InSyncReplicas(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if int32(inSync) < b.Instance.Spec.Replicas {
+		return b.wait(fmt.Sprintf("waiting for followers to sync, %d/%d in sync", inSync, b.Instance.Spec.Replicas))
+	}
+
+	leader, err := b.CurrentLeader(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if leader == "" {
+		return b.wait("waiting for a leader to be elected")
+	}
+
+	for _, pod := range slices.Backward(outdated) {
+		if pod.Name != leader {
+			return b.restart(ctx, pod, "restarting follower")
+		}
+	}
+
+	// All followers run the new revision and are in sync, stopping the leader hands over leadership to one of them.
+	return b.restart(ctx, outdated[0], "handing over leadership from")
+}
+
+// pods returns the pods of the instance sorted by ordinal.
+func (reconciler *ResourceReconciler) pods(ctx context.Context) ([]*corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	err := reconciler.Client.List(ctx, podList,
+		client.InNamespace(reconciler.Instance.Namespace),
+		client.MatchingLabels(utils.SelectorLabelsForLavinMQ(reconciler.Instance)))
+	if err != nil {
+		return nil, err
+	}
+
+	pods := make([]*corev1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		pods = append(pods, &podList.Items[i])
+	}
+
+	slices.SortFunc(pods, func(a, b *corev1.Pod) int {
+		return podOrdinal(a.Name) - podOrdinal(b.Name)
+	})
+
+	return pods, nil
+}
+
+func (b *RolloutReconciler) restart(ctx context.Context, pod *corev1.Pod, step string) (ctrl.Result, error) {
+	b.Logger.Info("Rolling out new revision", "step", step, "pod", pod.Name)
+	b.progress = fmt.Sprintf("%s %s", step, pod.Name)
+
+	if err := b.Client.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, fmt.Errorf("failed to delete pod %s: %w", pod.Name, err)
+	}
+
+	return ctrl.Result{Requeue: true}, nil
+}
+
+func (b *RolloutReconciler) wait(step string) (ctrl.Result, error) {
+	b.Logger.Info("Rollout in progress", "step", step)
+	b.progress = step
+
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// Progress returns the current step of an ongoing rollout, empty if there is none.
+func (b *RolloutReconciler) Progress() string {
+	return b.progress
+}
+
+// podOrdinal returns the ordinal suffix of a StatefulSet pod name.
+func podOrdinal(name string) int {
+	ordinal := 0
+	if i := strings.LastIndex(name, "-"); i >= 0 {
+		_, _ = fmt.Sscanf(name[i+1:], "%d", &ordinal)
+	}
+
+	return ordinal
+}
+
+// unreadyFor returns how long a pod hasn't been ready, since it was created if it never reported readiness.
+func unreadyFor(pod *corev1.Pod) time.Duration {
+	since := pod.CreationTimestamp.Time
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && !condition.LastTransitionTime.IsZero() {
+			since = condition.LastTransitionTime.Time
+		}
+	}
+
+	return time.Since(since)
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// Name returns the name of the rollout reconciler
+func (b *RolloutReconciler) Name() string {
+	return "rollout"
+}
@@ -0,0 +1,285 @@
+package reconciler_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/controller/utils"
+	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
+	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestRolloutFollowersFirst(t *testing.T) {
+	t.Parallel()
+	replicas := int32(3)
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{Replicas: &replicas})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	etcd := testutils.StartFakeEtcd(nil)
+	defer etcd.Close()
+
+	instance.Spec.EtcdEndpoints = []string{etcd.URL}
+	defer k8sClient.Delete(t.Context(), instance)
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	etcd.Set(instance.EtcdPrefix()+"/leader", fmt.Sprintf("tcp://%s-1.%s.%s.svc.cluster.local:5679", instance.Name, instance.Name, instance.Namespace))
+	etcd.Set(instance.EtcdPrefix()+"/isr", "a,b")
+
+	configMap := createConfigMap(t, instance, "initial_config")
+	defer deleteConfigMap(t, configMap)
+
+	resourceReconciler := &reconciler.ResourceReconciler{
+		Instance: instance,
+		Scheme:   scheme.Scheme,
+		Client:   k8sClient,
+	}
+	_, err = resourceReconciler.StatefulSetReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	sts := &appsv1.StatefulSet{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts))
+	assert.Equal(t, appsv1.OnDeleteStatefulSetStrategyType, sts.Spec.UpdateStrategy.Type)
+
+	sts.Status.ObservedGeneration = sts.Generation
+	sts.Status.Replicas = replicas
+	sts.Status.UpdateRevision = "new"
+	assert.NoError(t, k8sClient.Status().Update(t.Context(), sts))
+
+	for i := range int(replicas) {
+		createRevisionPod(t, instance, i, "old")
+	}
+
+	rc := resourceReconciler.RolloutReconciler()
+
+	t.Log("Followers aren't restarted while one of them is out of sync")
+	result, err := rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Contains(t, rc.Progress(), "2/3 in sync")
+	assert.Equal(t, []bool{true, true, true}, podsExist(t, instance))
+
+	etcd.Set(instance.EtcdPrefix()+"/isr", "a,b,c")
+
+	t.Log("The followers are restarted one at a time, the leader last")
+	for _, expected := range []int{2, 0, 1} {
+		rc = resourceReconciler.RolloutReconciler()
+		result, err = rc.Reconcile(t.Context())
+		assert.NoError(t, err)
+		assert.True(t, result.Requeue)
+
+		exists := podsExist(t, instance)
+		assert.False(t, exists[expected], "Expected %s-%d to be restarted", instance.Name, expected)
+		createRevisionPod(t, instance, expected, "new")
+	}
+
+	rc = resourceReconciler.RolloutReconciler()
+	result, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.False(t, result.Requeue)
+	assert.Empty(t, rc.Progress())
+}
+
+func TestRolloutUnreadyPod(t *testing.T) {
+	t.Parallel()
+	replicas := int32(3)
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{Replicas: &replicas})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	etcd := testutils.StartFakeEtcd(nil)
+	defer etcd.Close()
+
+	instance.Spec.EtcdEndpoints = []string{etcd.URL}
+	defer k8sClient.Delete(t.Context(), instance)
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	etcd.Set(instance.EtcdPrefix()+"/leader", fmt.Sprintf("tcp://%s-1.%s.%s.svc.cluster.local:5679", instance.Name, instance.Name, instance.Namespace))
+	etcd.Set(instance.EtcdPrefix()+"/isr", "a,b,c")
+
+	configMap := createConfigMap(t, instance, "initial_config")
+	defer deleteConfigMap(t, configMap)
+
+	resourceReconciler := &reconciler.ResourceReconciler{
+		Instance: instance,
+		Scheme:   scheme.Scheme,
+		Client:   k8sClient,
+	}
+	_, err = resourceReconciler.StatefulSetReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	sts := &appsv1.StatefulSet{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts))
+	sts.Status.ObservedGeneration = sts.Generation
+	sts.Status.Replicas = replicas
+	sts.Status.UpdateRevision = "new"
+	assert.NoError(t, k8sClient.Status().Update(t.Context(), sts))
+
+	for i := range int(replicas) {
+		createRevisionPod(t, instance, i, "old")
+	}
+
+	setReady := func(ordinal int, status corev1.ConditionStatus, since time.Duration) {
+		pod := &corev1.Pod{}
+		assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: fmt.Sprintf("%s-%d", instance.Name, ordinal), Namespace: instance.Namespace}, pod))
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type:               corev1.PodReady,
+			Status:             status,
+			LastTransitionTime: metav1.Time{Time: time.Now().Add(-since)},
+		}}
+		assert.NoError(t, k8sClient.Status().Update(t.Context(), pod))
+	}
+	reconcile := func() {
+		rc := resourceReconciler.RolloutReconciler()
+		result, err := rc.Reconcile(t.Context())
+		assert.NoError(t, err)
+		assert.True(t, result.Requeue)
+	}
+
+	t.Log("A pod that just became unready gets time to recover")
+	setReady(2, corev1.ConditionFalse, time.Second)
+	reconcile()
+	assert.Equal(t, []bool{true, true, true}, podsExist(t, instance))
+
+	t.Log("A pod unready for long isn't restarted while another pod is down")
+	setReady(2, corev1.ConditionFalse, 10*time.Minute)
+	setReady(0, corev1.ConditionFalse, time.Second)
+	reconcile()
+	assert.Equal(t, []bool{true, true, true}, podsExist(t, instance))
+
+	setReady(0, corev1.ConditionTrue, time.Second)
+	reconcile()
+	assert.Equal(t, []bool{true, true, false}, podsExist(t, instance))
+	createRevisionPod(t, instance, 2, "new")
+
+	t.Log("An unready leader isn't restarted before the outdated followers")
+	setReady(1, corev1.ConditionFalse, 10*time.Minute)
+	reconcile()
+	assert.Equal(t, []bool{true, true, true}, podsExist(t, instance))
+}
+
+func createRevisionPod(t *testing.T, instance *v1alpha1.LavinMQ, ordinal int, revision string) {
+	labels := utils.LabelsForLavinMQ(instance)
+	labels[appsv1.ControllerRevisionHashLabelKey] = revision
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", instance.Name, ordinal),
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "lavinmq", Image: instance.Spec.Image}},
+		},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), pod))
+
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	assert.NoError(t, k8sClient.Status().Update(t.Context(), pod))
+}
+
+func podsExist(t *testing.T, instance *v1alpha1.LavinMQ) []bool {
+	exists := []bool{}
+	for i := range int(instance.Spec.Replicas) {
+		err := k8sClient.Get(t.Context(), types.NamespacedName{Name: fmt.Sprintf("%s-%d", instance.Name, i), Namespace: instance.Namespace}, &corev1.Pod{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			assert.NoError(t, err)
+		}
+		exists = append(exists, err == nil)
+	}
+
+	return exists
+}
+
+func TestRolloutOfflineVolumeResize(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	configMap := createConfigMap(t, instance, "initial_config")
+	defer deleteConfigMap(t, configMap)
+
+	resourceReconciler := &reconciler.ResourceReconciler{
+		Instance: instance,
+		Scheme:   scheme.Scheme,
+		Client:   k8sClient,
+	}
+	_, err = resourceReconciler.StatefulSetReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	sts := &appsv1.StatefulSet{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts))
+	sts.Status.ObservedGeneration = sts.Generation
+	sts.Status.Replicas = 1
+	sts.Status.UpdateRevision = "current"
+	assert.NoError(t, k8sClient.Status().Update(t.Context(), sts))
+
+	createRevisionPod(t, instance, 0, "current")
+	pod := &corev1.Pod{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: fmt.Sprintf("%s-0", instance.Name), Namespace: instance.Namespace}, pod))
+	pod.Status.StartTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+	assert.NoError(t, k8sClient.Status().Update(t.Context(), pod))
+
+	_, err = resourceReconciler.PVCReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+	defer cleanupPvcResources(t, instance)
+
+	pendingSince := func(since time.Duration) {
+		pvc := &corev1.PersistentVolumeClaim{}
+		assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: fmt.Sprintf("data-%s-0", instance.Name), Namespace: instance.Namespace}, pvc))
+		pvc.Status.Phase = corev1.ClaimBound
+		pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+		pvc.Status.Conditions = []corev1.PersistentVolumeClaimCondition{{
+			Type:               corev1.PersistentVolumeClaimFileSystemResizePending,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.Time{Time: time.Now().Add(-since)},
+		}}
+		assert.NoError(t, k8sClient.Status().Update(t.Context(), pvc))
+
+		_, err := resourceReconciler.PVCReconciler().Reconcile(t.Context())
+		assert.NoError(t, err)
+	}
+
+	t.Log("The pod isn't restarted while the file system can still be resized online")
+	pendingSince(time.Second)
+	result, err := resourceReconciler.RolloutReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.False(t, result.Requeue)
+	assert.Equal(t, []bool{true}, podsExist(t, instance))
+
+	t.Log("The pod is restarted once the grace period has passed")
+	pendingSince(10 * time.Minute)
+	result, err = resourceReconciler.RolloutReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Equal(t, []bool{false}, podsExist(t, instance))
+
+	t.Log("A pod started after the resize became pending isn't restarted again")
+	createRevisionPod(t, instance, 0, "current")
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: fmt.Sprintf("%s-0", instance.Name), Namespace: instance.Namespace}, pod))
+	pod.Status.StartTime = &metav1.Time{Time: time.Now()}
+	assert.NoError(t, k8sClient.Status().Update(t.Context(), pod))
+
+	result, err = resourceReconciler.RolloutReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.False(t, result.Requeue)
+	assert.Equal(t, []bool{true}, podsExist(t, instance))
+}
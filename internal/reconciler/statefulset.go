@@ -4,23 +4,34 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"reflect"
 	"slices"
 
 	"github.com/cloudamqp/lavinmq-operator/internal/controller/utils"
-	resource_utils "github.com/cloudamqp/lavinmq-operator/internal/reconciler/utils"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+const podTemplateHashAnnotation = "lavinmq.cloudamqp.com/pod-template-hash"
+
+// leaderHandoverAnnotation records the election a scale down handed leadership over from, as <pod>/<revision>.
+const leaderHandoverAnnotation = "lavinmq.cloudamqp.com/leader-handover"
+
 type StatefulSetReconciler struct {
 	*ResourceReconciler
+
+	// progress describes what a scale down is waiting on, reported in the status.
+	progress string
 }
 
 func (reconciler *ResourceReconciler) StatefulSetReconciler() *StatefulSetReconciler {
@@ -30,15 +41,40 @@ func (reconciler *ResourceReconciler) StatefulSetReconciler() *StatefulSetReconc
 }
 
 func (b *StatefulSetReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	migrating, err := b.migrateSelector(ctx)
+	if err != nil {
+		b.Logger.Error(err, "Failed migrating statefulset selector")
+		return ctrl.Result{}, err
+	}
+	if migrating {
+		return ctrl.Result{Requeue: true}, nil
+	}
+
 	statefulset, err := b.newObject(ctx)
 	if err != nil {
 		b.Logger.Error(err, "Failed creating statefulset")
 		return ctrl.Result{}, err
 	}
 
+	waitingForEtcd := false
 	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		if err := b.GetItem(ctx, statefulset); err != nil {
 			if apierrors.IsNotFound(err) {
+				// The nodes can't elect a leader until a managed etcd cluster has quorum.
+				ready, err := b.etcdQuorumReady(ctx)
+				if err != nil {
+					return err
+				}
+				if !ready {
+					waitingForEtcd = true
+					return nil
+				}
+
+				// A volume migration keeps the StatefulSet deleted while one of the nodes is stopped.
+				if b.migratingClaim() != "" {
+					return nil
+				}
+
 				b.CreateItem(ctx, statefulset)
 				return nil
 			}
@@ -64,7 +100,7 @@ func (b *StatefulSetReconciler) Reconcile(ctx context.Context) (ctrl.Result, err
 		return nil
 	})
 
-	return ctrl.Result{}, err
+	return ctrl.Result{Requeue: waitingForEtcd || b.progress != ""}, err
 }
 
 func (b *StatefulSetReconciler) newObject(ctx context.Context) (*appsv1.StatefulSet, error) {
@@ -83,33 +119,88 @@ func (b *StatefulSetReconciler) newObject(ctx context.Context) (*appsv1.Stateful
 	if err := b.setConfigHashAnnotation(ctx, sts); err != nil {
 		return nil, err
 	}
+	if err := b.setTlsHashAnnotation(ctx, sts); err != nil {
+		return nil, err
+	}
+	if err := b.appendDefaultUser(ctx, sts); err != nil {
+		return nil, err
+	}
+	if err := b.appendOperatorUser(ctx, sts); err != nil {
+		return nil, err
+	}
+	if err := b.appendEtcdSecrets(ctx, sts); err != nil {
+		return nil, err
+	}
+	if err := b.applyPodTemplate(sts); err != nil {
+		return nil, err
+	}
+	if err := setPodTemplateHashAnnotation(sts); err != nil {
+		return nil, err
+	}
 
 	return sts, nil
 }
 
+// applyPodTemplate strategic-merges spec.podTemplate over the generated pod template.
+func (b *StatefulSetReconciler) applyPodTemplate(sts *appsv1.StatefulSet) error {
+	if b.Instance.Spec.PodTemplate == nil || len(b.Instance.Spec.PodTemplate.Raw) == 0 {
+		return nil
+	}
+
+	original, err := json.Marshal(sts.Spec.Template)
+	if err != nil {
+		return err
+	}
+
+	merged, err := strategicpatch.StrategicMergePatch(original, b.Instance.Spec.PodTemplate.Raw, corev1.PodTemplateSpec{})
+	if err != nil {
+		return fmt.Errorf("failed to merge spec.podTemplate: %w", err)
+	}
+
+	template := corev1.PodTemplateSpec{}
+	if err := json.Unmarshal(merged, &template); err != nil {
+		return fmt.Errorf("failed to merge spec.podTemplate: %w", err)
+	}
+
+	// The pods have to keep matching the immutable selector
+	if template.Labels == nil {
+		template.Labels = map[string]string{}
+	}
+	maps.Copy(template.Labels, utils.SelectorLabelsForLavinMQ(b.Instance))
+
+	sts.Spec.Template = template
+
+	return nil
+}
+
 func (b *StatefulSetReconciler) appendSpec(sts *appsv1.StatefulSet) *appsv1.StatefulSet {
 	configVolumeName := b.Instance.Name
 
 	sts.Spec = appsv1.StatefulSetSpec{
 		Replicas: &b.Instance.Spec.Replicas,
 		Selector: &metav1.LabelSelector{
-			MatchLabels: sts.Labels,
+			MatchLabels: utils.SelectorLabelsForLavinMQ(b.Instance),
 		},
 		ServiceName: b.Instance.Name,
+		// Pods are replaced by the rollout reconciler, followers first and the leader last.
+		UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+			Type: appsv1.OnDeleteStatefulSetStrategyType,
+		},
 		Template: corev1.PodTemplateSpec{
 			ObjectMeta: metav1.ObjectMeta{
-				Labels:      sts.Labels,
+				Labels:      utils.LabelsForLavinMQ(b.Instance),
 				Annotations: make(map[string]string),
 			},
 			Spec: corev1.PodSpec{
 				Containers: []corev1.Container{
 					{
-						Name:      "lavinmq",
-						Image:     b.Instance.Spec.Image,
-						Resources: b.Instance.Spec.Resources,
-						Command:   []string{"/usr/bin/lavinmq"},
-						Args:      b.cliArgs(),
-						Ports:     b.portsFromSpec(),
+						Name:            "lavinmq",
+						Image:           b.Instance.Spec.Image,
+						Resources:       b.Instance.Spec.Resources,
+						Command:         []string{"/usr/bin/lavinmq"},
+						Args:            b.cliArgs(),
+						Ports:           b.portsFromSpec(),
+						SecurityContext: b.Instance.Spec.SecurityContext,
 						VolumeMounts: []corev1.VolumeMount{
 							{
 								Name:      "data",
@@ -179,7 +270,15 @@ func (b *StatefulSetReconciler) appendSpec(sts *appsv1.StatefulSet) *appsv1.Stat
 						},
 					},
 				},
-				Affinity: b.Instance.Spec.Affinity,
+				Affinity:                  b.Instance.Spec.Affinity,
+				Tolerations:               b.Instance.Spec.Tolerations,
+				NodeSelector:              b.Instance.Spec.NodeSelector,
+				TopologySpreadConstraints: b.Instance.Spec.TopologySpreadConstraints,
+				PriorityClassName:         b.Instance.Spec.PriorityClassName,
+				SchedulerName:             b.Instance.Spec.SchedulerName,
+				ServiceAccountName:        b.Instance.Spec.ServiceAccountName,
+				ImagePullSecrets:          b.Instance.Spec.ImagePullSecrets,
+				SecurityContext:           b.Instance.Spec.PodSecurityContext,
 			},
 		},
 		VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
@@ -197,7 +296,7 @@ func (b *StatefulSetReconciler) appendSpec(sts *appsv1.StatefulSet) *appsv1.Stat
 }
 func (b *StatefulSetReconciler) portsFromSpec() []corev1.ContainerPort {
 	ports := []corev1.ContainerPort{}
-	if b.Instance.Spec.EtcdEndpoints != nil {
+	if b.Instance.EtcdEndpoints() != nil {
 		ports = appendContainerPort(ports, 5679, "clustering")
 	}
 
@@ -245,8 +344,12 @@ func (b *StatefulSetReconciler) cliArgs() []string {
 
 	if b.Instance.Spec.Replicas > 0 {
 		// Clustering config is currently spread between CLI here and in the config file.
+		scheme := "tcp"
+		if b.Instance.Spec.Config.Clustering.Tls {
+			scheme = "tls"
+		}
 		clusteringArgs := []string{
-			fmt.Sprintf("--clustering-advertised-uri=tcp://$(POD_NAME).%s.$(POD_NAMESPACE).svc.cluster.local:5679", b.Instance.Name),
+			fmt.Sprintf("--clustering-advertised-uri=%s://$(POD_NAME).%s.$(POD_NAMESPACE).svc.cluster.local:5679", scheme, b.Instance.Name),
 		}
 		defaultArgs = append(defaultArgs, clusteringArgs...)
 	}
@@ -255,7 +358,8 @@ func (b *StatefulSetReconciler) cliArgs() []string {
 }
 
 func (b *StatefulSetReconciler) appendTlsConfig(sts *appsv1.StatefulSet) {
-	if b.Instance.Spec.TlsSecret == nil {
+	secretName := b.Instance.TlsSecretName()
+	if secretName == "" {
 		return
 	}
 
@@ -273,7 +377,7 @@ func (b *StatefulSetReconciler) appendTlsConfig(sts *appsv1.StatefulSet) {
 			Name: "tls",
 			VolumeSource: corev1.VolumeSource{
 				Secret: &corev1.SecretVolumeSource{
-					SecretName: b.Instance.Spec.TlsSecret.Name,
+					SecretName: secretName,
 				},
 			},
 		},
@@ -311,81 +415,436 @@ func (b *StatefulSetReconciler) setConfigHashAnnotation(ctx context.Context, sts
 	return nil
 }
 
-func (b *StatefulSetReconciler) updateFields(ctx context.Context, sts *appsv1.StatefulSet) error {
-	if *sts.Spec.Replicas != int32(b.Instance.Spec.Replicas) {
-		b.Logger.Info("Replicas changed", "old", sts.Spec.Replicas, "new", b.Instance.Spec.Replicas)
-		// TODO: Add support for scaling.
-		sts.Spec.Replicas = &b.Instance.Spec.Replicas
+// Used to roll the pods when the content of the TLS secret changes, e.g. on certificate renewal.
+// The mounted files are updated in place by the kubelet, but LavinMQ only reads them on start.
+func (b *StatefulSetReconciler) setTlsHashAnnotation(ctx context.Context, sts *appsv1.StatefulSet) error {
+	secretName := b.Instance.TlsSecretName()
+	if secretName == "" {
+		return nil
 	}
 
-	b.diffTemplate(&sts.Spec.Template.Spec)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: b.Instance.Namespace,
+		},
+	}
 
-	if err := b.setConfigHashAnnotation(ctx, sts); err != nil {
+	if err := b.GetItem(ctx, secret); err != nil {
+		// Certificates issued by cert-manager show up once issued, the pods wait for the volume until then.
+		if apierrors.IsNotFound(err) && b.Instance.Spec.Tls != nil {
+			return nil
+		}
+		b.Logger.Error(err, "Failed to fetch TLS Secret", "name", secret.Name, "namespace", secret.Namespace)
 		return err
 	}
 
+	sts.Spec.Template.ObjectMeta.Annotations["tls-hash"] = secretDataHash(secret)
+
 	return nil
 }
 
-func (b *StatefulSetReconciler) diffTemplate(old *corev1.PodSpec) {
-	// Pointer the old as that's the object we're mutating
-	oldContainer := &old.Containers[0]
+// appendEtcdSecrets mounts the client certificate and credentials for etcd where the clustering section of
+// the config expects them. Like the TLS secret, changing their content restarts the pods.
+func (b *StatefulSetReconciler) appendEtcdSecrets(ctx context.Context, sts *appsv1.StatefulSet) error {
+	if b.Instance.EtcdEndpoints() == nil {
+		return nil
+	}
+
+	credentials := ""
+	if etcd := b.Instance.Spec.Etcd; etcd != nil && etcd.CredentialsSecretRef != nil {
+		credentials = etcd.CredentialsSecretRef.Name
+	}
 
-	if oldContainer.Image != b.Instance.Spec.Image {
-		oldContainer.Image = b.Instance.Spec.Image
+	mounts := []struct {
+		volume string
+		path   string
+		secret string
+	}{
+		{"etcd-tls", etcdTlsPath, b.Instance.EtcdTlsSecretName()},
+		{"etcd-credentials", etcdCredentialsPath, credentials},
 	}
 
-	if !resource_utils.EqualResourceRequirements(oldContainer.Resources, b.Instance.Spec.Resources) {
-		b.Logger.Info("Container resources changed, updating")
-		oldContainer.Resources = b.Instance.Spec.Resources
+	hash := md5.New()
+	mounted := false
+	container := &sts.Spec.Template.Spec.Containers[0]
+	for _, mount := range mounts {
+		if mount.secret == "" {
+			continue
+		}
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      mount.secret,
+				Namespace: b.Instance.Namespace,
+			},
+		}
+		if err := b.GetItem(ctx, secret); err != nil {
+			b.Logger.Error(err, "Failed to fetch etcd Secret", "name", secret.Name)
+			return err
+		}
+		hash.Write([]byte(secretDataHash(secret)))
+		mounted = true
+
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      mount.volume,
+			MountPath: mount.path,
+			ReadOnly:  true,
+		})
+		sts.Spec.Template.Spec.Volumes = append(sts.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: mount.volume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: mount.secret},
+			},
+		})
 	}
 
-	cliArgs := b.cliArgs()
-	// TODO: Expand this to own methods and granular checks
-	if !reflect.DeepEqual(oldContainer.Args, cliArgs) {
-		b.Logger.Info("cli args changed, updating")
-		oldContainer.Args = cliArgs
+	if mounted {
+		sts.Spec.Template.Annotations["etcd-secrets-hash"] = hex.EncodeToString(hash.Sum(nil))
 	}
 
-	if !reflect.DeepEqual(oldContainer.Ports, b.portsFromSpec()) {
-		b.Logger.Info("ports changed, updating")
-		oldContainer.Ports = b.portsFromSpec()
+	return nil
+}
+
+// secretDataHash returns a hash over all keys and values of the secret.
+func secretDataHash(secret *corev1.Secret) string {
+	hash := md5.New()
+	for _, key := range slices.Sorted(maps.Keys(secret.Data)) {
+		hash.Write([]byte(key))
+		hash.Write(secret.Data[key])
 	}
 
-	if !reflect.DeepEqual(old.Affinity, b.Instance.Spec.Affinity) {
-		b.Logger.Info("Affinity changed, updating")
-		old.Affinity = b.Instance.Spec.Affinity
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// appendDefaultUser passes the default user from spec.defaultUserSecretRef to LavinMQ through the environment,
+// so the password hash never ends up in the ConfigMap. The pods are restarted when the credentials change.
+func (b *StatefulSetReconciler) appendDefaultUser(ctx context.Context, sts *appsv1.StatefulSet) error {
+	ref := b.Instance.Spec.DefaultUserSecretRef
+	if ref == nil {
+		return nil
 	}
 
-	index := slices.IndexFunc(old.Volumes, func(v corev1.Volume) bool {
-		return v.Name == "tls"
-	})
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ref.Name,
+			Namespace: b.Instance.Namespace,
+		},
+	}
 
-	if index != -1 {
-		secretName := old.Volumes[index].VolumeSource.Secret.SecretName
-		// Checks if the secret name is the same as the one in the instance spec
-		if b.Instance.Spec.TlsSecret != nil && b.Instance.Spec.TlsSecret.Name != secretName {
-			b.Logger.Info("tls secret changed, updating")
-			old.Volumes[index] = corev1.Volume{
-				Name: "tls",
-				VolumeSource: corev1.VolumeSource{
-					Secret: &corev1.SecretVolumeSource{
-						SecretName: b.Instance.Spec.TlsSecret.Name,
-					},
+	if err := b.GetItem(ctx, secret); err != nil {
+		b.Logger.Error(err, "Failed to fetch default user Secret", "name", secret.Name)
+		return err
+	}
+
+	secretEnv := func(name, key string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: *ref,
+					Key:                  key,
 				},
-			}
+			},
 		}
-	} else if b.Instance.Spec.TlsSecret != nil {
-		b.Logger.Info("adding tls secret to volumes")
-		old.Volumes = append(old.Volumes, corev1.Volume{
-			Name: "tls",
-			VolumeSource: corev1.VolumeSource{
-				Secret: &corev1.SecretVolumeSource{
-					SecretName: b.Instance.Spec.TlsSecret.Name,
+	}
+
+	container := &sts.Spec.Template.Spec.Containers[0]
+	container.Env = append(container.Env,
+		secretEnv("LAVINMQ_DEFAULT_USER", DefaultUserUsernameKey),
+		secretEnv("LAVINMQ_DEFAULT_PASSWORD_HASH", DefaultUserPasswordHashKey),
+	)
+	container.Args = append(container.Args,
+		"--default-user=$(LAVINMQ_DEFAULT_USER)",
+		"--default-password=$(LAVINMQ_DEFAULT_PASSWORD_HASH)",
+	)
+
+	hash := md5.Sum(append(append([]byte{}, secret.Data[DefaultUserUsernameKey]...), secret.Data[DefaultUserPasswordHashKey]...))
+	sts.Spec.Template.Annotations["default-user-hash"] = hex.EncodeToString(hash[:])
+
+	return nil
+}
+
+// operatorUserScript creates the operator user, or resets its password, once the node serves as leader. The
+// user is replicated to the followers, which skip it. It never fails, the operator retries through the API.
+const operatorUserScript = `i=0
+while [ $i -lt 120 ]; do
+  if /usr/bin/lavinmqctl status >/dev/null 2>&1; then
+    /usr/bin/lavinmqctl add_user "$LAVINMQ_OPERATOR_USER" "$LAVINMQ_OPERATOR_PASSWORD" >/dev/null 2>&1 ||
+      /usr/bin/lavinmqctl change_password "$LAVINMQ_OPERATOR_USER" "$LAVINMQ_OPERATOR_PASSWORD"
+    /usr/bin/lavinmqctl set_user_tags "$LAVINMQ_OPERATOR_USER" administrator
+    exit 0
+  fi
+  /usr/bin/lavinmqctl status 2>&1 | grep -q follower && exit 0
+  i=$((i + 1))
+  sleep 1
+done
+exit 0`
+
+// appendOperatorUser sets up the administrator the operator uses for the management API. lavinmqctl talks to
+// the node over its local socket, so the user is created from a postStart hook without other credentials.
+func (b *StatefulSetReconciler) appendOperatorUser(ctx context.Context, sts *appsv1.StatefulSet) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      OperatorUserSecretName(b.Instance),
+			Namespace: b.Instance.Namespace,
+		},
+	}
+
+	// The Secret is generated by the OperatorUserReconciler before the StatefulSet is reconciled, the pods
+	// wait for it otherwise.
+	if err := b.GetItem(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+		b.Logger.Error(err, "Failed to fetch operator user Secret", "name", secret.Name)
+		return err
+	}
+
+	secretEnv := func(name, key string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: OperatorUserSecretName(b.Instance)},
+					Key:                  key,
 				},
 			},
-		})
+		}
+	}
+
+	container := &sts.Spec.Template.Spec.Containers[0]
+	container.Env = append(container.Env,
+		secretEnv("LAVINMQ_OPERATOR_USER", corev1.BasicAuthUsernameKey),
+		secretEnv("LAVINMQ_OPERATOR_PASSWORD", corev1.BasicAuthPasswordKey),
+	)
+	container.Lifecycle = &corev1.Lifecycle{
+		PostStart: &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{Command: []string{"/bin/sh", "-c", operatorUserScript}},
+		},
+	}
+
+	hash := md5.Sum(append(append([]byte{}, secret.Data[corev1.BasicAuthUsernameKey]...), secret.Data[corev1.BasicAuthPasswordKey]...))
+	sts.Spec.Template.Annotations["operator-user-hash"] = hex.EncodeToString(hash[:])
+
+	return nil
+}
+
+// The hash of the generated pod template is kept on the statefulset to tell when the template changed,
+// the template itself can't be compared as the API server fills in defaults.
+func setPodTemplateHashAnnotation(sts *appsv1.StatefulSet) error {
+	data, err := json.Marshal(sts.Spec.Template)
+	if err != nil {
+		return err
+	}
+
+	hash := md5.Sum(data)
+	if sts.Annotations == nil {
+		sts.Annotations = make(map[string]string)
+	}
+
+	sts.Annotations[podTemplateHashAnnotation] = hex.EncodeToString(hash[:])
+
+	return nil
+}
+
+// migrateSelector recreates StatefulSets created before the selector was scoped to the instance.
+// The selector of a StatefulSet is immutable, so the old StatefulSet is deleted while orphaning its pods.
+// The pods are relabeled first so the new StatefulSet adopts them, the PVCs are left untouched.
+// Returns true while the migration is in progress.
+func (b *StatefulSetReconciler) migrateSelector(ctx context.Context) (bool, error) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.Instance.Name,
+			Namespace: b.Instance.Namespace,
+		},
+	}
+
+	if err := b.GetItem(ctx, sts); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if sts.DeletionTimestamp != nil {
+		b.Logger.Info("Waiting for old statefulset to be deleted", "name", sts.Name)
+		return true, nil
+	}
+
+	selectorLabels := utils.SelectorLabelsForLavinMQ(b.Instance)
+	if sts.Spec.Selector != nil && reflect.DeepEqual(sts.Spec.Selector.MatchLabels, selectorLabels) {
+		return false, nil
+	}
+
+	b.Logger.Info("Statefulset selector outdated, recreating statefulset while keeping pods and volumes", "name", sts.Name)
+
+	pods := &corev1.PodList{}
+	listOpts := []client.ListOption{client.InNamespace(sts.Namespace)}
+	if sts.Spec.Selector != nil {
+		listOpts = append(listOpts, client.MatchingLabels(sts.Spec.Selector.MatchLabels))
+	}
+	if err := b.Client.List(ctx, pods, listOpts...); err != nil {
+		return false, err
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !metav1.IsControlledBy(pod, sts) {
+			continue
+		}
+
+		patch := client.MergeFrom(pod.DeepCopy())
+		if pod.Labels == nil {
+			pod.Labels = map[string]string{}
+		}
+		for k, v := range selectorLabels {
+			pod.Labels[k] = v
+		}
+		if err := b.Client.Patch(ctx, pod, patch); err != nil {
+			b.Logger.Error(err, "Failed relabeling pod", "name", pod.Name)
+			return false, err
+		}
+	}
+
+	err := b.Client.Delete(ctx, sts, client.PropagationPolicy(metav1.DeletePropagationOrphan))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (b *StatefulSetReconciler) updateFields(ctx context.Context, sts *appsv1.StatefulSet) error {
+	labels := utils.LabelsForLavinMQ(b.Instance)
+	if !reflect.DeepEqual(sts.Labels, labels) {
+		b.Logger.Info("Labels changed, updating")
+		sts.Labels = labels
+	}
+
+	if *sts.Spec.Replicas != b.Instance.Spec.Replicas {
+		replicas, err := b.scaleTo(ctx, sts)
+		if err != nil {
+			return err
+		}
+		if replicas != *sts.Spec.Replicas {
+			b.Logger.Info("Replicas changed", "old", *sts.Spec.Replicas, "new", replicas)
+			sts.Spec.Replicas = &replicas
+		}
+	}
+
+	desired, err := b.newObject(ctx)
+	if err != nil {
+		return err
+	}
+
+	if sts.Spec.UpdateStrategy.Type != desired.Spec.UpdateStrategy.Type {
+		b.Logger.Info("Update strategy changed, updating", "new", desired.Spec.UpdateStrategy.Type)
+		sts.Spec.UpdateStrategy = desired.Spec.UpdateStrategy
+	}
+
+	b.diffTemplate(sts, desired)
+
+	return nil
+}
+
+// scaleTo returns the number of replicas to run. Scaling up is immediate, a scale down waits until every node
+// is ready and in sync and the leader isn't one of the pods being removed, so no replicated data is lost.
+func (b *StatefulSetReconciler) scaleTo(ctx context.Context, sts *appsv1.StatefulSet) (int32, error) {
+	current := *sts.Spec.Replicas
+	desired := b.Instance.Spec.Replicas
+	if desired >= current || b.Instance.EtcdEndpoints() == nil {
+		delete(sts.Annotations, leaderHandoverAnnotation)
+		return desired, nil
+	}
+
+	pods, err := b.pods(ctx)
+	if err != nil {
+		return current, err
+	}
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			b.waitForScaleDown(fmt.Sprintf("waiting for %s to terminate", pod.Name))
+			return current, nil
+		}
+	}
+	for _, pod := range pods {
+		if !podReady(pod) {
+			b.waitForScaleDown(fmt.Sprintf("waiting for %s to become ready", pod.Name))
+			return current, nil
+		}
+	}
+
+	leaderKey, err := b.leaderKey(ctx)
+	if err != nil {
+		return current, err
+	}
+	if leaderKey == nil {
+		b.waitForScaleDown("waiting for a leader to be elected")
+		return current, nil
+	}
+	leader := podNameFromAdvertisedURI(leaderKey.Value)
+	election := fmt.Sprintf("%s/%d", leader, leaderKey.CreateRevision)
+
+	// The election key outlives the restarted leader until its lease expires, nothing is decided on it before.
+	if sts.Annotations[leaderHandoverAnnotation] == election {
+		b.waitForScaleDown(fmt.Sprintf("waiting for leadership to move from %s", leader))
+		return current, nil
+	}
+	delete(sts.Annotations, leaderHandoverAnnotation)
+
+	inSync, err := b.InSyncReplicas(ctx)
+	if err != nil {
+		return current, err
+	}
+	if int32(inSync) < current {
+		b.waitForScaleDown(fmt.Sprintf("waiting for followers to sync, %d/%d in sync", inSync, current))
+		return current, nil
+	}
+
+	if int32(podOrdinal(leader)) >= desired {
+		// The leader is restarted together with the other pods being removed, leaving only the pods that are
+		// kept to take over, so leadership is handed over once.
+		b.Logger.Info("Leader would be removed by scale down, handing over leadership", "pod", leader)
+		for _, pod := range pods {
+			if int32(podOrdinal(pod.Name)) < desired {
+				continue
+			}
+			if err := b.Client.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+				return current, fmt.Errorf("failed to delete pod %s: %w", pod.Name, err)
+			}
+		}
+
+		if sts.Annotations == nil {
+			sts.Annotations = map[string]string{}
+		}
+		sts.Annotations[leaderHandoverAnnotation] = election
+		b.waitForScaleDown(fmt.Sprintf("handing over leadership from %s", leader))
+		return current, nil
 	}
+
+	return desired, nil
+}
+
+func (b *StatefulSetReconciler) waitForScaleDown(step string) {
+	b.Logger.Info("Scale down in progress", "step", step)
+	b.progress = step
+}
+
+// Progress returns what a scale down is waiting on, empty if there is none.
+func (b *StatefulSetReconciler) Progress() string {
+	return b.progress
+}
+
+// diffTemplate replaces the pod template with the desired one, generated and merged the same way as on creation.
+// Fields left unset are defaulted by the API server again, so an unchanged template doesn't roll the pods.
+func (b *StatefulSetReconciler) diffTemplate(sts *appsv1.StatefulSet, desired *appsv1.StatefulSet) {
+	if sts.Annotations[podTemplateHashAnnotation] != desired.Annotations[podTemplateHashAnnotation] {
+		b.Logger.Info("Pod template changed, updating")
+		if sts.Annotations == nil {
+			sts.Annotations = make(map[string]string)
+		}
+		sts.Annotations[podTemplateHashAnnotation] = desired.Annotations[podTemplateHashAnnotation]
+	}
+
+	sts.Spec.Template = desired.Spec.Template
 }
 
 // Name returns the name of the statefulset reconciler
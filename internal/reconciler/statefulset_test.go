@@ -1,16 +1,20 @@
 package reconciler_test
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
 
 	cloudamqpcomv1alpha1 "github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
 	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
@@ -157,22 +161,22 @@ func TestUpdateContainerResources(t *testing.T) {
 func TestStsAffinity(t *testing.T) {
 	t.Parallel()
 	affinity := &corev1.Affinity{
-				NodeAffinity: &corev1.NodeAffinity{
-					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
-						NodeSelectorTerms: []corev1.NodeSelectorTerm{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
 							{
-								MatchExpressions: []corev1.NodeSelectorRequirement{
-									{
-										Key:      "foo",
-										Operator: "Exists",
-										Values:   nil,
-									},
-								},
+								Key:      "foo",
+								Operator: "Exists",
+								Values:   nil,
 							},
 						},
 					},
 				},
-			}
+			},
+		},
+	}
 	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
 	instance.Spec.Affinity = affinity
 
@@ -201,7 +205,7 @@ func TestStsAffinity(t *testing.T) {
 	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts)
 	assert.NoErrorf(t, err, "Failed to get statefulset")
 
-	assert.True(t, reflect.DeepEqual(selector, sts.Spec.Template.Spec.Affinity))
+	assert.True(t, reflect.DeepEqual(affinity, sts.Spec.Template.Spec.Affinity))
 }
 
 func TestConfigHashAnnotation(t *testing.T) {
@@ -256,6 +260,173 @@ func TestConfigHashAnnotation(t *testing.T) {
 	assert.NotEqual(t, initialHash, updatedHash, "Config hash should change when ConfigMap content changes")
 }
 
+func TestStatefulSetSelectorScopedToInstance(t *testing.T) {
+	t.Parallel()
+	namespace := envconf.RandomName("namespace", 15)
+	err := testutils.CreateNamespace(t.Context(), k8sClient, namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, namespace)
+
+	selectors := []map[string]string{}
+	for range 2 {
+		instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{Namespace: &namespace})
+		instance.Labels = map[string]string{"team": "messaging"}
+
+		configMap := createConfigMap(t, instance, "initial_config")
+		defer deleteConfigMap(t, configMap)
+
+		err = k8sClient.Create(t.Context(), instance)
+		assert.NoErrorf(t, err, "Failed to create instance")
+
+		rc := &reconciler.StatefulSetReconciler{
+			ResourceReconciler: &reconciler.ResourceReconciler{
+				Instance: instance,
+				Scheme:   scheme.Scheme,
+				Client:   k8sClient,
+			},
+		}
+		_, err = rc.Reconcile(t.Context())
+		assert.NoErrorf(t, err, "Failed to reconcile instance")
+
+		sts := &appsv1.StatefulSet{}
+		err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts)
+		assert.NoErrorf(t, err, "Failed to get statefulset")
+
+		assert.Equal(t, instance.Name, sts.Spec.Selector.MatchLabels["app.kubernetes.io/instance"])
+		assert.NotContains(t, sts.Spec.Selector.MatchLabels, "team")
+		assert.Equal(t, "messaging", sts.Spec.Template.Labels["team"])
+		selectors = append(selectors, sts.Spec.Selector.MatchLabels)
+	}
+
+	assert.NotEqual(t, selectors[0], selectors[1])
+}
+
+func TestStatefulSetLabelChange(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	configMap := createConfigMap(t, instance, "initial_config")
+	defer deleteConfigMap(t, configMap)
+
+	rc := &reconciler.StatefulSetReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	err = k8sClient.Create(t.Context(), instance)
+	assert.NoErrorf(t, err, "Failed to create instance")
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoErrorf(t, err, "Failed to reconcile instance")
+
+	instance.Labels = map[string]string{"team": "messaging"}
+	err = k8sClient.Update(t.Context(), instance)
+	assert.NoErrorf(t, err, "Failed to update instance")
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoErrorf(t, err, "Changing instance labels should not touch the immutable selector")
+
+	sts := &appsv1.StatefulSet{}
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts)
+	assert.NoErrorf(t, err, "Failed to get statefulset")
+
+	assert.Equal(t, "messaging", sts.Labels["team"])
+	assert.Equal(t, "messaging", sts.Spec.Template.Labels["team"])
+	assert.NotContains(t, sts.Spec.Selector.MatchLabels, "team")
+}
+
+func TestStatefulSetSelectorMigration(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	configMap := createConfigMap(t, instance, "initial_config")
+	defer deleteConfigMap(t, configMap)
+
+	err = k8sClient.Create(t.Context(), instance)
+	assert.NoErrorf(t, err, "Failed to create instance")
+
+	t.Log("Creating a statefulset with the legacy selector")
+	legacyLabels := map[string]string{
+		"app.kubernetes.io/name":       "lavinmq-operator",
+		"app.kubernetes.io/managed-by": "LavinMQController",
+	}
+	legacy := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.Name,
+			Namespace: instance.Namespace,
+			Labels:    legacyLabels,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Selector:    &metav1.LabelSelector{MatchLabels: legacyLabels},
+			ServiceName: instance.Name,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: legacyLabels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "lavinmq", Image: instance.Spec.Image}},
+				},
+			},
+		},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), legacy))
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.Name + "-0",
+			Namespace: instance.Namespace,
+			Labels:    legacyLabels,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "StatefulSet",
+				Name:       legacy.Name,
+				UID:        legacy.UID,
+				Controller: &[]bool{true}[0],
+			}},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "lavinmq", Image: instance.Spec.Image}},
+		},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), pod))
+
+	rc := &reconciler.StatefulSetReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	result, err := rc.Reconcile(t.Context())
+	assert.NoErrorf(t, err, "Failed to reconcile instance")
+	assert.True(t, result.Requeue, "Expected a requeue while the old statefulset is removed")
+
+	t.Log("Pods are relabeled so the new statefulset adopts them")
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, pod)
+	assert.NoError(t, err)
+	assert.Equal(t, instance.Name, pod.Labels["app.kubernetes.io/instance"])
+
+	t.Log("The old statefulset is deleted orphaning its pods")
+	sts := &appsv1.StatefulSet{}
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts)
+	if err == nil {
+		assert.NotNil(t, sts.DeletionTimestamp)
+		assert.Contains(t, sts.Finalizers, metav1.FinalizerOrphanDependents)
+	} else {
+		assert.True(t, apierrors.IsNotFound(err))
+	}
+}
+
 func createConfigMap(t *testing.T, instance *cloudamqpcomv1alpha1.LavinMQ, config string) *corev1.ConfigMap {
 	// Create initial ConfigMap
 	configMap := &corev1.ConfigMap{
@@ -277,3 +448,335 @@ func deleteConfigMap(t *testing.T, configMap *corev1.ConfigMap) {
 	err := k8sClient.Delete(t.Context(), configMap)
 	assert.NoErrorf(t, err, "Failed to delete ConfigMap")
 }
+
+func TestStatefulSetPodTemplateFields(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	configMap := createConfigMap(t, instance, "initial_config")
+	defer deleteConfigMap(t, configMap)
+
+	runAsNonRoot := true
+	instance.Spec.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "broker", Effect: corev1.TaintEffectNoSchedule}}
+	instance.Spec.NodeSelector = map[string]string{"node-role": "broker"}
+	instance.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry"}}
+	instance.Spec.PodSecurityContext = &corev1.PodSecurityContext{RunAsNonRoot: &runAsNonRoot}
+	instance.Spec.SecurityContext = &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}}}
+
+	rc := &reconciler.StatefulSetReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	err = k8sClient.Create(t.Context(), instance)
+	assert.NoErrorf(t, err, "Failed to create instance")
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoErrorf(t, err, "Failed to reconcile instance")
+
+	sts := &appsv1.StatefulSet{}
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts)
+	assert.NoErrorf(t, err, "Failed to get statefulset")
+
+	podSpec := sts.Spec.Template.Spec
+	assert.Equal(t, instance.Spec.Tolerations, podSpec.Tolerations)
+	assert.Equal(t, instance.Spec.NodeSelector, podSpec.NodeSelector)
+	assert.Equal(t, instance.Spec.ImagePullSecrets, podSpec.ImagePullSecrets)
+	assert.Equal(t, instance.Spec.PodSecurityContext, podSpec.SecurityContext)
+	assert.Equal(t, instance.Spec.SecurityContext, podSpec.Containers[0].SecurityContext)
+
+	t.Log("Reconciling unchanged spec doesn't update the statefulset")
+	resourceVersion := sts.ResourceVersion
+	_, err = rc.Reconcile(t.Context())
+	assert.NoErrorf(t, err, "Failed to reconcile instance")
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts)
+	assert.NoErrorf(t, err, "Failed to get statefulset")
+	assert.Equal(t, resourceVersion, sts.ResourceVersion)
+
+	t.Log("Changed fields are reconciled")
+	instance.Spec.Tolerations = nil
+	instance.Spec.PriorityClassName = "messaging"
+	instance.Spec.ServiceAccountName = "lavinmq"
+	instance.Spec.PodSecurityContext = nil
+	err = k8sClient.Update(t.Context(), instance)
+	assert.NoErrorf(t, err, "Failed to update instance")
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoErrorf(t, err, "Failed to reconcile instance")
+
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts)
+	assert.NoErrorf(t, err, "Failed to get statefulset")
+	podSpec = sts.Spec.Template.Spec
+	assert.Empty(t, podSpec.Tolerations)
+	assert.Equal(t, "messaging", podSpec.PriorityClassName)
+	assert.Equal(t, "lavinmq", podSpec.ServiceAccountName)
+	assert.Nil(t, podSpec.SecurityContext.RunAsNonRoot)
+}
+
+func TestStatefulSetPodTemplateOverride(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	configMap := createConfigMap(t, instance, "initial_config")
+	defer deleteConfigMap(t, configMap)
+
+	instance.Spec.PodTemplate = &runtime.RawExtension{Raw: []byte(`{
+		"metadata": {"annotations": {"prometheus.io/scrape": "true"}},
+		"spec": {
+			"containers": [
+				{"name": "lavinmq", "env": [{"name": "EXTRA", "value": "1"}]},
+				{"name": "log-shipper", "image": "fluent/fluent-bit:3.0"}
+			]
+		}
+	}`)}
+
+	rc := &reconciler.StatefulSetReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	err = k8sClient.Create(t.Context(), instance)
+	assert.NoErrorf(t, err, "Failed to create instance")
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoErrorf(t, err, "Failed to reconcile instance")
+
+	sts := &appsv1.StatefulSet{}
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts)
+	assert.NoErrorf(t, err, "Failed to get statefulset")
+
+	template := sts.Spec.Template
+	assert.Equal(t, "true", template.Annotations["prometheus.io/scrape"])
+	assert.NotEmpty(t, template.Annotations["config-hash"], "Generated annotations are kept")
+	assert.Len(t, template.Spec.Containers, 2)
+	assert.Equal(t, "lavinmq", template.Spec.Containers[0].Name)
+	assert.Equal(t, instance.Spec.Image, template.Spec.Containers[0].Image)
+	assert.Contains(t, template.Spec.Containers[0].Env, corev1.EnvVar{Name: "EXTRA", Value: "1"})
+	assert.Len(t, template.Spec.Containers[0].Env, 3, "Generated env vars are kept")
+	assert.Equal(t, "log-shipper", template.Spec.Containers[1].Name)
+
+	t.Log("Removing the sidecar from the override removes it from the statefulset")
+	instance.Spec.PodTemplate = &runtime.RawExtension{Raw: []byte(`{"spec":{"containers":[{"name":"lavinmq","env":[{"name":"EXTRA","value":"1"}]}]}}`)}
+	err = k8sClient.Update(t.Context(), instance)
+	assert.NoErrorf(t, err, "Failed to update instance")
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoErrorf(t, err, "Failed to reconcile instance")
+
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts)
+	assert.NoErrorf(t, err, "Failed to get statefulset")
+	assert.Len(t, sts.Spec.Template.Spec.Containers, 1)
+	assert.NotContains(t, sts.Spec.Template.Annotations, "prometheus.io/scrape")
+}
+
+func TestTlsHashAnnotation(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	configMap := createConfigMap(t, instance, "initial_config")
+	defer deleteConfigMap(t, configMap)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: instance.Namespace},
+		Data:       map[string][]byte{"tls.crt": []byte("first"), "tls.key": []byte("key")},
+	}
+	err = k8sClient.Create(t.Context(), secret)
+	assert.NoErrorf(t, err, "Failed to create secret")
+
+	instance.Spec.TlsSecret = &corev1.SecretReference{Name: "tls"}
+
+	rc := &reconciler.StatefulSetReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	err = k8sClient.Create(t.Context(), instance)
+	assert.NoErrorf(t, err, "Failed to create instance")
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoErrorf(t, err, "Failed to reconcile instance")
+
+	sts := &appsv1.StatefulSet{}
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts)
+	assert.NoErrorf(t, err, "Failed to get statefulset")
+	initialHash := sts.Spec.Template.Annotations["tls-hash"]
+	assert.NotEmpty(t, initialHash)
+
+	secret.Data["tls.crt"] = []byte("renewed")
+	err = k8sClient.Update(t.Context(), secret)
+	assert.NoErrorf(t, err, "Failed to update secret")
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoErrorf(t, err, "Failed to reconcile instance")
+
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts)
+	assert.NoErrorf(t, err, "Failed to get statefulset")
+	assert.NotEqual(t, initialHash, sts.Spec.Template.Annotations["tls-hash"])
+}
+
+func TestEtcdSecretsMounted(t *testing.T) {
+	t.Parallel()
+	replicas := int32(3)
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{Replicas: &replicas})
+
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	configMap := createConfigMap(t, instance, "initial_config")
+	defer deleteConfigMap(t, configMap)
+
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "etcd-credentials", Namespace: instance.Namespace},
+		Data:       map[string][]byte{"username": []byte("lavinmq"), "password": []byte("first")},
+	}
+	err = k8sClient.Create(t.Context(), credentials)
+	assert.NoErrorf(t, err, "Failed to create secret")
+
+	instance.Spec.EtcdEndpoints = []string{"https://etcd:2379"}
+	instance.Spec.Etcd = &cloudamqpcomv1alpha1.EtcdSpec{
+		CredentialsSecretRef: &corev1.LocalObjectReference{Name: "etcd-credentials"},
+	}
+
+	rc := &reconciler.StatefulSetReconciler{
+		ResourceReconciler: &reconciler.ResourceReconciler{
+			Instance: instance,
+			Scheme:   scheme.Scheme,
+			Client:   k8sClient,
+		},
+	}
+
+	err = k8sClient.Create(t.Context(), instance)
+	assert.NoErrorf(t, err, "Failed to create instance")
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoErrorf(t, err, "Failed to reconcile instance")
+
+	sts := &appsv1.StatefulSet{}
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts)
+	assert.NoErrorf(t, err, "Failed to get statefulset")
+	assert.Contains(t, sts.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      "etcd-credentials",
+		MountPath: "/etc/lavinmq/etcd/credentials",
+		ReadOnly:  true,
+	})
+	initialHash := sts.Spec.Template.Annotations["etcd-secrets-hash"]
+	assert.NotEmpty(t, initialHash)
+
+	t.Log("Rotating the credentials restarts the pods")
+	credentials.Data["password"] = []byte("rotated")
+	err = k8sClient.Update(t.Context(), credentials)
+	assert.NoErrorf(t, err, "Failed to update secret")
+
+	_, err = rc.Reconcile(t.Context())
+	assert.NoErrorf(t, err, "Failed to reconcile instance")
+
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts)
+	assert.NoErrorf(t, err, "Failed to get statefulset")
+	assert.NotEqual(t, initialHash, sts.Spec.Template.Annotations["etcd-secrets-hash"])
+}
+
+func TestScaleDownHandsOverLeadership(t *testing.T) {
+	t.Parallel()
+	replicas := int32(3)
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{Replicas: &replicas})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	etcd := testutils.StartFakeEtcd(nil)
+	defer etcd.Close()
+
+	instance.Spec.EtcdEndpoints = []string{etcd.URL}
+	defer k8sClient.Delete(t.Context(), instance)
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	leader := func(ordinal int) string {
+		return fmt.Sprintf("tcp://%s-%d.%s.%s.svc.cluster.local:5679", instance.Name, ordinal, instance.Name, instance.Namespace)
+	}
+	etcd.Set(instance.EtcdPrefix()+"/leader", leader(2))
+	etcd.Set(instance.EtcdPrefix()+"/isr", "a,b,c")
+
+	configMap := createConfigMap(t, instance, "initial_config")
+	defer deleteConfigMap(t, configMap)
+
+	resourceReconciler := &reconciler.ResourceReconciler{
+		Instance: instance,
+		Scheme:   scheme.Scheme,
+		Client:   k8sClient,
+	}
+	_, err = resourceReconciler.StatefulSetReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	for i := range int(replicas) {
+		createRevisionPod(t, instance, i, "current")
+	}
+
+	instance.Spec.Replicas = 1
+	stsReplicas := func() int32 {
+		sts := &appsv1.StatefulSet{}
+		assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, sts))
+		return *sts.Spec.Replicas
+	}
+
+	t.Log("The leader is restarted together with the other pods being removed")
+	rc := resourceReconciler.StatefulSetReconciler()
+	result, err := rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Equal(t, fmt.Sprintf("handing over leadership from %s-2", instance.Name), rc.Progress())
+	assert.Equal(t, int32(3), stsReplicas())
+	assert.Equal(t, []bool{true, false, false}, podsExist(t, instance))
+
+	t.Log("The restarted pods aren't touched while the old election key is still there")
+	for _, ordinal := range []int{1, 2} {
+		createRevisionPod(t, instance, ordinal, "current")
+	}
+	rc = resourceReconciler.StatefulSetReconciler()
+	result, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Equal(t, fmt.Sprintf("waiting for leadership to move from %s-2", instance.Name), rc.Progress())
+	assert.Equal(t, []bool{true, true, true}, podsExist(t, instance))
+
+	t.Log("Waiting for the restarted nodes to be back in sync")
+	etcd.Delete(instance.EtcdPrefix() + "/leader")
+	etcd.Set(instance.EtcdPrefix()+"/leader", leader(0))
+	etcd.Set(instance.EtcdPrefix()+"/isr", "a,b")
+	rc = resourceReconciler.StatefulSetReconciler()
+	result, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Contains(t, rc.Progress(), "2/3 in sync")
+	assert.Equal(t, int32(3), stsReplicas())
+
+	t.Log("Scaling down once all nodes are in sync and the leader is kept")
+	etcd.Set(instance.EtcdPrefix()+"/isr", "a,b,c")
+	rc = resourceReconciler.StatefulSetReconciler()
+	result, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.False(t, result.Requeue)
+	assert.Empty(t, rc.Progress())
+	assert.Equal(t, int32(1), stsReplicas())
+}
@@ -0,0 +1,37 @@
+package resource_utils_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	resource_utils "github.com/cloudamqp/lavinmq-operator/internal/reconciler/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateSelfSignedCertificate(t *testing.T) {
+	t.Parallel()
+	certPEM, keyPEM, err := resource_utils.GenerateSelfSignedCertificate("etcd", []string{"*.etcd.default.svc.cluster.local"}, time.Hour)
+	assert.NoError(t, err)
+
+	_, err = tls.X509KeyPair(certPEM, keyPEM)
+	assert.NoError(t, err)
+
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	assert.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	for _, usage := range []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth} {
+		_, err = cert.Verify(x509.VerifyOptions{
+			DNSName:   "etcd-0.etcd.default.svc.cluster.local",
+			Roots:     roots,
+			KeyUsages: []x509.ExtKeyUsage{usage},
+		})
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,47 @@
+package resource_utils
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+const passwordSaltSize = 4
+
+// HashPassword hashes a password the way LavinMQ expects it for default_password,
+// base64 of a random 4 byte salt followed by sha256(salt + password).
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, passwordSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	return encodePasswordHash(salt, password), nil
+}
+
+// VerifyPasswordHash reports whether hash was computed from password.
+func VerifyPasswordHash(hash string, password string) bool {
+	decoded, err := base64.StdEncoding.DecodeString(hash)
+	if err != nil || len(decoded) != passwordSaltSize+sha256.Size {
+		return false
+	}
+
+	expected, _ := base64.StdEncoding.DecodeString(encodePasswordHash(decoded[:passwordSaltSize], password))
+	return bytes.Equal(decoded, expected)
+}
+
+// GeneratePassword returns a random password safe to use in URIs.
+func GeneratePassword() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func encodePasswordHash(salt []byte, password string) string {
+	digest := sha256.Sum256(append(append([]byte{}, salt...), password...))
+	return base64.StdEncoding.EncodeToString(append(append([]byte{}, salt...), digest[:]...))
+}
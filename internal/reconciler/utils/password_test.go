@@ -0,0 +1,45 @@
+package resource_utils_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	resource_utils "github.com/cloudamqp/lavinmq-operator/internal/reconciler/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashPassword(t *testing.T) {
+	t.Parallel()
+	hash, err := resource_utils.HashPassword("s3cret")
+	assert.NoError(t, err)
+
+	decoded, err := base64.StdEncoding.DecodeString(hash)
+	assert.NoError(t, err)
+	assert.Len(t, decoded, 4+sha256.Size)
+
+	expected := sha256.Sum256(append(append([]byte{}, decoded[:4]...), "s3cret"...))
+	assert.Equal(t, expected[:], decoded[4:])
+}
+
+func TestVerifyPasswordHash(t *testing.T) {
+	t.Parallel()
+	hash, err := resource_utils.HashPassword("s3cret")
+	assert.NoError(t, err)
+
+	assert.True(t, resource_utils.VerifyPasswordHash(hash, "s3cret"))
+	assert.False(t, resource_utils.VerifyPasswordHash(hash, "other"))
+	assert.False(t, resource_utils.VerifyPasswordHash("not a hash", "s3cret"))
+}
+
+func TestGeneratePassword(t *testing.T) {
+	t.Parallel()
+	a, err := resource_utils.GeneratePassword()
+	assert.NoError(t, err)
+	b, err := resource_utils.GeneratePassword()
+	assert.NoError(t, err)
+
+	assert.Len(t, a, 32)
+	assert.NotEqual(t, a, b)
+}
@@ -0,0 +1,601 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/controller/utils"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// The volumes involved in a migration are recorded on its copy Job once the data has been copied.
+	volumeMigrationSourceVolumeAnnotation = "lavinmq.cloudamqp.com/volume-migration-source-volume"
+	volumeMigrationTargetVolumeAnnotation = "lavinmq.cloudamqp.com/volume-migration-target-volume"
+	// The reclaim policy a volume had before it was retained for the migration.
+	volumeMigrationReclaimPolicyAnnotation = "lavinmq.cloudamqp.com/volume-migration-reclaim-policy"
+)
+
+// VolumeMigrationReconciler replaces data volumes that can't be changed in place, when shrinking them or
+// moving them to another storage class. One volume at a time the StatefulSet is deleted while orphaning its
+// pods, the pod using the volume is stopped and a Job copies its data to a new claim. The new volume is then
+// bound to a claim with the original name and the StatefulSet is recreated by the StatefulSetReconciler.
+// The old volume is retained until the node has rejoined the cluster on the new one.
+type VolumeMigrationReconciler struct {
+	*ResourceReconciler
+
+	// progress describes the current step of an ongoing migration, reported in the status.
+	progress string
+}
+
+func (reconciler *ResourceReconciler) VolumeMigrationReconciler() *VolumeMigrationReconciler {
+	return &VolumeMigrationReconciler{
+		ResourceReconciler: reconciler,
+	}
+}
+
+func (b *VolumeMigrationReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	// A started migration is finished even if spec.volumeMigration is removed in the meantime.
+	if status := b.Instance.Status.VolumeMigration; status != nil {
+		return b.migrate(ctx, status)
+	}
+
+	if b.Instance.Spec.VolumeMigration == nil {
+		return ctrl.Result{}, nil
+	}
+
+	ordinal, err := b.nextVolume(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if ordinal < 0 {
+		return ctrl.Result{}, nil
+	}
+
+	if b.Instance.Spec.VolumeMigration.Strategy != v1alpha1.VolumeMigrationStrategyMaintenanceWindow {
+		step, err := b.clusterUnavailable(ctx)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if step != "" {
+			return b.wait(step)
+		}
+	}
+
+	claimName := dataClaimName(b.Instance, ordinal)
+	b.Logger.Info("Migrating volume", "name", claimName)
+	err = b.setStatus(ctx, &v1alpha1.VolumeMigrationStatus{Claim: claimName, Phase: v1alpha1.VolumeMigrationPhaseCopying})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return b.start(ctx, ordinal)
+}
+
+// migratingClaim returns the name of the claim the StatefulSet and the PVCReconciler have to leave alone,
+// empty unless a volume migration has stopped one of the nodes.
+func (reconciler *ResourceReconciler) migratingClaim() string {
+	status := reconciler.Instance.Status.VolumeMigration
+	if status == nil || status.Phase != v1alpha1.VolumeMigrationPhaseCopying {
+		return ""
+	}
+
+	return status.Claim
+}
+
+// nextVolume returns the ordinal of the next volume to migrate, -1 if all volumes match the spec.
+// Followers are migrated first, from the highest ordinal, and the leader last.
+func (b *VolumeMigrationReconciler) nextVolume(ctx context.Context) (int, error) {
+	leader := -1
+	if b.Instance.EtcdEndpoints() != nil {
+		name, err := b.CurrentLeader(ctx)
+		if err != nil {
+			return -1, err
+		}
+		if name != "" {
+			leader = podOrdinal(name)
+		}
+	}
+
+	outdated := -1
+	for i := int(b.Instance.Spec.Replicas) - 1; i >= 0; i-- {
+		pvc := &corev1.PersistentVolumeClaim{}
+		pvc.Name = dataClaimName(b.Instance, i)
+		pvc.Namespace = b.Instance.Namespace
+		if err := b.GetItem(ctx, pvc); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return -1, err
+		}
+
+		if !volumeNeedsMigration(pvc, b.Instance) {
+			continue
+		}
+		if i != leader {
+			return i, nil
+		}
+		outdated = i
+	}
+
+	return outdated, nil
+}
+
+// volumeNeedsMigration reports whether a claim can only be brought in line with the spec by replacing it.
+func volumeNeedsMigration(pvc *corev1.PersistentVolumeClaim, instance *v1alpha1.LavinMQ) bool {
+	desired := instance.Spec.DataVolumeClaimSpec
+	if pvc.Spec.Resources.Requests.Storage().Cmp(*desired.Resources.Requests.Storage()) > 0 {
+		return true
+	}
+
+	// Claims without a class get the default one assigned, only an explicitly requested class is compared.
+	return desired.StorageClassName != nil && pvc.Spec.StorageClassName != nil &&
+		*desired.StorageClassName != *pvc.Spec.StorageClassName
+}
+
+// clusterUnavailable returns what a migration waits for before stopping a node or releasing the old volume,
+// empty when all replicas are ready and in sync.
+func (b *VolumeMigrationReconciler) clusterUnavailable(ctx context.Context) (string, error) {
+	sts := &appsv1.StatefulSet{}
+	sts.Name = b.Instance.Name
+	sts.Namespace = b.Instance.Namespace
+	if err := b.GetItem(ctx, sts); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "waiting for the StatefulSet to be created", nil
+		}
+		return "", err
+	}
+
+	if sts.Spec.Replicas == nil || *sts.Spec.Replicas != b.Instance.Spec.Replicas || sts.Status.ReadyReplicas < b.Instance.Spec.Replicas {
+		return fmt.Sprintf("waiting for all replicas to be ready, %d/%d ready", sts.Status.ReadyReplicas, b.Instance.Spec.Replicas), nil
+	}
+
+	if b.Instance.EtcdEndpoints() == nil {
+		return "", nil
+	}
+
+	inSync, err := b.InSyncReplicas(ctx)
+	if err != nil {
+		return "", err
+	}
+	if int32(inSync) < b.Instance.Spec.Replicas {
+		return fmt.Sprintf("waiting for followers to sync, %d/%d in sync", inSync, b.Instance.Spec.Replicas), nil
+	}
+
+	return "", nil
+}
+
+// start creates the claim of the new volume and the copy Job, suspended until the node has been stopped.
+func (b *VolumeMigrationReconciler) start(ctx context.Context, ordinal int) (ctrl.Result, error) {
+	target := b.newClaim(ordinal, migrationClaimName(b.Instance, ordinal))
+	if err := b.CreateItem(ctx, target); err != nil && !apierrors.IsAlreadyExists(err) {
+		return ctrl.Result{}, fmt.Errorf("failed to create PVC %s: %w", target.Name, err)
+	}
+
+	job := b.newJob(ordinal)
+	if err := b.CreateItem(ctx, job); err != nil && !apierrors.IsAlreadyExists(err) {
+		return ctrl.Result{}, fmt.Errorf("failed to create job %s: %w", job.Name, err)
+	}
+
+	return b.wait(fmt.Sprintf("migrating %s", dataClaimName(b.Instance, ordinal)))
+}
+
+// migrate advances the ongoing migration by one step.
+func (b *VolumeMigrationReconciler) migrate(ctx context.Context, status *v1alpha1.VolumeMigrationStatus) (ctrl.Result, error) {
+	ordinal := podOrdinal(status.Claim)
+
+	job := &batchv1.Job{}
+	job.Name = volumeMigrationJobName(b.Instance, ordinal)
+	job.Namespace = b.Instance.Namespace
+	if err := b.GetItem(ctx, job); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		job = nil
+	}
+
+	if status.Phase == v1alpha1.VolumeMigrationPhaseRejoining {
+		return b.rejoin(ctx, job, status.Claim)
+	}
+
+	if job == nil {
+		return b.restart(ctx, ordinal)
+	}
+
+	if job.Spec.Suspend != nil && *job.Spec.Suspend {
+		return b.stopNode(ctx, job, ordinal)
+	}
+
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return ctrl.Result{}, fmt.Errorf("copying %s failed, delete job %s to retry: %s", status.Claim, job.Name, condition.Message)
+		}
+	}
+
+	if job.Status.Succeeded == 0 {
+		return b.wait(fmt.Sprintf("copying data of %s", status.Claim))
+	}
+
+	return b.swapClaim(ctx, job, ordinal)
+}
+
+// restart recreates the copy Job of a migration that lost it, e.g. when it was deleted to retry a failed copy.
+// The migration is given up if the volume matches the spec again before its data was copied.
+func (b *VolumeMigrationReconciler) restart(ctx context.Context, ordinal int) (ctrl.Result, error) {
+	claimName := dataClaimName(b.Instance, ordinal)
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	pvc.Name = claimName
+	pvc.Namespace = b.Instance.Namespace
+	if err := b.GetItem(ctx, pvc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("PVC %s is gone while its volume migration has no job", claimName)
+		}
+		return ctrl.Result{}, err
+	}
+
+	if volumeNeedsMigration(pvc, b.Instance) {
+		return b.start(ctx, ordinal)
+	}
+
+	target := &corev1.PersistentVolumeClaim{}
+	target.Name = migrationClaimName(b.Instance, ordinal)
+	target.Namespace = b.Instance.Namespace
+	if err := b.Client.Delete(ctx, target); err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, fmt.Errorf("failed to delete PVC %s: %w", target.Name, err)
+	}
+
+	b.Logger.Info("Volume migration no longer needed, aborting", "name", claimName)
+	return ctrl.Result{Requeue: true}, b.setStatus(ctx, nil)
+}
+
+// stopNode takes the node using the volume out of the cluster and then lets the copy start. The StatefulSet
+// is deleted while orphaning the other pods, it would otherwise recreate the stopped pod right away.
+func (b *VolumeMigrationReconciler) stopNode(ctx context.Context, job *batchv1.Job, ordinal int) (ctrl.Result, error) {
+	sts := &appsv1.StatefulSet{}
+	sts.Name = b.Instance.Name
+	sts.Namespace = b.Instance.Namespace
+	err := b.GetItem(ctx, sts)
+	if err == nil {
+		if sts.DeletionTimestamp == nil {
+			b.Logger.Info("Deleting statefulset while keeping pods for volume migration", "name", sts.Name)
+			err := b.Client.Delete(ctx, sts, client.PropagationPolicy(metav1.DeletePropagationOrphan))
+			if err != nil && !apierrors.IsNotFound(err) {
+				return ctrl.Result{}, err
+			}
+		}
+		return b.wait("waiting for the StatefulSet to be deleted")
+	}
+	if !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+
+	pod := &corev1.Pod{}
+	pod.Name = fmt.Sprintf("%s-%d", b.Instance.Name, ordinal)
+	pod.Namespace = b.Instance.Namespace
+	err = b.GetItem(ctx, pod)
+	if err == nil {
+		if pod.DeletionTimestamp == nil {
+			b.Logger.Info("Stopping pod for volume migration", "pod", pod.Name)
+			if err := b.Client.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+				return ctrl.Result{}, fmt.Errorf("failed to delete pod %s: %w", pod.Name, err)
+			}
+		}
+		return b.wait(fmt.Sprintf("stopping %s", pod.Name))
+	}
+	if !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+
+	job.Spec.Suspend = nil
+	if err := b.Client.Update(ctx, job); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return b.wait(fmt.Sprintf("copying data of %s", dataClaimName(b.Instance, ordinal)))
+}
+
+// swapClaim replaces the old claim with one bound to the volume the data was copied to. Both volumes are
+// retained while the claims are swapped, the new one gets its reclaim policy back once it's bound again and
+// the old one once the node has rejoined the cluster.
+func (b *VolumeMigrationReconciler) swapClaim(ctx context.Context, job *batchv1.Job, ordinal int) (ctrl.Result, error) {
+	claimName := dataClaimName(b.Instance, ordinal)
+
+	target := &corev1.PersistentVolumeClaim{}
+	target.Name = migrationClaimName(b.Instance, ordinal)
+	target.Namespace = b.Instance.Namespace
+	err := b.GetItem(ctx, target)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+
+	if err == nil {
+		if job.Annotations[volumeMigrationTargetVolumeAnnotation] == "" {
+			if err := b.retainVolumes(ctx, job, claimName, target); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		for _, name := range []string{claimName, target.Name} {
+			pvc := &corev1.PersistentVolumeClaim{}
+			pvc.Name = name
+			pvc.Namespace = b.Instance.Namespace
+			b.Logger.Info("Deleting PVC replaced by volume migration", "name", name)
+			if err := b.Client.Delete(ctx, pvc); err != nil && !apierrors.IsNotFound(err) {
+				return ctrl.Result{}, fmt.Errorf("failed to delete PVC %s: %w", name, err)
+			}
+		}
+
+		return b.wait(fmt.Sprintf("replacing %s", claimName))
+	}
+
+	pv, err := b.volume(ctx, job.Annotations[volumeMigrationTargetVolumeAnnotation])
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	pvc.Name = claimName
+	pvc.Namespace = b.Instance.Namespace
+	err = b.GetItem(ctx, pvc)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+
+	if apierrors.IsNotFound(err) {
+		// The volume keeps referring to the deleted claim until it's released.
+		if pv.Spec.ClaimRef != nil && pv.Spec.ClaimRef.Name != claimName {
+			pv.Spec.ClaimRef = nil
+			if err := b.Client.Update(ctx, pv); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		pvc = b.newClaim(ordinal, claimName)
+		pvc.Spec.VolumeName = pv.Name
+		pvc.Spec.StorageClassName = &pv.Spec.StorageClassName
+		if err := b.CreateItem(ctx, pvc); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to create PVC %s: %w", claimName, err)
+		}
+		return b.wait(fmt.Sprintf("replacing %s", claimName))
+	}
+
+	if pvc.Spec.VolumeName != pv.Name {
+		return b.wait(fmt.Sprintf("waiting for %s to be deleted", claimName))
+	}
+
+	if err := b.restoreReclaimPolicy(ctx, pv); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// The StatefulSet is recreated in the next pass, which brings the node back.
+	err = b.setStatus(ctx, &v1alpha1.VolumeMigrationStatus{Claim: claimName, Phase: v1alpha1.VolumeMigrationPhaseRejoining})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return b.wait(fmt.Sprintf("starting %s-%d", b.Instance.Name, ordinal))
+}
+
+// retainVolumes keeps the volumes of both claims from being reclaimed when the claims are deleted and
+// records them on the job.
+func (b *VolumeMigrationReconciler) retainVolumes(ctx context.Context, job *batchv1.Job, claimName string, target *corev1.PersistentVolumeClaim) error {
+	source := &corev1.PersistentVolumeClaim{}
+	source.Name = claimName
+	source.Namespace = b.Instance.Namespace
+	if err := b.GetItem(ctx, source); err != nil {
+		return fmt.Errorf("failed to get PVC %s: %w", claimName, err)
+	}
+
+	for _, pvc := range []*corev1.PersistentVolumeClaim{source, target} {
+		if pvc.Spec.VolumeName == "" {
+			return fmt.Errorf("PVC %s isn't bound to a volume", pvc.Name)
+		}
+
+		pv, err := b.volume(ctx, pvc.Spec.VolumeName)
+		if err != nil {
+			return err
+		}
+
+		// The original policy is kept on the volume itself, so retrying never records Retain instead.
+		if _, ok := pv.Annotations[volumeMigrationReclaimPolicyAnnotation]; !ok {
+			if pv.Annotations == nil {
+				pv.Annotations = map[string]string{}
+			}
+			pv.Annotations[volumeMigrationReclaimPolicyAnnotation] = string(pv.Spec.PersistentVolumeReclaimPolicy)
+			pv.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+			if err := b.Client.Update(ctx, pv); err != nil {
+				return err
+			}
+		}
+	}
+
+	job.Annotations[volumeMigrationSourceVolumeAnnotation] = source.Spec.VolumeName
+	job.Annotations[volumeMigrationTargetVolumeAnnotation] = target.Spec.VolumeName
+
+	return b.Client.Update(ctx, job)
+}
+
+// rejoin waits for the node to be back in the cluster on the new volume before the old volume is released
+// under its original reclaim policy.
+func (b *VolumeMigrationReconciler) rejoin(ctx context.Context, job *batchv1.Job, claimName string) (ctrl.Result, error) {
+	step, err := b.clusterUnavailable(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if step != "" {
+		return b.wait(step)
+	}
+
+	if job != nil {
+		if name := job.Annotations[volumeMigrationSourceVolumeAnnotation]; name != "" {
+			pv := &corev1.PersistentVolume{}
+			err := b.Client.Get(ctx, client.ObjectKey{Name: name}, pv)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return ctrl.Result{}, fmt.Errorf("failed to get volume %s: %w", name, err)
+			}
+			if err == nil {
+				b.Logger.Info("Releasing volume replaced by volume migration", "volume", name)
+				if err := b.restoreReclaimPolicy(ctx, pv); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+		}
+
+		err := b.Client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+	}
+
+	b.Logger.Info("Volume migrated", "name", claimName)
+	if err := b.setStatus(ctx, nil); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// The next volume, if any, is picked up in the next pass.
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// restoreReclaimPolicy gives a volume retained for the migration its original reclaim policy back.
+func (b *VolumeMigrationReconciler) restoreReclaimPolicy(ctx context.Context, pv *corev1.PersistentVolume) error {
+	policy, ok := pv.Annotations[volumeMigrationReclaimPolicyAnnotation]
+	if !ok {
+		return nil
+	}
+
+	delete(pv.Annotations, volumeMigrationReclaimPolicyAnnotation)
+	if policy != "" {
+		pv.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimPolicy(policy)
+	}
+
+	return b.Client.Update(ctx, pv)
+}
+
+// setStatus records the ongoing migration in the status right away, the other reconcilers rely on it.
+func (b *VolumeMigrationReconciler) setStatus(ctx context.Context, status *v1alpha1.VolumeMigrationStatus) error {
+	original := b.Instance.DeepCopy()
+	b.Instance.Status.VolumeMigration = status
+
+	return b.Client.Status().Patch(ctx, b.Instance, client.MergeFrom(original))
+}
+
+func (b *VolumeMigrationReconciler) volume(ctx context.Context, name string) (*corev1.PersistentVolume, error) {
+	pv := &corev1.PersistentVolume{}
+	if err := b.Client.Get(ctx, client.ObjectKey{Name: name}, pv); err != nil {
+		return nil, fmt.Errorf("failed to get volume %s: %w", name, err)
+	}
+
+	return pv, nil
+}
+
+func (b *VolumeMigrationReconciler) newClaim(ordinal int, name string) *corev1.PersistentVolumeClaim {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: b.Instance.Namespace,
+			Labels:    utils.LabelsForLavinMQ(b.Instance),
+		},
+		Spec: *b.Instance.Spec.DataVolumeClaimSpec.DeepCopy(),
+	}
+	// Forcing ReadWriteOnce for volume access mode
+	pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+
+	return pvc
+}
+
+func (b *VolumeMigrationReconciler) newJob(ordinal int) *batchv1.Job {
+	image := b.Instance.Spec.Image
+	if b.Instance.Spec.VolumeMigration != nil && b.Instance.Spec.VolumeMigration.Image != "" {
+		image = b.Instance.Spec.VolumeMigration.Image
+	}
+
+	suspend := true
+	backoffLimit := int32(3)
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        volumeMigrationJobName(b.Instance, ordinal),
+			Namespace:   b.Instance.Namespace,
+			Labels:      utils.LabelsForLavinMQ(b.Instance),
+			Annotations: map[string]string{},
+		},
+		Spec: batchv1.JobSpec{
+			Suspend:      &suspend,
+			BackoffLimit: &backoffLimit,
+			// The pods don't get the instance labels, the StatefulSet and the services would select them.
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "copy",
+							Image:   image,
+							Command: []string{"sh", "-c", "cp -a /source/. /target/"},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "source", MountPath: "/source", ReadOnly: true},
+								{Name: "target", MountPath: "/target"},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "source",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: dataClaimName(b.Instance, ordinal), ReadOnly: true},
+							},
+						},
+						{
+							Name: "target",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: migrationClaimName(b.Instance, ordinal)},
+							},
+						},
+					},
+					SecurityContext:  b.Instance.Spec.PodSecurityContext,
+					Tolerations:      b.Instance.Spec.Tolerations,
+					NodeSelector:     b.Instance.Spec.NodeSelector,
+					ImagePullSecrets: b.Instance.Spec.ImagePullSecrets,
+				},
+			},
+		},
+	}
+}
+
+func (b *VolumeMigrationReconciler) wait(step string) (ctrl.Result, error) {
+	b.Logger.Info("Volume migration in progress", "step", step)
+	b.progress = step
+
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// Progress returns the current step of an ongoing migration, empty if there is none.
+func (b *VolumeMigrationReconciler) Progress() string {
+	return b.progress
+}
+
+func dataClaimName(instance *v1alpha1.LavinMQ, ordinal int) string {
+	return fmt.Sprintf("data-%s-%d", instance.Name, ordinal)
+}
+
+// migrationClaimName is the claim the data is copied to, it doesn't parse as a StatefulSet ordinal.
+func migrationClaimName(instance *v1alpha1.LavinMQ, ordinal int) string {
+	return dataClaimName(instance, ordinal) + "-migration"
+}
+
+func volumeMigrationJobName(instance *v1alpha1.LavinMQ, ordinal int) string {
+	return fmt.Sprintf("%s-volume-migration-%d", instance.Name, ordinal)
+}
+
+// Name returns the name of the volume migration reconciler
+func (b *VolumeMigrationReconciler) Name() string {
+	return "volume-migration"
+}
@@ -0,0 +1,294 @@
+package reconciler_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cloudamqp/lavinmq-operator/api/v1alpha1"
+	"github.com/cloudamqp/lavinmq-operator/internal/reconciler"
+	testutils "github.com/cloudamqp/lavinmq-operator/internal/test_utils"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestVolumeMigrationRolling(t *testing.T) {
+	t.Parallel()
+	replicas := int32(2)
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{Replicas: &replicas})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	etcd := testutils.StartFakeEtcd(nil)
+	defer etcd.Close()
+
+	instance.Spec.EtcdEndpoints = []string{etcd.URL}
+	defer k8sClient.Delete(t.Context(), instance)
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	etcd.Set(instance.EtcdPrefix()+"/leader", fmt.Sprintf("tcp://%s-0.%s.%s.svc.cluster.local:5679", instance.Name, instance.Name, instance.Namespace))
+	etcd.Set(instance.EtcdPrefix()+"/isr", "a,b")
+
+	configMap := createConfigMap(t, instance, "initial_config")
+	defer deleteConfigMap(t, configMap)
+
+	source := []string{}
+	for i := range int(replicas) {
+		source = append(source, createBoundClaim(t, instance, fmt.Sprintf("data-%s-%d", instance.Name, i), "10Gi"))
+		createRevisionPod(t, instance, i, "current")
+	}
+
+	resourceReconciler := &reconciler.ResourceReconciler{
+		Instance: instance,
+		Scheme:   scheme.Scheme,
+		Client:   k8sClient,
+	}
+	createReadyStatefulSet(t, resourceReconciler)
+
+	instance.Spec.DataVolumeClaimSpec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("5Gi")
+	instance.Spec.VolumeMigration = &v1alpha1.VolumeMigrationSpec{Strategy: v1alpha1.VolumeMigrationStrategyRolling}
+	assert.NoError(t, k8sClient.Update(t.Context(), instance))
+
+	t.Log("Shrinking a volume is left to the volume migration")
+	_, err = resourceReconciler.PVCReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	t.Log("The follower is migrated first")
+	rc := resourceReconciler.VolumeMigrationReconciler()
+	result, err := rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+	claimName := fmt.Sprintf("data-%s-1", instance.Name)
+	assert.Equal(t, &v1alpha1.VolumeMigrationStatus{Claim: claimName, Phase: v1alpha1.VolumeMigrationPhaseCopying}, instance.Status.VolumeMigration)
+
+	job := &batchv1.Job{}
+	jobKey := types.NamespacedName{Name: fmt.Sprintf("%s-volume-migration-1", instance.Name), Namespace: instance.Namespace}
+	assert.NoError(t, k8sClient.Get(t.Context(), jobKey, job))
+	assert.True(t, *job.Spec.Suspend, "Expected the copy to wait for the node to stop")
+
+	t.Log("The StatefulSet is deleted while keeping the other pods")
+	_, err = resourceReconciler.VolumeMigrationReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+	removeFinalizers(t, &appsv1.StatefulSet{}, instance.Name, instance.Namespace)
+
+	_, err = resourceReconciler.StatefulSetReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, &appsv1.StatefulSet{})
+	assert.True(t, apierrors.IsNotFound(err), "Expected the StatefulSet to stay deleted while the node is stopped")
+
+	t.Log("The node using the volume is stopped before the copy starts")
+	_, err = resourceReconciler.VolumeMigrationReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, podsExist(t, instance))
+
+	_, err = resourceReconciler.VolumeMigrationReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.NoError(t, k8sClient.Get(t.Context(), jobKey, job))
+	assert.False(t, job.Spec.Suspend != nil && *job.Spec.Suspend, "Expected the copy to start")
+
+	rc = resourceReconciler.VolumeMigrationReconciler()
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.Contains(t, rc.Progress(), "copying data")
+
+	target := bindClaim(t, instance, claimName+"-migration")
+	now := metav1.Now()
+	job.Status.StartTime = &now
+	job.Status.Succeeded = 1
+	assert.NoError(t, k8sClient.Status().Update(t.Context(), job))
+
+	t.Log("Both volumes are retained while the claims are swapped")
+	_, err = resourceReconciler.VolumeMigrationReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.PersistentVolumeReclaimRetain, reclaimPolicy(t, source[1]))
+	assert.Equal(t, corev1.PersistentVolumeReclaimRetain, reclaimPolicy(t, target))
+	removeFinalizers(t, &corev1.PersistentVolumeClaim{}, claimName, instance.Namespace)
+	removeFinalizers(t, &corev1.PersistentVolumeClaim{}, claimName+"-migration", instance.Namespace)
+
+	_, err = resourceReconciler.VolumeMigrationReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+	pvc := &corev1.PersistentVolumeClaim{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: claimName, Namespace: instance.Namespace}, pvc))
+	assert.Equal(t, target, pvc.Spec.VolumeName)
+
+	t.Log("The old volume is kept until the node has rejoined the cluster")
+	_, err = resourceReconciler.VolumeMigrationReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, v1alpha1.VolumeMigrationPhaseRejoining, instance.Status.VolumeMigration.Phase)
+	assert.Equal(t, corev1.PersistentVolumeReclaimDelete, reclaimPolicy(t, target))
+	assert.Equal(t, corev1.PersistentVolumeReclaimRetain, reclaimPolicy(t, source[1]))
+
+	createReadyStatefulSet(t, resourceReconciler)
+	etcd.Set(instance.EtcdPrefix()+"/isr", "a")
+	rc = resourceReconciler.VolumeMigrationReconciler()
+	_, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.Contains(t, rc.Progress(), "1/2 in sync")
+	assert.Equal(t, corev1.PersistentVolumeReclaimRetain, reclaimPolicy(t, source[1]))
+
+	etcd.Set(instance.EtcdPrefix()+"/isr", "a,b")
+	_, err = resourceReconciler.VolumeMigrationReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.Nil(t, instance.Status.VolumeMigration)
+	assert.Equal(t, corev1.PersistentVolumeReclaimDelete, reclaimPolicy(t, source[1]))
+	err = k8sClient.Get(t.Context(), jobKey, &batchv1.Job{})
+	assert.True(t, apierrors.IsNotFound(err), "Expected the copy job to be deleted")
+
+	t.Log("The leader is migrated last")
+	_, err = resourceReconciler.VolumeMigrationReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("data-%s-0", instance.Name), instance.Status.VolumeMigration.Claim)
+}
+
+func TestVolumeMigrationMaintenanceWindow(t *testing.T) {
+	t.Parallel()
+	instance := testutils.GetDefaultInstance(&testutils.DefaultInstanceSettings{})
+	err := testutils.CreateNamespace(t.Context(), k8sClient, instance.Namespace)
+	assert.NoErrorf(t, err, "Failed to create namespace")
+	defer testutils.DeleteNamespace(t.Context(), k8sClient, instance.Namespace)
+
+	defer k8sClient.Delete(t.Context(), instance)
+	assert.NoError(t, k8sClient.Create(t.Context(), instance))
+
+	className := "fast"
+	createBoundClaim(t, instance, fmt.Sprintf("data-%s-0", instance.Name), "10Gi")
+
+	resourceReconciler := &reconciler.ResourceReconciler{
+		Instance: instance,
+		Scheme:   scheme.Scheme,
+		Client:   k8sClient,
+	}
+
+	t.Log("A storage class change isn't migrated without spec.volumeMigration")
+	instance.Spec.DataVolumeClaimSpec.StorageClassName = &className
+	assert.NoError(t, k8sClient.Update(t.Context(), instance))
+	result, err := resourceReconciler.VolumeMigrationReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.False(t, result.Requeue)
+	assert.Nil(t, instance.Status.VolumeMigration)
+
+	t.Log("A rolling migration waits for the cluster to be available")
+	instance.Spec.VolumeMigration = &v1alpha1.VolumeMigrationSpec{Strategy: v1alpha1.VolumeMigrationStrategyRolling}
+	assert.NoError(t, k8sClient.Update(t.Context(), instance))
+	rc := resourceReconciler.VolumeMigrationReconciler()
+	result, err = rc.Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Contains(t, rc.Progress(), "StatefulSet to be created")
+	assert.Nil(t, instance.Status.VolumeMigration)
+
+	t.Log("A maintenance window migrates the single node right away")
+	instance.Spec.VolumeMigration.Strategy = v1alpha1.VolumeMigrationStrategyMaintenanceWindow
+	assert.NoError(t, k8sClient.Update(t.Context(), instance))
+	result, err = resourceReconciler.VolumeMigrationReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Equal(t, fmt.Sprintf("data-%s-0", instance.Name), instance.Status.VolumeMigration.Claim)
+
+	target := &corev1.PersistentVolumeClaim{}
+	err = k8sClient.Get(t.Context(), types.NamespacedName{Name: fmt.Sprintf("data-%s-0-migration", instance.Name), Namespace: instance.Namespace}, target)
+	assert.NoError(t, err)
+	assert.Equal(t, className, *target.Spec.StorageClassName)
+
+	t.Log("A migration is given up when the spec is reverted before the copy")
+	instance.Spec.DataVolumeClaimSpec.StorageClassName = nil
+	assert.NoError(t, k8sClient.Update(t.Context(), instance))
+	job := &batchv1.Job{}
+	job.Name = fmt.Sprintf("%s-volume-migration-0", instance.Name)
+	job.Namespace = instance.Namespace
+	assert.NoError(t, k8sClient.Delete(t.Context(), job, client.PropagationPolicy(metav1.DeletePropagationBackground)))
+
+	_, err = resourceReconciler.VolumeMigrationReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+	assert.Nil(t, instance.Status.VolumeMigration)
+}
+
+// createBoundClaim creates a claim bound to a volume of its own and returns the name of the volume.
+func createBoundClaim(t *testing.T, instance *v1alpha1.LavinMQ, name string, size string) string {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: instance.Namespace,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+			},
+		},
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), pvc))
+
+	return bindClaim(t, instance, name)
+}
+
+// bindClaim does what the volume binder would, creating a volume and binding the claim to it.
+func bindClaim(t *testing.T, instance *v1alpha1.LavinMQ, name string) string {
+	pvc := &corev1.PersistentVolumeClaim{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: name, Namespace: instance.Namespace}, pvc))
+
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("%s-%s", instance.Namespace, name),
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity:                      pvc.Spec.Resources.Requests,
+			AccessModes:                   pvc.Spec.AccessModes,
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{Path: "/tmp/" + name},
+			},
+		},
+	}
+	if pvc.Spec.StorageClassName != nil {
+		pv.Spec.StorageClassName = *pvc.Spec.StorageClassName
+	}
+	assert.NoError(t, k8sClient.Create(t.Context(), pv))
+	t.Cleanup(func() { k8sClient.Delete(t.Context(), pv) })
+
+	pvc.Spec.VolumeName = pv.Name
+	assert.NoError(t, k8sClient.Update(t.Context(), pvc))
+
+	return pv.Name
+}
+
+// createReadyStatefulSet creates the StatefulSet and reports all its replicas as ready.
+func createReadyStatefulSet(t *testing.T, resourceReconciler *reconciler.ResourceReconciler) {
+	_, err := resourceReconciler.StatefulSetReconciler().Reconcile(t.Context())
+	assert.NoError(t, err)
+
+	sts := &appsv1.StatefulSet{}
+	key := types.NamespacedName{Name: resourceReconciler.Instance.Name, Namespace: resourceReconciler.Instance.Namespace}
+	assert.NoError(t, k8sClient.Get(t.Context(), key, sts))
+	sts.Status.Replicas = resourceReconciler.Instance.Spec.Replicas
+	sts.Status.ReadyReplicas = resourceReconciler.Instance.Spec.Replicas
+	assert.NoError(t, k8sClient.Status().Update(t.Context(), sts))
+}
+
+// removeFinalizers lets a terminating object go, there are no controllers processing finalizers in envtest.
+func removeFinalizers(t *testing.T, obj client.Object, name string, namespace string) {
+	err := k8sClient.Get(t.Context(), types.NamespacedName{Name: name, Namespace: namespace}, obj)
+	if apierrors.IsNotFound(err) {
+		return
+	}
+	assert.NoError(t, err)
+
+	obj.SetFinalizers(nil)
+	assert.NoError(t, k8sClient.Update(t.Context(), obj))
+}
+
+func reclaimPolicy(t *testing.T, name string) corev1.PersistentVolumeReclaimPolicy {
+	pv := &corev1.PersistentVolume{}
+	assert.NoError(t, k8sClient.Get(t.Context(), types.NamespacedName{Name: name}, pv))
+
+	return pv.Spec.PersistentVolumeReclaimPolicy
+}
@@ -0,0 +1,208 @@
+package testutils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// FakeEtcd serves the subset of the etcd v3 JSON gateway used by the operator from an in-memory map.
+type FakeEtcd struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	data     map[string]string
+	username string
+	password string
+	// Revisions are tracked like etcd does, a key keeps its create revision until it's deleted.
+	revision int64
+	created  map[string]int64
+	modified map[string]int64
+}
+
+func StartFakeEtcd(data map[string]string) *FakeEtcd {
+	fake := &FakeEtcd{data: map[string]string{}, created: map[string]int64{}, modified: map[string]int64{}}
+	keys := []string{}
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fake.put(k, data[k])
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v3/auth/authenticate", fake.handleAuthenticate)
+	mux.HandleFunc("/v3/kv/range", fake.handleRange)
+	mux.HandleFunc("/v3/kv/deleterange", fake.handleDeleteRange)
+	fake.Server = httptest.NewServer(mux)
+
+	return fake
+}
+
+// Keys returns all keys currently stored, sorted.
+func (f *FakeEtcd) Keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	keys := []string{}
+	for k := range f.data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Set replaces the value of a key.
+func (f *FakeEtcd) Set(key, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.put(key, value)
+}
+
+// Delete removes a key, setting it again gives it a new create revision like a new election does.
+func (f *FakeEtcd) Delete(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.remove(key)
+}
+
+// put stores a key with the next revision. Callers hold the lock.
+func (f *FakeEtcd) put(key, value string) {
+	f.revision++
+	if _, ok := f.data[key]; !ok {
+		f.created[key] = f.revision
+	}
+	f.modified[key] = f.revision
+	f.data[key] = value
+}
+
+// remove deletes a key and its revisions. Callers hold the lock.
+func (f *FakeEtcd) remove(key string) {
+	delete(f.data, key)
+	delete(f.created, key)
+	delete(f.modified, key)
+}
+
+// RequireAuth makes the fake reject requests without a token for the given credentials.
+func (f *FakeEtcd) RequireAuth(username, password string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.username = username
+	f.password = password
+}
+
+const fakeEtcdToken = "fake-etcd-token"
+
+func (f *FakeEtcd) handleAuthenticate(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	req := struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if f.username == "" || req.Name != f.username || req.Password != f.password {
+		http.Error(w, `{"error":"etcdserver: authentication failed, invalid user ID or password"}`, http.StatusBadRequest)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"token": fakeEtcdToken})
+}
+
+// authorized reports whether the request carries a valid token, always true without auth. Callers hold the lock.
+func (f *FakeEtcd) authorized(r *http.Request) bool {
+	return f.username == "" || r.Header.Get("Authorization") == fakeEtcdToken
+}
+
+type fakeEtcdRequest struct {
+	Key      string `json:"key"`
+	RangeEnd string `json:"range_end"`
+}
+
+func (r fakeEtcdRequest) matches(key string) bool {
+	start, _ := base64.StdEncoding.DecodeString(r.Key)
+	end, _ := base64.StdEncoding.DecodeString(r.RangeEnd)
+	if len(end) == 0 {
+		return key == string(start)
+	}
+
+	return key >= string(start) && key < string(end)
+}
+
+func (f *FakeEtcd) matching(r *http.Request) ([]string, fakeEtcdRequest, error) {
+	req := fakeEtcdRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, req, err
+	}
+
+	keys := []string{}
+	for k := range f.data {
+		if req.matches(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys, req, nil
+}
+
+func (f *FakeEtcd) handleRange(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.authorized(r) {
+		http.Error(w, `{"error":"etcdserver: user name is empty"}`, http.StatusUnauthorized)
+		return
+	}
+
+	keys, _, err := f.matching(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	kvs := []map[string]string{}
+	for _, k := range keys {
+		kvs = append(kvs, map[string]string{
+			"key":             base64.StdEncoding.EncodeToString([]byte(k)),
+			"value":           base64.StdEncoding.EncodeToString([]byte(f.data[k])),
+			"create_revision": strconv.FormatInt(f.created[k], 10),
+			"mod_revision":    strconv.FormatInt(f.modified[k], 10),
+		})
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]any{"kvs": kvs, "count": strconv.Itoa(len(kvs))})
+}
+
+func (f *FakeEtcd) handleDeleteRange(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.authorized(r) {
+		http.Error(w, `{"error":"etcdserver: user name is empty"}`, http.StatusUnauthorized)
+		return
+	}
+
+	keys, _, err := f.matching(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, k := range keys {
+		f.remove(k)
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]any{"deleted": strconv.Itoa(len(keys))})
+}
@@ -0,0 +1,885 @@
+package testutils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/cloudamqp/lavinmq-operator/internal/management"
+)
+
+// FakeManagement serves the subset of the LavinMQ management API used by the operator from memory.
+// Entities are keyed by vhost and name, the default vhost "/" exists from the start.
+type FakeManagement struct {
+	*httptest.Server
+
+	routes      map[string]fakeHandler
+	mu          sync.Mutex
+	nodes       []management.Node
+	vhosts      map[string]management.Vhost
+	queues      map[string]management.Queue
+	exchanges   map[string]management.Exchange
+	bindings    map[string]management.Binding
+	users       map[string]management.User
+	passwords   map[string]string
+	permissions map[string]management.Permission
+	policies    map[string]management.Policy
+	// operatorPolicies are keyed like policies.
+	operatorPolicies map[string]management.Policy
+	// parameters are keyed by component, then by vhost and name.
+	parameters map[string]map[string]management.Parameter
+	// shovelStates overrides the Running state of shovels, see SetShovelState.
+	shovelStates map[string]management.Shovel
+}
+
+func StartFakeManagement(username, password string) *FakeManagement {
+	fake := &FakeManagement{
+		vhosts:      map[string]management.Vhost{"/": {Name: "/"}},
+		queues:      map[string]management.Queue{},
+		exchanges:   map[string]management.Exchange{},
+		bindings:    map[string]management.Binding{},
+		users:       map[string]management.User{username: {Name: username, Tags: "administrator"}},
+		passwords:   map[string]string{username: password},
+		permissions: map[string]management.Permission{},
+		policies:    map[string]management.Policy{},
+
+		operatorPolicies: map[string]management.Policy{},
+		parameters:       map[string]map[string]management.Parameter{},
+		shovelStates:     map[string]management.Shovel{},
+	}
+
+	// Routed by hand, http.ServeMux cleans the path and the default vhost "/" is escaped to %2F in it.
+	fake.routes = map[string]fakeHandler{
+		route(http.MethodGet, "overview", 0):       fake.handleOverview,
+		route(http.MethodGet, "nodes", 0):          fake.handleNodes,
+		route(http.MethodGet, "definitions", 0):    fake.handleDefinitions,
+		route(http.MethodGet, "queues", 0):         fake.handleListQueues,
+		route(http.MethodGet, "queues", 1):         fake.handleListQueues,
+		route(http.MethodGet, "queues", 2):         fake.handleGetQueue,
+		route(http.MethodPut, "queues", 2):         fake.handlePutQueue,
+		route(http.MethodDelete, "queues", 2):      fake.handleDeleteQueue,
+		route(http.MethodGet, "exchanges", 0):      fake.handleListExchanges,
+		route(http.MethodGet, "exchanges", 1):      fake.handleListExchanges,
+		route(http.MethodGet, "exchanges", 2):      fake.handleGetExchange,
+		route(http.MethodPut, "exchanges", 2):      fake.handlePutExchange,
+		route(http.MethodDelete, "exchanges", 2):   fake.handleDeleteExchange,
+		route(http.MethodGet, "bindings", 5):       fake.handleListBindings,
+		route(http.MethodPost, "bindings", 5):      fake.handlePostBinding,
+		route(http.MethodDelete, "bindings", 6):    fake.handleDeleteBinding,
+		route(http.MethodGet, "users", 0):          fake.handleListUsers,
+		route(http.MethodGet, "users", 1):          fake.handleGetUser,
+		route(http.MethodPut, "users", 1):          fake.handlePutUser,
+		route(http.MethodDelete, "users", 1):       fake.handleDeleteUser,
+		route(http.MethodGet, "permissions", 0):    fake.handleListPermissions,
+		route(http.MethodGet, "permissions", 2):    fake.handleGetPermission,
+		route(http.MethodPut, "permissions", 2):    fake.handlePutPermission,
+		route(http.MethodDelete, "permissions", 2): fake.handleDeletePermission,
+		route(http.MethodGet, "vhosts", 0):         fake.handleListVhosts,
+		route(http.MethodGet, "vhosts", 1):         fake.handleGetVhost,
+		route(http.MethodPut, "vhosts", 1):         fake.handlePutVhost,
+		route(http.MethodDelete, "vhosts", 1):      fake.handleDeleteVhost,
+		route(http.MethodGet, "policies", 0):       fake.handleListPolicies,
+		route(http.MethodGet, "policies", 1):       fake.handleListPolicies,
+		route(http.MethodGet, "policies", 2):       fake.handleGetPolicy,
+		route(http.MethodPut, "policies", 2):       fake.handlePutPolicy,
+		route(http.MethodDelete, "policies", 2):    fake.handleDeletePolicy,
+
+		route(http.MethodGet, "operator-policies", 0):    fake.handleListOperatorPolicies,
+		route(http.MethodGet, "operator-policies", 1):    fake.handleListOperatorPolicies,
+		route(http.MethodGet, "operator-policies", 2):    fake.handleGetOperatorPolicy,
+		route(http.MethodPut, "operator-policies", 2):    fake.handlePutOperatorPolicy,
+		route(http.MethodDelete, "operator-policies", 2): fake.handleDeleteOperatorPolicy,
+		route(http.MethodGet, "parameters", 3):           fake.handleGetParameter,
+		route(http.MethodPut, "parameters", 3):           fake.handlePutParameter,
+		route(http.MethodDelete, "parameters", 3):        fake.handleDeleteParameter,
+		route(http.MethodGet, "shovels", 0):              fake.handleListShovels,
+		route(http.MethodGet, "shovels", 1):              fake.handleListShovels,
+	}
+	fake.Server = httptest.NewServer(fake.authenticate(http.HandlerFunc(fake.serve)))
+
+	return fake
+}
+
+// fakeHandler serves a route, path holds the unescaped segments after the resource, e.g. vhost and name.
+type fakeHandler func(w http.ResponseWriter, r *http.Request, path []string)
+
+func route(method, resource string, segments int) string {
+	return fmt.Sprintf("%s %s/%d", method, resource, segments)
+}
+
+// serve dispatches on the method, the resource and the number of segments following it.
+func (f *FakeManagement) serve(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/api/"), "/")
+	path := make([]string, 0, len(segments)-1)
+	for _, segment := range segments[1:] {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "bad_request", err.Error())
+			return
+		}
+		path = append(path, unescaped)
+	}
+
+	handler, ok := f.routes[route(r.Method, segments[0], len(path))]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Not Found")
+		return
+	}
+	handler(w, r, path)
+}
+
+// SetNodes replaces the nodes reported by the fake.
+func (f *FakeManagement) SetNodes(nodes ...management.Node) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nodes = nodes
+}
+
+// Password returns the password of a user, empty if the user doesn't exist.
+func (f *FakeManagement) Password(user string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.passwords[user]
+}
+
+// SetShovelState replaces the state reported for a shovel, shovels are Running unless set.
+func (f *FakeManagement) SetShovelState(vhost, name, state, reason string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.shovelStates[entityKey(vhost, name)] = management.Shovel{Name: name, Vhost: vhost, State: state, Error: reason}
+}
+
+// authenticate only lets users with the administrator tag through, like the operator user.
+func (f *FakeManagement) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		f.mu.Lock()
+		valid := ok && f.passwords[username] == password && strings.Contains(f.users[username].Tags, "administrator")
+		f.mu.Unlock()
+		if !valid {
+			writeError(w, http.StatusUnauthorized, "not_authorized", "Login failed")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// entityKey keys entities by vhost and name.
+func entityKey(vhost, name string) string {
+	return vhost + "\x00" + name
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, reason, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": reason, "reason": message})
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, out any) bool {
+	if r.ContentLength == 0 {
+		return true
+	}
+	if err := json.NewDecoder(r.Body).Decode(out); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error())
+		return false
+	}
+	return true
+}
+
+// sortedValues returns the values of a map sorted by key, optionally only those in a vhost.
+func sortedValues[T any](entities map[string]T, vhost string) []T {
+	values := []T{}
+	for _, key := range slices.Sorted(maps.Keys(entities)) {
+		if vhost != "" && !strings.HasPrefix(key, vhost+"\x00") {
+			continue
+		}
+		values = append(values, entities[key])
+	}
+	return values
+}
+
+func (f *FakeManagement) handleOverview(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	overview := management.Overview{LavinMQVersion: "2.0.0", Node: "fake"}
+	overview.ObjectTotals.Queues = len(f.queues)
+	overview.ObjectTotals.Exchanges = len(f.exchanges)
+	writeJSON(w, overview)
+}
+
+func (f *FakeManagement) handleNodes(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	nodes := f.nodes
+	if nodes == nil {
+		nodes = []management.Node{{Name: "fake", Running: true}}
+	}
+	writeJSON(w, nodes)
+}
+
+func (f *FakeManagement) handleDefinitions(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	writeJSON(w, management.Definitions{
+		LavinMQVersion: "2.0.0",
+		Users:          sortedValues(f.users, ""),
+		Vhosts:         sortedValues(f.vhosts, ""),
+		Permissions:    sortedValues(f.permissions, ""),
+		Queues:         sortedValues(f.queues, ""),
+		Policies:       sortedValues(f.policies, ""),
+	})
+}
+
+// requireVhost writes a not found error and returns false if the vhost doesn't exist. Callers hold the lock.
+func (f *FakeManagement) requireVhost(w http.ResponseWriter, vhost string) bool {
+	if _, ok := f.vhosts[vhost]; !ok {
+		writeError(w, http.StatusNotFound, "not_found", fmt.Sprintf("Not Found: vhost %s", vhost))
+		return false
+	}
+	return true
+}
+
+func (f *FakeManagement) handleListQueues(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	queues := sortedValues(f.queues, strings.Join(path, ""))
+	for i := range queues {
+		queues[i].Policy = effectivePolicy(f.policies, queues[i].Vhost, queues[i].Name, "queues")
+		queues[i].OperatorPolicy = effectivePolicy(f.operatorPolicies, queues[i].Vhost, queues[i].Name, "queues")
+	}
+	writeJSON(w, queues)
+}
+
+func (f *FakeManagement) handleGetQueue(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	queue, ok := f.queues[entityKey(path[0], path[1])]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Not Found")
+		return
+	}
+	writeJSON(w, queue)
+}
+
+func (f *FakeManagement) handlePutQueue(w http.ResponseWriter, r *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	vhost, name := path[0], path[1]
+	if !f.requireVhost(w, vhost) {
+		return
+	}
+	settings := management.QueueSettings{}
+	if !decodeBody(w, r, &settings) {
+		return
+	}
+
+	queue := management.Queue{
+		Name:       name,
+		Vhost:      vhost,
+		Durable:    settings.Durable,
+		AutoDelete: settings.AutoDelete,
+		Arguments:  settings.Arguments,
+		State:      "running",
+	}
+	if queue.Arguments == nil {
+		queue.Arguments = map[string]any{}
+	}
+	// Redeclaring with other properties fails like it does over AMQP.
+	if existing, ok := f.queues[entityKey(vhost, name)]; ok {
+		if existing.Durable != queue.Durable || existing.AutoDelete != queue.AutoDelete || !reflect.DeepEqual(existing.Arguments, queue.Arguments) {
+			writeError(w, http.StatusBadRequest, "bad_request", fmt.Sprintf("PRECONDITION_FAILED - Existing queue '%s' declared with other arguments", name))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	f.queues[entityKey(vhost, name)] = queue
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (f *FakeManagement) handleDeleteQueue(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := entityKey(path[0], path[1])
+	if _, ok := f.queues[key]; !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Not Found")
+		return
+	}
+	delete(f.queues, key)
+	f.unbindAll(path[0], management.DestinationTypeQueue, path[1])
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *FakeManagement) handleListExchanges(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	exchanges := sortedValues(f.exchanges, strings.Join(path, ""))
+	for i := range exchanges {
+		exchanges[i].Policy = effectivePolicy(f.policies, exchanges[i].Vhost, exchanges[i].Name, "exchanges")
+	}
+	writeJSON(w, exchanges)
+}
+
+func (f *FakeManagement) handleGetExchange(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	exchange, ok := f.exchanges[entityKey(path[0], path[1])]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Not Found")
+		return
+	}
+	writeJSON(w, exchange)
+}
+
+func (f *FakeManagement) handlePutExchange(w http.ResponseWriter, r *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	vhost, name := path[0], path[1]
+	if !f.requireVhost(w, vhost) {
+		return
+	}
+	settings := management.ExchangeSettings{}
+	if !decodeBody(w, r, &settings) {
+		return
+	}
+
+	exchange := management.Exchange{
+		Name:       name,
+		Vhost:      vhost,
+		Type:       settings.Type,
+		Durable:    settings.Durable,
+		AutoDelete: settings.AutoDelete,
+		Internal:   settings.Internal,
+		Arguments:  settings.Arguments,
+	}
+	if exchange.Arguments == nil {
+		exchange.Arguments = map[string]any{}
+	}
+	if existing, ok := f.exchanges[entityKey(vhost, name)]; ok {
+		if !reflect.DeepEqual(existing, exchange) {
+			writeError(w, http.StatusBadRequest, "bad_request", fmt.Sprintf("PRECONDITION_FAILED - Existing exchange '%s' declared with other arguments", name))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	f.exchanges[entityKey(vhost, name)] = exchange
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (f *FakeManagement) handleDeleteExchange(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := entityKey(path[0], path[1])
+	if _, ok := f.exchanges[key]; !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Not Found")
+		return
+	}
+	delete(f.exchanges, key)
+	f.unbindAll(path[0], management.DestinationTypeExchange, path[1])
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// unbindAll removes the bindings of a deleted queue or exchange. Callers hold the lock.
+func (f *FakeManagement) unbindAll(vhost string, entityType management.DestinationType, name string) {
+	maps.DeleteFunc(f.bindings, func(_ string, binding management.Binding) bool {
+		if binding.Vhost != vhost {
+			return false
+		}
+		return (binding.DestinationType == entityType && binding.Destination == name) ||
+			(entityType == management.DestinationTypeExchange && binding.Source == name)
+	})
+}
+
+// bindingPath resolves the vhost, source and destination of a binding path, writing a not found error and
+// returning false if one of them doesn't exist. Callers hold the lock.
+func (f *FakeManagement) bindingPath(w http.ResponseWriter, path []string) (management.Binding, bool) {
+	binding := management.Binding{Vhost: path[0], Source: path[2], Destination: path[4]}
+	if !f.requireVhost(w, binding.Vhost) {
+		return binding, false
+	}
+	if _, ok := f.exchanges[entityKey(binding.Vhost, binding.Source)]; path[1] != "e" || !ok {
+		writeError(w, http.StatusNotFound, "not_found", fmt.Sprintf("Not Found: exchange %s", binding.Source))
+		return binding, false
+	}
+
+	exists := false
+	switch path[3] {
+	case "q":
+		binding.DestinationType = management.DestinationTypeQueue
+		_, exists = f.queues[entityKey(binding.Vhost, binding.Destination)]
+	case "e":
+		binding.DestinationType = management.DestinationTypeExchange
+		_, exists = f.exchanges[entityKey(binding.Vhost, binding.Destination)]
+	}
+	if !exists {
+		writeError(w, http.StatusNotFound, "not_found", fmt.Sprintf("Not Found: %s %s", binding.DestinationType, binding.Destination))
+		return binding, false
+	}
+
+	return binding, true
+}
+
+func bindingKey(binding management.Binding) string {
+	return strings.Join([]string{binding.Vhost, binding.Source, string(binding.DestinationType), binding.Destination, binding.PropertiesKey}, "\x00")
+}
+
+func (f *FakeManagement) handleListBindings(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	binding, ok := f.bindingPath(w, path)
+	if !ok {
+		return
+	}
+	writeJSON(w, sortedValues(f.bindings, strings.TrimSuffix(bindingKey(binding), "\x00")))
+}
+
+func (f *FakeManagement) handlePostBinding(w http.ResponseWriter, r *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	binding, ok := f.bindingPath(w, path)
+	if !ok {
+		return
+	}
+	settings := management.BindingSettings{}
+	if !decodeBody(w, r, &settings) {
+		return
+	}
+
+	binding.RoutingKey = settings.RoutingKey
+	binding.Arguments = settings.Arguments
+	if binding.Arguments == nil {
+		binding.Arguments = map[string]any{}
+	}
+	// Like LavinMQ the properties key is the routing key, suffixed with a hash of the arguments if there are any.
+	binding.PropertiesKey = settings.RoutingKey
+	if len(settings.Arguments) > 0 {
+		data, _ := json.Marshal(settings.Arguments)
+		sum := sha256.Sum256(data)
+		binding.PropertiesKey = settings.RoutingKey + "~" + hex.EncodeToString(sum[:4])
+	}
+	f.bindings[bindingKey(binding)] = binding
+	w.Header().Set("Location", binding.PropertiesKey)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (f *FakeManagement) handleDeleteBinding(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	binding, ok := f.bindingPath(w, path)
+	if !ok {
+		return
+	}
+	binding.PropertiesKey = path[5]
+	key := bindingKey(binding)
+	if _, ok := f.bindings[key]; !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Not Found")
+		return
+	}
+	delete(f.bindings, key)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *FakeManagement) handleListUsers(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	writeJSON(w, sortedValues(f.users, ""))
+}
+
+func (f *FakeManagement) handleGetUser(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	user, ok := f.users[path[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Not Found")
+		return
+	}
+	writeJSON(w, user)
+}
+
+func (f *FakeManagement) handlePutUser(w http.ResponseWriter, r *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := path[0]
+	settings := management.UserSettings{}
+	if !decodeBody(w, r, &settings) {
+		return
+	}
+
+	_, exists := f.users[name]
+	f.users[name] = management.User{Name: name, Tags: settings.Tags, HashingAlgorithm: "SHA256", PasswordHash: settings.PasswordHash}
+	if settings.Password != "" {
+		f.passwords[name] = settings.Password
+	}
+	if exists {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (f *FakeManagement) handleDeleteUser(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := path[0]
+	if _, ok := f.users[name]; !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Not Found")
+		return
+	}
+	delete(f.users, name)
+	delete(f.passwords, name)
+	for key, permission := range f.permissions {
+		if permission.User == name {
+			delete(f.permissions, key)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *FakeManagement) handleListPermissions(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	writeJSON(w, sortedValues(f.permissions, ""))
+}
+
+func (f *FakeManagement) handleGetPermission(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	permission, ok := f.permissions[entityKey(path[0], path[1])]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Not Found")
+		return
+	}
+	writeJSON(w, permission)
+}
+
+func (f *FakeManagement) handlePutPermission(w http.ResponseWriter, r *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	vhost, name := path[0], path[1]
+	if !f.requireVhost(w, vhost) {
+		return
+	}
+	if _, ok := f.users[name]; !ok {
+		writeError(w, http.StatusNotFound, "not_found", fmt.Sprintf("Not Found: user %s", name))
+		return
+	}
+	settings := management.PermissionSettings{}
+	if !decodeBody(w, r, &settings) {
+		return
+	}
+
+	f.permissions[entityKey(vhost, name)] = management.Permission{
+		User:      name,
+		Vhost:     vhost,
+		Configure: settings.Configure,
+		Write:     settings.Write,
+		Read:      settings.Read,
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *FakeManagement) handleDeletePermission(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := entityKey(path[0], path[1])
+	if _, ok := f.permissions[key]; !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Not Found")
+		return
+	}
+	delete(f.permissions, key)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *FakeManagement) handleListVhosts(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	writeJSON(w, sortedValues(f.vhosts, ""))
+}
+
+func (f *FakeManagement) handleGetVhost(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	vhost, ok := f.vhosts[path[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Not Found")
+		return
+	}
+	writeJSON(w, vhost)
+}
+
+func (f *FakeManagement) handlePutVhost(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := path[0]
+	if _, ok := f.vhosts[name]; ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	f.vhosts[name] = management.Vhost{Name: name}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// handleDeleteVhost deletes the vhost with everything in it.
+func (f *FakeManagement) handleDeleteVhost(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := path[0]
+	if _, ok := f.vhosts[name]; !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Not Found")
+		return
+	}
+	delete(f.vhosts, name)
+	maps.DeleteFunc(f.queues, func(key string, _ management.Queue) bool { return strings.HasPrefix(key, name+"\x00") })
+	maps.DeleteFunc(f.exchanges, func(key string, _ management.Exchange) bool { return strings.HasPrefix(key, name+"\x00") })
+	maps.DeleteFunc(f.bindings, func(key string, _ management.Binding) bool { return strings.HasPrefix(key, name+"\x00") })
+	maps.DeleteFunc(f.permissions, func(key string, _ management.Permission) bool { return strings.HasPrefix(key, name+"\x00") })
+	maps.DeleteFunc(f.policies, func(key string, _ management.Policy) bool { return strings.HasPrefix(key, name+"\x00") })
+	maps.DeleteFunc(f.operatorPolicies, func(key string, _ management.Policy) bool { return strings.HasPrefix(key, name+"\x00") })
+	for _, parameters := range f.parameters {
+		maps.DeleteFunc(parameters, func(key string, _ management.Parameter) bool { return strings.HasPrefix(key, name+"\x00") })
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// effectivePolicy returns the name of the policy with the highest priority whose pattern matches an entity of
+// the given kind, "queues" or "exchanges". Callers hold the lock.
+func effectivePolicy(policies map[string]management.Policy, vhost, name, kind string) string {
+	var effective *management.Policy
+	for _, policy := range sortedValues(policies, vhost) {
+		if policy.ApplyTo != "all" && policy.ApplyTo != kind {
+			continue
+		}
+		if matched, err := regexp.MatchString(policy.Pattern, name); err != nil || !matched {
+			continue
+		}
+		if effective == nil || policy.Priority > effective.Priority {
+			effective = &policy
+		}
+	}
+	if effective == nil {
+		return ""
+	}
+	return effective.Name
+}
+
+func (f *FakeManagement) handleListPolicies(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.listPolicies(w, f.policies, path)
+}
+
+func (f *FakeManagement) handleGetPolicy(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.getPolicy(w, f.policies, path)
+}
+
+func (f *FakeManagement) handlePutPolicy(w http.ResponseWriter, r *http.Request, path []string) {
+	f.putPolicy(w, r, f.policies, path)
+}
+
+func (f *FakeManagement) handleDeletePolicy(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.deletePolicy(w, f.policies, path)
+}
+
+func (f *FakeManagement) handleListOperatorPolicies(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.listPolicies(w, f.operatorPolicies, path)
+}
+
+func (f *FakeManagement) handleGetOperatorPolicy(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.getPolicy(w, f.operatorPolicies, path)
+}
+
+func (f *FakeManagement) handlePutOperatorPolicy(w http.ResponseWriter, r *http.Request, path []string) {
+	f.putPolicy(w, r, f.operatorPolicies, path)
+}
+
+func (f *FakeManagement) handleDeleteOperatorPolicy(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.deletePolicy(w, f.operatorPolicies, path)
+}
+
+func (f *FakeManagement) listPolicies(w http.ResponseWriter, policies map[string]management.Policy, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	writeJSON(w, sortedValues(policies, strings.Join(path, "")))
+}
+
+func (f *FakeManagement) getPolicy(w http.ResponseWriter, policies map[string]management.Policy, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	policy, ok := policies[entityKey(path[0], path[1])]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Not Found")
+		return
+	}
+	writeJSON(w, policy)
+}
+
+func (f *FakeManagement) putPolicy(w http.ResponseWriter, r *http.Request, policies map[string]management.Policy, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	vhost, name := path[0], path[1]
+	if !f.requireVhost(w, vhost) {
+		return
+	}
+	settings := management.PolicySettings{}
+	if !decodeBody(w, r, &settings) {
+		return
+	}
+	if settings.ApplyTo == "" {
+		settings.ApplyTo = "all"
+	}
+
+	policies[entityKey(vhost, name)] = management.Policy{
+		Name:       name,
+		Vhost:      vhost,
+		Pattern:    settings.Pattern,
+		ApplyTo:    settings.ApplyTo,
+		Priority:   settings.Priority,
+		Definition: settings.Definition,
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *FakeManagement) deletePolicy(w http.ResponseWriter, policies map[string]management.Policy, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := entityKey(path[0], path[1])
+	if _, ok := policies[key]; !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Not Found")
+		return
+	}
+	delete(policies, key)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *FakeManagement) handleGetParameter(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parameter, ok := f.parameters[path[0]][entityKey(path[1], path[2])]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Not Found")
+		return
+	}
+	writeJSON(w, parameter)
+}
+
+// handlePutParameter stores shovel and federation-upstream parameters, rejecting them without URIs like
+// LavinMQ does.
+func (f *FakeManagement) handlePutParameter(w http.ResponseWriter, r *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	component, vhost, name := path[0], path[1], path[2]
+	if !f.requireVhost(w, vhost) {
+		return
+	}
+	body := struct {
+		Value map[string]any `json:"value"`
+	}{}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	var required []string
+	switch component {
+	case management.ComponentShovel:
+		required = []string{"src-uri", "dest-uri"}
+	case management.ComponentFederationUpstream:
+		required = []string{"uri"}
+	default:
+		writeError(w, http.StatusNotFound, "not_found", fmt.Sprintf("Not Found: component %s", component))
+		return
+	}
+	for _, field := range required {
+		if uri, _ := body.Value[field].(string); uri == "" {
+			writeError(w, http.StatusBadRequest, "bad_request", fmt.Sprintf("Field '%s' is required", field))
+			return
+		}
+	}
+
+	if f.parameters[component] == nil {
+		f.parameters[component] = map[string]management.Parameter{}
+	}
+	f.parameters[component][entityKey(vhost, name)] = management.Parameter{
+		Name:      name,
+		Vhost:     vhost,
+		Component: component,
+		Value:     body.Value,
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *FakeManagement) handleDeleteParameter(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := entityKey(path[1], path[2])
+	if _, ok := f.parameters[path[0]][key]; !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Not Found")
+		return
+	}
+	delete(f.parameters[path[0]], key)
+	delete(f.shovelStates, key)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *FakeManagement) handleListShovels(w http.ResponseWriter, _ *http.Request, path []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	shovels := []management.Shovel{}
+	for _, parameter := range sortedValues(f.parameters[management.ComponentShovel], strings.Join(path, "")) {
+		shovel, ok := f.shovelStates[entityKey(parameter.Vhost, parameter.Name)]
+		if !ok {
+			shovel = management.Shovel{Name: parameter.Name, Vhost: parameter.Vhost, State: "Running"}
+		}
+		shovels = append(shovels, shovel)
+	}
+	writeJSON(w, shovels)
+}